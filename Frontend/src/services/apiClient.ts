@@ -86,11 +86,12 @@ class ApiClient {
       const response = await fetch(url, config);
 
       if (!response.ok) {
+        // Failed requests answer { success: false, error: { code, reason, message, request_id, details } }
         const errorData = await response
           .json()
-          .catch(() => ({ error: "Unknown error" }));
+          .catch(() => ({ error: { message: "Unknown error" } }));
         throw new Error(
-          errorData.error || `HTTP error! status: ${response.status}`
+          errorData.error?.message || `HTTP error! status: ${response.status}`
         );
       }
 
@@ -0,0 +1,257 @@
+// Package certs sets up TLS for the gRPC server, the REST gateway and the connection between
+// them. Certificates, keys and CAs are read from PEM files and re-read when the files change,
+// so rotated certificates are picked up without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval limits how often handshakes look at the files' modification times
+const reloadCheckInterval = 5 * time.Second
+
+// ServerSettings are the TLS options of a listener
+type ServerSettings struct {
+	Enabled      bool   `conf:"enabled" usage:"serve TLS"`
+	CertFile     string `conf:"cert_file" usage:"PEM certificate (chain) file"`
+	KeyFile      string `conf:"key_file" secret:"true" usage:"PEM private key file"`
+	ClientCAFile string `conf:"client_ca_file" usage:"require client certificates signed by this CA (mutual TLS)"`
+}
+
+// Validate checks the settings are complete
+func (s ServerSettings) Validate() error {
+	switch {
+	case !s.Enabled && (s.ClientCAFile != ""):
+		return errors.New("client_ca_file needs TLS to be enabled")
+	case s.Enabled && (s.CertFile == "" || s.KeyFile == ""):
+		return errors.New("TLS needs cert_file and key_file")
+	}
+	return nil
+}
+
+// ServerConfig builds the TLS config of a listener offering nextProtos (ALPN), or nil if TLS
+// is disabled. With a client CA, clients must present a certificate it signed.
+func (s ServerSettings) ServerConfig(nextProtos ...string) (*tls.Config, error) {
+	if !s.Enabled {
+		return nil, nil
+	}
+
+	cert, err := NewKeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     nextProtos,
+		GetCertificate: cert.GetCertificate,
+	}
+	if s.ClientCAFile == "" {
+		return base, nil
+	}
+
+	clientCAs, err := NewCAPool(s.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.ClientCAs = clientCAs.Pool()
+
+	// Each handshake gets the current client CAs
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := base.Clone()
+			cfg.ClientCAs = clientCAs.Pool()
+			return cfg, nil
+		},
+	}, nil
+}
+
+// ClientSettings are the TLS options of a connection to a server
+type ClientSettings struct {
+	Enabled    bool   `conf:"enabled" usage:"connect with TLS"`
+	CAFile     string `conf:"ca_file" usage:"CA that signed the server certificate (system roots if empty)"`
+	CertFile   string `conf:"cert_file" usage:"client certificate presented for mutual TLS"`
+	KeyFile    string `conf:"key_file" secret:"true" usage:"private key of the client certificate"`
+	ServerName string `conf:"server_name" usage:"name expected in the server certificate (host of the address if empty)"`
+}
+
+// Validate checks the settings are complete
+func (s ClientSettings) Validate() error {
+	switch {
+	case !s.Enabled && (s.CAFile != "" || s.CertFile != ""):
+		return errors.New("ca_file and cert_file need TLS to be enabled")
+	case (s.CertFile == "") != (s.KeyFile == ""):
+		return errors.New("cert_file and key_file must be set together")
+	}
+	return nil
+}
+
+// ClientConfig builds the TLS config of a connection, or nil if TLS is disabled.
+// The client certificate is reloaded when its files change; a new CA needs a restart.
+func (s ClientSettings) ClientConfig() (*tls.Config, error) {
+	if !s.Enabled {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: s.ServerName,
+	}
+	if s.CAFile != "" {
+		rootCAs, err := NewCAPool(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = rootCAs.Pool()
+	}
+	if s.CertFile != "" {
+		cert, err := NewKeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = cert.GetClientCertificate
+	}
+	return cfg, nil
+}
+
+// KeyPair serves a certificate and key from files, re-reading them when they change
+type KeyPair struct {
+	certFile, keyFile string
+
+	mu    sync.Mutex
+	watch fileWatch
+	cert  *tls.Certificate
+}
+
+// NewKeyPair loads a certificate and key; they must be valid now, later reloads that fail keep the old pair
+func NewKeyPair(certFile, keyFile string) (*KeyPair, error) {
+	kp := &KeyPair{certFile: certFile, keyFile: keyFile, watch: fileWatch{paths: []string{certFile, keyFile}}}
+	kp.watch.changed(time.Now())
+	if err := kp.load(); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+func (kp *KeyPair) load() error {
+	cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s: %w", kp.certFile, err)
+	}
+	kp.cert = &cert
+	return nil
+}
+
+// Certificate returns the current certificate, reloading it first if its files changed
+func (kp *KeyPair) Certificate() *tls.Certificate {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+
+	if kp.watch.changed(time.Now()) {
+		if err := kp.load(); err != nil {
+			slog.Warn("Keeping previous certificate", "error", err)
+		} else {
+			slog.Info("Reloaded certificate", "file", kp.certFile)
+		}
+	}
+	return kp.cert
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (kp *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// CAPool serves a pool of CA certificates from a file, re-reading it when it changes
+type CAPool struct {
+	file string
+
+	mu    sync.Mutex
+	watch fileWatch
+	pool  *x509.CertPool
+}
+
+// NewCAPool loads the CA certificates in file
+func NewCAPool(file string) (*CAPool, error) {
+	p := &CAPool{file: file, watch: fileWatch{paths: []string{file}}}
+	p.watch.changed(time.Now())
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *CAPool) load() error {
+	pem, err := os.ReadFile(p.file)
+	if err != nil {
+		return fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", p.file)
+	}
+	p.pool = pool
+	return nil
+}
+
+// Pool returns the current CAs, reloading them first if the file changed
+func (p *CAPool) Pool() *x509.CertPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.watch.changed(time.Now()) {
+		if err := p.load(); err != nil {
+			slog.Warn("Keeping previous CA certificates", "error", err)
+		} else {
+			slog.Info("Reloaded CA certificates", "file", p.file)
+		}
+	}
+	return p.pool
+}
+
+// fileWatch notices changes to a set of files by their modification times,
+// looking at most once per reloadCheckInterval
+type fileWatch struct {
+	paths   []string
+	modTime time.Time
+	checked time.Time
+}
+
+// changed reports whether a file was modified since the last call that returned true
+func (w *fileWatch) changed(now time.Time) bool {
+	if now.Sub(w.checked) < reloadCheckInterval {
+		return false
+	}
+	w.checked = now
+
+	var newest time.Time
+	for _, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false // Mid-rotation; look again next time
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+
+	if !newest.After(w.modTime) {
+		return false
+	}
+	w.modTime = newest
+	return true
+}
@@ -0,0 +1,137 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Files written by GenerateDev
+const (
+	CAFile         = "ca.pem"
+	CAKeyFile      = "ca-key.pem"
+	ServerCertFile = "server.pem"
+	ServerKeyFile  = "server-key.pem"
+	ClientCertFile = "client.pem"
+	ClientKeyFile  = "client-key.pem"
+)
+
+// Lifetimes of the development certificates
+const (
+	devCAValidity   = 5 * 365 * 24 * time.Hour
+	devLeafValidity = 365 * 24 * time.Hour
+)
+
+// GenerateDev writes a local CA, a server certificate for hosts (names or IPs) and a client
+// certificate for the gateway into dir, all signed by the CA. For development only: the CA
+// key is written next to the certificates.
+func GenerateDev(dir string, hosts []string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{"Voucher Dev"}, CommonName: "Voucher Dev CA"},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caDER, err := sign(caTemplate, caTemplate, caKey, caKey, devCAValidity)
+	if err != nil {
+		return err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return err
+	}
+	if err := writePair(dir, CAFile, CAKeyFile, caDER, caKey); err != nil {
+		return err
+	}
+
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"Voucher Dev"}, CommonName: "voucher-server"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	if err := issue(dir, ServerCertFile, ServerKeyFile, serverTemplate, ca, caKey); err != nil {
+		return err
+	}
+
+	clientTemplate := &x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"Voucher Dev"}, CommonName: "voucher-gateway"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return issue(dir, ClientCertFile, ClientKeyFile, clientTemplate, ca, caKey)
+}
+
+// issue creates a key for template and writes the certificate the CA signs for it
+func issue(dir, certFile, keyFile string, template, ca *x509.Certificate, caKey crypto.Signer) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	der, err := sign(template, ca, key, caKey, devLeafValidity)
+	if err != nil {
+		return err
+	}
+	return writePair(dir, certFile, keyFile, der, key)
+}
+
+func sign(template, parent *x509.Certificate, key *ecdsa.PrivateKey, parentKey crypto.Signer, validity time.Duration) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour) // Tolerate clock skew
+	template.NotAfter = time.Now().Add(validity)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate %s: %w", template.Subject.CommonName, err)
+	}
+	return der, nil
+}
+
+// writePair writes a certificate and its private key as PEM; the key is readable by the owner only
+func writePair(dir, certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writePEM(filepath.Join(dir, certFile), "CERTIFICATE", der, 0o644); err != nil {
+		return err
+	}
+	return writePEM(filepath.Join(dir, keyFile), "PRIVATE KEY", keyDER, 0o600)
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
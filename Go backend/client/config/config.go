@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/certs"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/conf"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+// Config is the REST gateway configuration. See package conf for how values are layered;
+// run the gateway with --print-config to see the effective values.
+type Config struct {
+	HTTP      HTTPConfig       `conf:"http"`
+	GRPC      GRPCConfig       `conf:"grpc"`
+	RateLimit RateLimitConfig  `conf:"rate_limit"`
+	Log       logging.Settings `conf:"log"`
+	Tracing   tracing.Settings `conf:"tracing"`
+}
+
+type HTTPConfig struct {
+	Addr              string               `conf:"addr" default:":8080" usage:"HTTP listen address"`
+	ReadHeaderTimeout time.Duration        `conf:"read_header_timeout" default:"5s" usage:"how long a client may take to send request headers"`
+	ReadTimeout       time.Duration        `conf:"read_timeout" default:"15s" usage:"how long a client may take to send the whole request"`
+	WriteTimeout      time.Duration        `conf:"write_timeout" default:"30s" usage:"how long handling a request and writing the response may take"`
+	IdleTimeout       time.Duration        `conf:"idle_timeout" default:"2m" usage:"how long an idle keep-alive connection is kept open"`
+	ShutdownTimeout   time.Duration        `conf:"shutdown_timeout" default:"20s" usage:"how long shutdown waits for in-flight requests to finish"`
+	StreamHeartbeat   time.Duration        `conf:"stream_heartbeat" default:"15s" usage:"how often idle event streams get a keep-alive comment, so proxies don't close them"`
+	TrustedProxies    string               `conf:"trusted_proxies" usage:"comma-separated IPs or CIDRs of reverse proxies whose X-Forwarded-For gives the client IP (empty trusts none)"`
+	TLS               certs.ServerSettings `conf:"tls"`
+}
+
+// Proxies returns the trusted reverse proxies, or nil if none are trusted
+func (c *HTTPConfig) Proxies() ([]string, error) {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("%q is not an IP or CIDR", proxy)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+type GRPCConfig struct {
+	ServerAddr    string               `conf:"server_addr" default:"localhost:50051" usage:"address of the gRPC voucher service"`
+	HealthTimeout time.Duration        `conf:"health_timeout" default:"2s" usage:"how long /readyz waits for the gRPC health check"`
+	Timeout       time.Duration        `conf:"timeout" default:"10s" usage:"deadline of the gRPC calls made for an API request"`
+	RouteTimeouts string               `conf:"route_timeouts" default:"POST /api/v1/vouchers/buy=20s,GET /api/v1/settlements/:batch_id/export=25s" usage:"per route deadlines as \"METHOD /route=duration\" pairs"`
+	Keepalive     KeepaliveConfig      `conf:"keepalive"`
+	Retry         RetryConfig          `conf:"retry"`
+	Breaker       BreakerConfig        `conf:"breaker"`
+	TLS           certs.ClientSettings `conf:"tls"`
+}
+
+type KeepaliveConfig struct {
+	Time    time.Duration `conf:"time" default:"30s" usage:"ping the gRPC server after this long without activity (at least 10s)"`
+	Timeout time.Duration `conf:"timeout" default:"10s" usage:"drop the connection if a ping isn't answered within this time"`
+}
+
+// RetryConfig applies to idempotent calls only (search, balance, transactions); purchases are never retried
+type RetryConfig struct {
+	MaxAttempts    int           `conf:"max_attempts" default:"3" usage:"attempts of idempotent calls that find the gRPC server unavailable (1 disables retries)"`
+	InitialBackoff time.Duration `conf:"initial_backoff" default:"100ms" usage:"wait before the first retry; doubles with each retry"`
+	MaxBackoff     time.Duration `conf:"max_backoff" default:"1s" usage:"longest wait between retries"`
+}
+
+type BreakerConfig struct {
+	Enabled          bool          `conf:"enabled" default:"true" usage:"answer 503 at once while the gRPC server is failing"`
+	FailureThreshold int           `conf:"failure_threshold" default:"5" usage:"consecutive unavailable or timed out calls that open the breaker"`
+	OpenTimeout      time.Duration `conf:"open_timeout" default:"10s" usage:"how long the breaker stays open before a probe call is let through"`
+}
+
+// Deadlines returns the default and per-route deadlines of gRPC calls
+func (c *GRPCConfig) Deadlines() (time.Duration, map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration)
+	for _, pair := range strings.Split(c.RouteTimeouts, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		route, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return 0, nil, fmt.Errorf("%q is not a route=duration pair", pair)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout <= 0 {
+			return 0, nil, fmt.Errorf("invalid duration in %q", pair)
+		}
+		routes[strings.TrimSpace(route)] = timeout
+	}
+	return c.Timeout, routes, nil
+}
+
+type RateLimitConfig struct {
+	Enabled bool    `conf:"enabled" default:"true" usage:"limit how fast each client IP may call each route"`
+	Rate    float64 `conf:"rate" default:"20" usage:"requests per second per client for routes without their own budget"`
+	Burst   int     `conf:"burst" default:"40" usage:"requests a client may make at once for routes without their own budget"`
+	Routes  string  `conf:"routes" default:"POST /api/v1/auth/login=1:10,POST /api/v1/vouchers/buy=2:10" usage:"per route budgets as \"METHOD /route=rate:burst\" pairs"`
+}
+
+// Limits returns the default and per-route budgets
+func (c *RateLimitConfig) Limits() (ratelimit.Limit, map[string]ratelimit.Limit, error) {
+	routes, err := ratelimit.ParseLimits(c.Routes)
+	return ratelimit.Limit{Rate: c.Rate, Burst: c.Burst}, routes, err
+}
+
+// LoadConfig loads the configuration from defaults, the optional config file,
+// the environment (and .env file, if present) and the command line args
+func LoadConfig(args []string) (*Config, conf.Options, error) {
+	config := &Config{}
+	opts, err := conf.Load(config, "gateway", args)
+	if err != nil {
+		return nil, opts, err
+	}
+	return config, opts, nil
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if err := conf.CheckAddr(c.HTTP.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("http: %w", err))
+	}
+	if c.HTTP.ReadHeaderTimeout <= 0 || c.HTTP.ReadTimeout <= 0 || c.HTTP.WriteTimeout <= 0 || c.HTTP.IdleTimeout <= 0 || c.HTTP.ShutdownTimeout <= 0 || c.HTTP.StreamHeartbeat <= 0 {
+		errs = append(errs, errors.New("http timeouts and stream_heartbeat must be positive"))
+	}
+	if _, err := c.HTTP.Proxies(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid http trusted_proxies: %w", err))
+	}
+	if err := c.HTTP.TLS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("http: %w", err))
+	}
+	if err := conf.CheckAddr(c.GRPC.ServerAddr); err != nil {
+		errs = append(errs, fmt.Errorf("grpc: %w", err))
+	}
+	if err := c.GRPC.TLS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("grpc: %w", err))
+	}
+	if c.GRPC.HealthTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("grpc health_timeout %s must be positive", c.GRPC.HealthTimeout))
+	}
+	if c.GRPC.Keepalive.Time < 10*time.Second || c.GRPC.Keepalive.Timeout <= 0 {
+		errs = append(errs, errors.New("grpc keepalive time must be at least 10s and timeout positive"))
+	}
+	if c.GRPC.Retry.MaxAttempts < 1 || c.GRPC.Retry.MaxAttempts > 5 {
+		errs = append(errs, fmt.Errorf("grpc retry max_attempts %d must be between 1 and 5", c.GRPC.Retry.MaxAttempts))
+	}
+	if c.GRPC.Retry.InitialBackoff <= 0 || c.GRPC.Retry.MaxBackoff < c.GRPC.Retry.InitialBackoff {
+		errs = append(errs, errors.New("grpc retry initial_backoff must be positive and max_backoff at least initial_backoff"))
+	}
+	if c.GRPC.Breaker.Enabled && (c.GRPC.Breaker.FailureThreshold < 1 || c.GRPC.Breaker.OpenTimeout <= 0) {
+		errs = append(errs, errors.New("grpc breaker failure_threshold must be at least 1 and open_timeout positive"))
+	}
+	if c.GRPC.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("grpc timeout %s must be positive", c.GRPC.Timeout))
+	}
+	// A deadline past the write timeout would leave the client with a dropped connection instead of an error
+	if _, routes, err := c.GRPC.Deadlines(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid grpc route_timeouts: %w", err))
+	} else {
+		for _, route := range slices.Sorted(maps.Keys(routes)) {
+			if t := routes[route]; t >= c.HTTP.WriteTimeout {
+				errs = append(errs, fmt.Errorf("grpc route timeout %s of %q must be below http write_timeout %s", t, route, c.HTTP.WriteTimeout))
+			}
+		}
+		if c.GRPC.Timeout >= c.HTTP.WriteTimeout {
+			errs = append(errs, fmt.Errorf("grpc timeout %s must be below http write_timeout %s", c.GRPC.Timeout, c.HTTP.WriteTimeout))
+		}
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Rate <= 0 || c.RateLimit.Burst < 1 {
+			errs = append(errs, errors.New("rate_limit rate must be positive and burst at least 1"))
+		}
+		if _, _, err := c.RateLimit.Limits(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid rate_limit routes: %w", err))
+		}
+	}
+	if _, err := c.Log.Config(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.Tracing.ParseExporter(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
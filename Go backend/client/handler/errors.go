@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorResponse is the body of every failed API request
+type ErrorResponse struct {
+	Success bool     `json:"success"` // Always false
+	Error   APIError `json:"error"`
+}
+
+// APIError describes a failure the same way for every route
+type APIError struct {
+	Code      int           `json:"code"`   // HTTP status
+	Reason    string        `json:"reason"` // Stable machine readable cause, e.g. "NOT_FOUND" or "PURCHASE_LIMIT_REACHED"
+	Message   string        `json:"message"`
+	RequestID string        `json:"request_id,omitempty"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail adds structured information to an APIError
+type ErrorDetail struct {
+	Type              string `json:"type"` // "field_violation" or "retry"
+	Field             string `json:"field,omitempty"`
+	Description       string `json:"description,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// httpError is how a gRPC status code is reported over HTTP
+type httpError struct {
+	status int
+	reason string
+}
+
+// grpcToHTTP maps every gRPC status code to an HTTP status and reason
+var grpcToHTTP = map[codes.Code]httpError{
+	codes.Canceled:           {499, "CANCELLED"}, // Client closed request
+	codes.Unknown:            {http.StatusInternalServerError, "UNKNOWN"},
+	codes.InvalidArgument:    {http.StatusBadRequest, "INVALID_ARGUMENT"},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, "DEADLINE_EXCEEDED"},
+	codes.NotFound:           {http.StatusNotFound, "NOT_FOUND"},
+	codes.AlreadyExists:      {http.StatusConflict, "ALREADY_EXISTS"},
+	codes.PermissionDenied:   {http.StatusForbidden, "PERMISSION_DENIED"},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, "RESOURCE_EXHAUSTED"},
+	codes.FailedPrecondition: {http.StatusPreconditionFailed, "FAILED_PRECONDITION"},
+	codes.Aborted:            {http.StatusConflict, "ABORTED"},
+	codes.OutOfRange:         {http.StatusBadRequest, "OUT_OF_RANGE"},
+	codes.Unimplemented:      {http.StatusNotImplemented, "UNIMPLEMENTED"},
+	codes.Internal:           {http.StatusInternalServerError, "INTERNAL"},
+	codes.Unavailable:        {http.StatusServiceUnavailable, "UNAVAILABLE"},
+	codes.DataLoss:           {http.StatusInternalServerError, "DATA_LOSS"},
+	codes.Unauthenticated:    {http.StatusUnauthorized, "UNAUTHENTICATED"},
+}
+
+// respondError translates err, usually a gRPC status error, into the error envelope and aborts the request
+func respondError(c *gin.Context, err error) {
+	status, body := errorResponse(c.Writer, c.Request, err)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// errorResponse translates err into the HTTP status and error envelope, setting Retry-After on w when
+// the call may be retried later. The reason is the server's ErrorInfo reason if it sent one, or else
+// named after the gRPC code.
+func errorResponse(w http.ResponseWriter, r *http.Request, err error) (int, ErrorResponse) {
+	st, ok := status.FromError(err)
+	if !ok {
+		// Not from the server, so the message may reveal gateway internals
+		logging.FromContext(r.Context()).Error("Request failed", "error", err)
+		st = status.New(codes.Internal, "internal server error")
+	}
+
+	mapping, ok := grpcToHTTP[st.Code()]
+	if !ok {
+		mapping = grpcToHTTP[codes.Unknown]
+	}
+
+	apiErr := APIError{
+		Code:      mapping.status,
+		Reason:    mapping.reason,
+		Message:   st.Message(),
+		RequestID: logging.RequestIDFromContext(r.Context()),
+		Details:   errorDetails(st),
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() != "" {
+			apiErr.Reason = info.GetReason()
+		}
+	}
+
+	// Tell clients when a rate limited call or an open circuit breaker may be retried
+	if wait, ok := ratelimit.RetryAfter(st); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(wait)))
+	}
+
+	return mapping.status, ErrorResponse{Error: apiErr}
+}
+
+// badRequest rejects a request whose input the gateway could not read
+func badRequest(c *gin.Context, msg string) {
+	respondError(c, status.Error(codes.InvalidArgument, msg))
+}
+
+// errorDetails lists the status details clients can act on
+func errorDetails(st *status.Status) []ErrorDetail {
+	var details []ErrorDetail
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range detail.GetFieldViolations() {
+				details = append(details, ErrorDetail{Type: "field_violation", Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			wait := detail.GetRetryDelay().AsDuration()
+			details = append(details, ErrorDetail{Type: "retry", RetryAfterSeconds: ratelimit.RetryAfterSeconds(wait)})
+		}
+	}
+	return details
+}
+
+// Errors renders the last error a middleware recorded with c.Error in the error envelope,
+// so shared middleware such as the rate limiter reports errors like the handlers do
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			respondError(c, c.Errors.Last().Err)
+		}
+	}
+}
+
+// NotFound answers requests for unknown routes
+func NotFound(c *gin.Context) {
+	respondError(c, status.Error(codes.NotFound, "route not found"))
+}
+
+// Recovered answers requests whose handler panicked; gin.CustomRecovery has already logged the panic
+func Recovered(c *gin.Context, _ any) {
+	respondError(c, status.Error(codes.Internal, "internal server error"))
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/client/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// createdMethods answer 201 Created rather than 200 OK
+var createdMethods = map[protoreflect.Name]bool{
+	"CreatePromotion":       true,
+	"CreatePriceSchedule":   true,
+	"ReserveVoucher":        true,
+	"CreateMerchant":        true,
+	"CreateMerchantVoucher": true,
+	"CreateSettlementBatch": true,
+	"CreateWebhookEndpoint": true,
+}
+
+// nestedBodies names the message field that holds most of the body of these methods. Their
+// bodies used to be flat, so a body without the field has its other fields moved into it.
+var nestedBodies = map[protoreflect.FullName]protoreflect.Name{
+	"VoucherService.CreateMerchantVoucher": "voucher",
+	"VoucherService.UpdateMerchantVoucher": "voucher",
+}
+
+// jsonOptions encode messages with their proto field names, like the API always has
+var jsonOptions = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// Route is the REST route of a VoucherService method, from its google.api.http option
+type Route struct {
+	Method string // HTTP method
+	Path   string // Path template, e.g. "/api/v1/wallet/balance/{user_id}"
+	Body   string // "*" when the JSON body fills the request, empty when there is none
+	RPC    protoreflect.MethodDescriptor
+}
+
+// GinPath returns the path in gin's syntax, e.g. "/api/v1/wallet/balance/:user_id"
+func (r Route) GinPath() string {
+	segments := strings.Split(r.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// PathParams returns the names of the request fields bound to path segments
+func (r Route) PathParams() []string {
+	var params []string
+	for _, segment := range strings.Split(r.Path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}"))
+		}
+	}
+	return params
+}
+
+// Routes lists the REST routes declared in voucher.proto, in declaration order.
+// Methods without a google.api.http option, such as the CSV export, are not transcoded.
+func Routes() []Route {
+	var routes []Route
+	methods := protoc.File_voucher_proto.Services().ByName("VoucherService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		opts, ok := method.Options().(*descriptorpb.MethodOptions)
+		if !ok || !proto.HasExtension(opts, annotations.E_Http) {
+			continue
+		}
+		rule := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+
+		route := Route{Body: rule.GetBody(), RPC: method}
+		switch pattern := rule.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			route.Method, route.Path = http.MethodGet, pattern.Get
+		case *annotations.HttpRule_Post:
+			route.Method, route.Path = http.MethodPost, pattern.Post
+		case *annotations.HttpRule_Put:
+			route.Method, route.Path = http.MethodPut, pattern.Put
+		case *annotations.HttpRule_Delete:
+			route.Method, route.Path = http.MethodDelete, pattern.Delete
+		case *annotations.HttpRule_Patch:
+			route.Method, route.Path = http.MethodPatch, pattern.Patch
+		default:
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// Gateway transcodes REST requests into VoucherService calls with the handlers grpc-gateway
+// generates from the google.api.http options in voucher.proto
+type Gateway struct {
+	mux    *runtime.ServeMux
+	routes []Route
+}
+
+// NewGateway creates a gateway calling the gRPC server through grpcClient
+func NewGateway(ctx context.Context, grpcClient *service.GRPCClient) (*Gateway, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   jsonOptions,
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithForwardResponseRewriter(func(_ context.Context, resp proto.Message) (any, error) {
+			return successResponse{resp}, nil
+		}),
+		runtime.WithForwardResponseOption(func(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+			// The full method, e.g. "/.VoucherService/CreateMerchant", ends with the method name
+			if method, ok := runtime.RPCMethod(ctx); ok && createdMethods[protoreflect.Name(path.Base(method))] {
+				w.WriteHeader(http.StatusCreated)
+			}
+			return nil
+		}),
+		runtime.WithErrorHandler(func(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+			httpStatus, body := errorResponse(w, r, err)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(httpStatus)
+			_ = json.NewEncoder(w).Encode(body)
+		}),
+		// Server headers stay between the gateway and the server
+		runtime.WithOutgoingHeaderMatcher(func(string) (string, bool) { return "", false }),
+	)
+
+	if err := protoc.RegisterVoucherServiceHandlerClient(ctx, mux, grpcClient.GetVoucherClient()); err != nil {
+		return nil, fmt.Errorf("failed to register gateway handlers: %w", err)
+	}
+	return &Gateway{mux: mux, routes: Routes()}, nil
+}
+
+// Register adds every transcoded route to group, which must be the group of the routes'
+// common prefix ("/api/v1"). Registering each route with gin, rather than forwarding all
+// requests to the gateway, lets route based middleware such as rate limits see the route.
+func (g *Gateway) Register(group *gin.RouterGroup) error {
+	handler := gin.WrapH(g.mux)
+	for _, route := range g.routes {
+		relativePath, ok := strings.CutPrefix(route.GinPath(), strings.TrimSuffix(group.BasePath(), "/"))
+		if !ok {
+			return fmt.Errorf("route %s %s is outside %s", route.Method, route.Path, group.BasePath())
+		}
+
+		handlers := []gin.HandlerFunc{handler}
+		if field, ok := nestedBodies[route.RPC.FullName()]; ok {
+			handlers = append([]gin.HandlerFunc{nestBody(route.RPC.Input(), field)}, handlers...)
+		}
+		group.Handle(route.Method, relativePath, handlers...)
+	}
+	return nil
+}
+
+// nestBody moves the fields of a flat JSON body that aren't fields of msg into its field
+// named field, unless the body already has that field
+func nestBody(msg protoreflect.MessageDescriptor, field protoreflect.Name) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			badRequest(c, "invalid request body")
+			return
+		}
+
+		var body map[string]json.RawMessage
+		if json.Unmarshal(raw, &body) == nil {
+			if _, ok := body[string(field)]; !ok {
+				nested := map[string]json.RawMessage{}
+				for key, value := range body {
+					if msg.Fields().ByName(protoreflect.Name(key)) == nil {
+						nested[key] = value
+						delete(body, key)
+					}
+				}
+				if len(nested) > 0 {
+					body[string(field)], _ = json.Marshal(nested)
+					raw, _ = json.Marshal(body)
+				}
+			}
+		}
+
+		// Anything that isn't a JSON object is left for the gateway to reject
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+		c.Request.ContentLength = int64(len(raw))
+		c.Next()
+	}
+}
+
+// successResponse adds "success": true to a response message, the shape every API response has
+type successResponse struct {
+	msg proto.Message
+}
+
+// MarshalJSON implements json.Marshaler
+func (r successResponse) MarshalJSON() ([]byte, error) {
+	b, err := jsonOptions.Marshal(r.msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode response: %v", err)
+	}
+
+	rest := bytes.TrimSpace(b[1:]) // After the opening brace
+	if len(rest) > 0 && rest[0] == '}' {
+		return []byte(`{"success":true}`), nil
+	}
+	return append([]byte(`{"success":true,`), rest...), nil
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/client/service"
+	"github.com/gin-gonic/gin"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const serviceName = "voucher-payment-api"
+
+type HealthHandler struct {
+	grpcClient *service.GRPCClient
+	timeout    time.Duration
+	draining   atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler whose readiness checks wait up to timeout
+func NewHealthHandler(grpcClient *service.GRPCClient, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{
+		grpcClient: grpcClient,
+		timeout:    timeout,
+	}
+}
+
+// Livez handles GET /livez: the gateway process is up, whatever the state of the backend
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "alive",
+		"service": serviceName,
+	})
+}
+
+// Drain makes /readyz report unavailable so load balancers stop sending traffic during shutdown
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Readyz handles GET /readyz: the gateway is ready only while the gRPC server reports SERVING,
+// which in turn requires its database and payment gateway to be healthy
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "draining",
+			"service": serviceName,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	client := h.grpcClient.GetHealthClient()
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"service": serviceName,
+			"error":   "gRPC server health check failed: " + err.Error(),
+		})
+		return
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":       "unavailable",
+			"service":      serviceName,
+			"dependencies": h.dependencies(ctx),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ready",
+		"service": serviceName,
+	})
+}
+
+// dependencies lists the status of each backend dependency, or nil if the server can't tell
+func (h *HealthHandler) dependencies(ctx context.Context) map[string]string {
+	resp, err := h.grpcClient.GetHealthClient().List(ctx, &healthpb.HealthListRequest{})
+	if err != nil {
+		return nil
+	}
+
+	statuses := make(map[string]string, len(resp.GetStatuses()))
+	for name, st := range resp.GetStatuses() {
+		if name != "" {
+			statuses[name] = st.GetStatus().String()
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:embed docs
+var docsFiles embed.FS
+
+// Document is an OpenAPI 3 document, with the parts of the specification the API uses
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of one path, by lower case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one route of the API
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // "path" or "query"
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is the response of an operation for one status code
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas operations refer to
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema, or a reference to one in Components
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+}
+
+// schemaRef refers to the component schema called name
+func schemaRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// DocsHandler serves the OpenAPI document of the REST API and a UI to browse and try it
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler builds the OpenAPI document from the routes in voucher.proto
+func NewDocsHandler() (*DocsHandler, error) {
+	spec, err := json.Marshal(BuildOpenAPI(Routes()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	return &DocsHandler{spec: spec}, nil
+}
+
+// Spec handles GET /api/v1/openapi.json
+func (h *DocsHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// UI handles GET /api/v1/docs, the bundled Swagger UI page reading /api/v1/openapi.json
+func (h *DocsHandler) UI(c *gin.Context) {
+	c.FileFromFS("docs/", http.FS(docsFiles))
+}
+
+// Asset handles GET /api/v1/docs/*filepath, the Swagger UI scripts, styles and icons the page loads
+func (h *DocsHandler) Asset(c *gin.Context) {
+	c.FileFromFS(path.Join("docs", c.Param("filepath")), http.FS(docsFiles))
+}
+
+// BuildOpenAPI describes routes, and the CSV export and event stream that aren't transcoded, as an OpenAPI 3 document
+func BuildOpenAPI(routes []Route) *Document {
+	b := &openAPIBuilder{schemas: errorSchemas()}
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Voucher API",
+			Description: "REST gateway of the VoucherService gRPC API. Successful responses carry \"success\": true next to the response message fields, failed ones the error envelope.",
+			Version:     "v1",
+		},
+		Paths: map[string]PathItem{},
+	}
+
+	for _, route := range routes {
+		item, ok := doc.Paths[route.Path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = b.operation(route)
+	}
+
+	export := "/api/v1/settlements/{batch_id}/export"
+	if _, ok := doc.Paths[export]; !ok {
+		doc.Paths[export] = PathItem{}
+	}
+	doc.Paths[export]["get"] = &Operation{
+		OperationID: "ExportSettlementBatch",
+		Summary:     "Export settlement batch as a CSV file",
+		Tags:        []string{"settlements"},
+		Parameters: []Parameter{
+			{Name: "batch_id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}},
+			{Name: "user_id", In: "query", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}},
+		},
+		Responses: map[string]Response{
+			"200": {
+				Description: "CSV file of the batch's settlement lines",
+				Headers:     map[string]Header{"Content-Disposition": {Schema: &Schema{Type: "string"}}},
+				Content:     map[string]MediaType{"text/csv": {Schema: &Schema{Type: "string", Format: "binary"}}},
+			},
+			"default": errorResponseSpec(),
+		},
+	}
+
+	doc.Paths["/api/v1/transactions/stream"] = PathItem{"get": &Operation{
+		OperationID: "WatchTransactions",
+		Summary:     "Stream the user's new transactions and payment status changes as Server-Sent Events",
+		Tags:        []string{"transactions"},
+		Parameters: []Parameter{
+			{Name: "user_id", In: "query", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}},
+		},
+		Responses: map[string]Response{
+			"200": {
+				Description: "Event stream of \"transaction\" events, each a TransactionEvent in JSON, starting with one of type \"subscribed\", and a final \"error\" event with the error envelope if the stream fails. Events are not replayed after a reconnect.",
+				Content:     map[string]MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}},
+			},
+			"default": errorResponseSpec(),
+		},
+	}}
+	b.messageSchema((&protoc.TransactionEvent{}).ProtoReflect().Descriptor())
+
+	doc.Components.Schemas = b.schemas
+	return doc
+}
+
+// openAPIBuilder collects the schemas of the messages operations use
+type openAPIBuilder struct {
+	schemas map[string]*Schema
+}
+
+// operation describes one transcoded route
+func (b *openAPIBuilder) operation(route Route) *Operation {
+	input := route.RPC.Input()
+	pathParams := route.PathParams()
+
+	op := &Operation{
+		OperationID: string(route.RPC.Name()),
+		Summary:     summary(string(route.RPC.Name())),
+		Tags:        []string{tag(route.Path)},
+		Responses:   map[string]Response{},
+	}
+
+	for _, name := range pathParams {
+		if field := input.Fields().ByName(protoreflect.Name(name)); field != nil {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: b.fieldSchema(field)})
+		}
+	}
+
+	// Fields not in the path come from the body, or from the query when there is no body
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	fields := input.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := string(field.Name())
+		if slices.Contains(pathParams, name) {
+			continue
+		}
+		if route.Body == "" {
+			if field.Kind() != protoreflect.MessageKind {
+				op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Schema: b.fieldSchema(field)})
+			}
+			continue
+		}
+		body.Properties[name] = b.fieldSchema(field)
+	}
+	if route.Body != "" {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: body}},
+		}
+	}
+
+	code, description := "200", "OK"
+	if createdMethods[route.RPC.Name()] {
+		code, description = "201", "Created"
+	}
+	op.Responses[code] = Response{
+		Description: description,
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{AllOf: []*Schema{
+			schemaRef("Success"),
+			b.messageSchema(route.RPC.Output()),
+		}}}},
+	}
+	op.Responses["default"] = errorResponseSpec()
+	return op
+}
+
+// messageSchema adds the schema of msg, and of the messages it contains, to the components
+// and returns a reference to it
+func (b *openAPIBuilder) messageSchema(msg protoreflect.MessageDescriptor) *Schema {
+	name := string(msg.FullName())
+	if _, ok := b.schemas[name]; ok {
+		return schemaRef(name)
+	}
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.schemas[name] = schema // Before the fields, so recursive messages end
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		schema.Properties[string(field.Name())] = b.fieldSchema(field)
+	}
+	return schemaRef(name)
+}
+
+// fieldSchema returns the schema of field as protojson encodes it
+func (b *openAPIBuilder) fieldSchema(field protoreflect.FieldDescriptor) *Schema {
+	var schema *Schema
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		schema = &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		schema = &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		schema = &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		schema = &Schema{Type: "string", Format: "int64"} // Strings keep 64-bit precision in JavaScript
+	case protoreflect.FloatKind:
+		schema = &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		schema = &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		schema = &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		schema = &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		schema = &Schema{Type: "string"}
+		values := field.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			schema.Enum = append(schema.Enum, string(values.Get(i).Name()))
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		schema = b.messageSchema(field.Message())
+	}
+
+	if field.IsList() {
+		return &Schema{Type: "array", Items: schema}
+	}
+	return schema
+}
+
+// errorResponseSpec is the response of every failed operation
+func errorResponseSpec() Response {
+	return Response{
+		Description: "Error",
+		Headers: map[string]Header{"Retry-After": {
+			Description: "Seconds to wait before retrying, when the call was rate limited or the backend is unavailable",
+			Schema:      &Schema{Type: "integer"},
+		}},
+		Content: map[string]MediaType{"application/json": {Schema: schemaRef("ErrorResponse")}},
+	}
+}
+
+// errorSchemas describes the success flag and the error envelope written by respondError
+func errorSchemas() map[string]*Schema {
+	return map[string]*Schema{
+		"Success": {
+			Type:       "object",
+			Properties: map[string]*Schema{"success": {Type: "boolean", Description: "Always true"}},
+			Required:   []string{"success"},
+		},
+		"ErrorResponse": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"success": {Type: "boolean", Description: "Always false"},
+				"error":   schemaRef("APIError"),
+			},
+			Required: []string{"success", "error"},
+		},
+		"APIError": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"code":       {Type: "integer", Description: "HTTP status"},
+				"reason":     {Type: "string", Description: "Stable machine readable cause, e.g. NOT_FOUND or PURCHASE_LIMIT_REACHED"},
+				"message":    {Type: "string"},
+				"request_id": {Type: "string"},
+				"details":    {Type: "array", Items: schemaRef("ErrorDetail")},
+			},
+			Required: []string{"code", "reason", "message"},
+		},
+		"ErrorDetail": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"type":                {Type: "string", Enum: []string{"field_violation", "retry"}},
+				"field":               {Type: "string"},
+				"description":         {Type: "string"},
+				"retry_after_seconds": {Type: "integer"},
+			},
+			Required: []string{"type"},
+		},
+	}
+}
+
+// summary turns a method name into a sentence, e.g. "CreateMerchantVoucher" into "Create merchant voucher"
+func summary(method string) string {
+	var b strings.Builder
+	for i, r := range method {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tag groups operations by the first path segment after the version, e.g. "admin" or "vouchers"
+func tag(path string) string {
+	rest := strings.TrimPrefix(path, "/api/v1/")
+	first, _, _ := strings.Cut(rest, "/")
+	return first
+}
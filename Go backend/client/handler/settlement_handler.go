@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/client/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/gin-gonic/gin"
+)
+
+type SettlementHandler struct {
+	grpcClient *service.GRPCClient
+}
+
+// NewSettlementHandler creates a new settlement handler
+func NewSettlementHandler(grpcClient *service.GRPCClient) *SettlementHandler {
+	return &SettlementHandler{
+		grpcClient: grpcClient,
+	}
+}
+
+// ExportBatch handles GET /api/v1/settlements/:batch_id/export?user_id= and returns a CSV file
+func (h *SettlementHandler) ExportBatch(c *gin.Context) {
+	userID, batchID, ok := h.parseBatchQuery(c)
+	if !ok {
+		return
+	}
+
+	// Build gRPC request
+	req := &protoc.ExportSettlementBatchRequest{
+		UserId:  int32(userID),
+		BatchId: int32(batchID),
+	}
+
+	// Call gRPC server
+	client := h.grpcClient.GetVoucherClient()
+	resp, err := client.ExportSettlementBatch(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.GetFilename()))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", resp.GetContent())
+}
+
+// parseBatchQuery reads the batch_id path parameter and user_id query parameter
+func (h *SettlementHandler) parseBatchQuery(c *gin.Context) (userID, batchID int, ok bool) {
+	batchID, err := strconv.Atoi(c.Param("batch_id"))
+	if err != nil {
+		badRequest(c, "invalid batch_id")
+		return 0, 0, false
+	}
+
+	userID, err = strconv.Atoi(c.Query("user_id"))
+	if err != nil {
+		badRequest(c, "invalid user_id")
+		return 0, 0, false
+	}
+
+	return userID, batchID, true
+}
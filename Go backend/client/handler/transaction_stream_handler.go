@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/client/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/gin-gonic/gin"
+)
+
+// sseRetry is how long browsers wait before reconnecting a dropped event stream
+const sseRetry = 3 * time.Second
+
+// TransactionStreamHandler serves the WatchTransactions stream to browsers as Server-Sent Events
+type TransactionStreamHandler struct {
+	grpcClient *service.GRPCClient
+	heartbeat  time.Duration
+	ctx        context.Context // Canceled by Shutdown
+	cancel     context.CancelFunc
+}
+
+// NewTransactionStreamHandler creates a new transaction stream handler sending a keep-alive
+// comment on streams that were idle for heartbeat
+func NewTransactionStreamHandler(grpcClient *service.GRPCClient, heartbeat time.Duration) *TransactionStreamHandler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TransactionStreamHandler{
+		grpcClient: grpcClient,
+		heartbeat:  heartbeat,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Shutdown ends the open streams, which would otherwise keep the HTTP server from draining
+func (h *TransactionStreamHandler) Shutdown() {
+	h.cancel()
+}
+
+// Stream handles GET /api/v1/transactions/stream?user_id=
+//
+// Each event is a "transaction" event whose data is a TransactionEvent in JSON. The first has
+// type "subscribed"; events are not replayed after a reconnect, so clients list transactions
+// whenever it arrives.
+func (h *TransactionStreamHandler) Stream(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Query("user_id"))
+	if err != nil {
+		badRequest(c, "invalid user_id")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	stop := context.AfterFunc(h.ctx, cancel)
+	defer stop()
+
+	// Call gRPC server
+	client := h.grpcClient.GetVoucherClient()
+	stream, err := client.WatchTransactions(ctx, &protoc.WatchTransactionsRequest{UserId: int32(userID)})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	// The server accepts the stream with a "subscribed" event; until then errors, such as an
+	// unknown user, still get a normal error response
+	first, err := stream.Recv()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Keep reverse proxies from buffering events
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry.Milliseconds())
+	c.Writer.Flush()
+
+	events := make(chan *protoc.TransactionEvent, 1)
+	events <- first
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return // Client went away or the gateway is shutting down
+		case event := <-events:
+			heartbeat.Reset(h.heartbeat)
+			var data []byte
+			if data, err = jsonOptions.Marshal(event); err == nil {
+				err = writeEvent(c.Writer, "transaction", data)
+			}
+		case <-heartbeat.C:
+			_, err = io.WriteString(c.Writer, ": keep-alive\n\n")
+		case streamErr := <-errc:
+			// Tell the client why the stream ended; EventSource reconnects on its own
+			_, body := errorResponse(c.Writer, c.Request, streamErr)
+			if data, err := json.Marshal(body); err == nil {
+				_ = writeEvent(c.Writer, "error", data)
+			}
+			c.Writer.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes one Server-Sent Event; data must be a single line, such as compact JSON
+func writeEvent(w io.Writer, name string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
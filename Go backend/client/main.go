@@ -1,48 +1,129 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/NavaneethWKT/CapStone_GO_Lang/client/config"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/client/handler"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/client/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/conf"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	httpPort     = ":8080"
-	grpcServerAddress = "localhost:50051"
+	serviceName = "voucher-api-gateway"
+
+	rateLimitCleanupInterval = time.Minute // Drop state of clients that went quiet
 )
 
 func main() {
-	log.Println("Starting REST API Gateway...")
+	cfg, opts, err := config.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logging.Fatal("Failed to load config", "error", err)
+	}
+	if opts.PrintConfig {
+		if err := conf.Print(os.Stdout, cfg); err != nil {
+			logging.Fatal("Failed to print config", "error", err)
+		}
+		return
+	}
+
+	logCfg, _ := cfg.Log.Config() // Already checked by LoadConfig
+	logging.Setup(logCfg)
+	slog.Info("Starting REST API Gateway...")
+	slog.Info("Configuration loaded successfully", "file", opts.File)
 
-	// Step 1: Connect to gRPC server
-	grpcClient, err := service.NewGRPCClient(grpcServerAddress)
+	traceExporter, _ := cfg.Tracing.ParseExporter()
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, traceExporter)
 	if err != nil {
-		log.Fatalf("Failed to connect to gRPC server: %v", err)
+		logging.Fatal("Failed to set up tracing", "error", err)
 	}
-	defer grpcClient.Close()
-	log.Println("Connected to gRPC server")
+	defer shutdownTracing(context.Background())
+	slog.Info("Tracing configured", "exporter", traceExporter)
 
-	// Step 2: Initialize handlers
-	loginHandler := handler.NewLoginHandler(grpcClient)
-	voucherHandler := handler.NewVoucherHandler(grpcClient)
-	paymentHandler := handler.NewPaymentHandler(grpcClient)
-	walletHandler := handler.NewWalletHandler(grpcClient)
-	transactionHandler := handler.NewTransactionHandler(grpcClient)
-	log.Println("Handlers initialized")
+	// Step 1: Connect to gRPC server (over TLS, presenting the client certificate for mutual TLS, if configured)
+	grpcTLS, err := cfg.GRPC.TLS.ClientConfig()
+	if err != nil {
+		logging.Fatal("Failed to set up gRPC TLS", "error", err)
+	}
+	var breaker *service.CircuitBreaker // Nil never trips
+	if cfg.GRPC.Breaker.Enabled {
+		breaker = service.NewCircuitBreaker(cfg.GRPC.Breaker.FailureThreshold, cfg.GRPC.Breaker.OpenTimeout)
+	}
+	grpcClient, err := service.NewGRPCClient(cfg.GRPC.ServerAddr, service.ClientOptions{
+		TLS: grpcTLS,
+		Retry: service.RetryPolicy{
+			MaxAttempts:    cfg.GRPC.Retry.MaxAttempts,
+			InitialBackoff: cfg.GRPC.Retry.InitialBackoff,
+			MaxBackoff:     cfg.GRPC.Retry.MaxBackoff,
+		},
+		Breaker:          breaker,
+		KeepaliveTime:    cfg.GRPC.Keepalive.Time,
+		KeepaliveTimeout: cfg.GRPC.Keepalive.Timeout,
+	})
+	if err != nil {
+		logging.Fatal("Failed to connect to gRPC server", "error", err)
+	}
+	slog.Info("Connected to gRPC server")
 
-	// Step 3: Setup Gin router
-	router := gin.Default()
+	// Step 2: Initialize handlers; the REST routes declared in voucher.proto are transcoded by the gateway
+	gateway, err := handler.NewGateway(context.Background(), grpcClient)
+	if err != nil {
+		logging.Fatal("Failed to set up REST gateway", "error", err)
+	}
+	docsHandler, err := handler.NewDocsHandler()
+	if err != nil {
+		logging.Fatal("Failed to build OpenAPI document", "error", err)
+	}
+	settlementHandler := handler.NewSettlementHandler(grpcClient)
+	transactionStreamHandler := handler.NewTransactionStreamHandler(grpcClient, cfg.HTTP.StreamHeartbeat)
+	healthHandler := handler.NewHealthHandler(grpcClient, cfg.GRPC.HealthTimeout)
+	slog.Info("Handlers initialized")
+
+	// Step 3: Setup Gin router (request IDs and structured access logs replace gin's default logger)
+	router := gin.New()
+	// The client IP drives rate limits and login lockouts, so X-Forwarded-For is only believed from known proxies
+	trustedProxies, _ := cfg.HTTP.Proxies() // Already checked by LoadConfig
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logging.Fatal("Failed to set trusted proxies", "error", err)
+	}
+	router.Use(tracing.GinMiddleware(), logging.GinMiddleware(logCfg), metrics.GinMiddleware(), gin.CustomRecovery(handler.Recovered))
+
+	// Every failed request gets the same JSON error envelope, whether a handler or a middleware rejected it
+	router.Use(handler.Errors())
+	router.NoRoute(handler.NotFound)
+
+	// Per client IP and route budgets, kept in memory
+	var limiter *ratelimit.Limiter // Nil allows everything
+	if cfg.RateLimit.Enabled {
+		rateLimitStore := ratelimit.NewMemoryStore()
+		rateLimitStore.StartJanitor(context.Background(), rateLimitCleanupInterval)
+		defaultLimit, routeLimits, _ := cfg.RateLimit.Limits() // Already checked by LoadConfig
+		limiter = ratelimit.NewLimiter(rateLimitStore, defaultLimit, routeLimits)
+	}
+	router.Use(ratelimit.GinMiddleware(limiter))
 
 	// Enable CORS
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -53,49 +134,62 @@ func main() {
 		c.Next()
 	})
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "healthy",
-			"service": "voucher-payment-api",
-		})
-	})
+	// Health endpoints: liveness of the gateway itself, readiness of the backend behind it
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Livez) // Kept for existing clients
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
-	// API routes
-	api := router.Group("/api/v1")
+	// API routes; their gRPC calls give up after the route's deadline
+	defaultDeadline, routeDeadlines, _ := cfg.GRPC.Deadlines() // Already checked by LoadConfig
+	api := router.Group("/api/v1", handler.Deadline(defaultDeadline, routeDeadlines))
 	{
-		// Auth routes
-		auth := api.Group("/auth")
-		{
-			auth.POST("/login", loginHandler.Login)
+		// Routes of the google.api.http options in voucher.proto, e.g. POST /auth/login
+		if err := gateway.Register(api); err != nil {
+			logging.Fatal("Failed to register REST routes", "error", err)
 		}
 
-		// Voucher routes
-		vouchers := api.Group("/vouchers")
-		{
-			vouchers.GET("/search", voucherHandler.Search)
-			vouchers.POST("/buy", paymentHandler.BuyVoucher)
-		}
+		// Settlement CSV download, a file rather than JSON so not transcoded
+		api.GET("/settlements/:batch_id/export", settlementHandler.ExportBatch)
+	}
 
-		// Wallet routes
-		wallet := api.Group("/wallet")
-		{
-			wallet.GET("/balance/:user_id", walletHandler.GetBalance)
-		}
+	// Live transaction updates as Server-Sent Events; the stream stays open, so no route deadline
+	router.GET("/api/v1/transactions/stream", transactionStreamHandler.Stream)
 
-		// Transaction routes
-		transactions := api.Group("/transactions")
-		{
-			transactions.GET("/:user_id", transactionHandler.ListTransactions)
-		}
+	// API documentation: the OpenAPI document and Swagger UI to browse and try it
+	router.GET("/api/v1/openapi.json", docsHandler.Spec)
+	router.GET("/api/v1/docs", docsHandler.UI)
+	router.GET("/api/v1/docs/*filepath", docsHandler.Asset)
+
+	httpTLS, err := cfg.HTTP.TLS.ServerConfig("h2", "http/1.1")
+	if err != nil {
+		logging.Fatal("Failed to set up HTTP TLS", "error", err)
 	}
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           router,
+		TLSConfig:         httpTLS,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+	server.RegisterOnShutdown(transactionStreamHandler.Shutdown) // Open event streams would never drain
 
-	log.Printf("REST API Gateway listening on %s", httpPort)
+	slog.Info("REST API Gateway listening", "addr", cfg.HTTP.Addr, "tls", cfg.HTTP.TLS.Enabled)
 
 	// Step 4: Start server in goroutine
 	go func() {
-		if err := router.Run(httpPort); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
+		var err error
+		if httpTLS != nil {
+			err = server.ListenAndServeTLS("", "") // Certificates come from TLSConfig
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Failed to start server", "error", err)
 		}
 	}()
 
@@ -104,7 +198,21 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down REST API Gateway...")
-	log.Println("Server stopped")
+	slog.Info("Shutting down REST API Gateway...")
+	healthHandler.Drain()
+
+	// Stop accepting connections and let in-flight requests, such as purchases, finish
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("Gateway did not drain in time, closing remaining connections", "timeout", cfg.HTTP.ShutdownTimeout, "error", err)
+		server.Close()
+	}
+
+	// The gRPC connection is only needed until the last request is done
+	if err := grpcClient.Close(); err != nil {
+		slog.Warn("Failed to close gRPC connection", "error", err)
+	}
+	slog.Info("Server stopped")
 }
 
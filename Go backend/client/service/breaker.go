@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Circuit breaker states
+const (
+	breakerClosed   = "closed"    // Calls go through
+	breakerOpen     = "open"      // Calls fail fast until the open timeout passes
+	breakerHalfOpen = "half-open" // One probe call decides whether to close or open again
+)
+
+// healthServicePrefix marks health checks, which bypass the breaker so /readyz reports the backend's real state
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// CircuitBreaker stops calling the backend after consecutive failures that say it is
+// unhealthy, failing fast with codes.Unavailable instead of waiting for every deadline
+type CircuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures and lets a probe call through after openTimeout
+func NewCircuitBreaker(threshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		state:       breakerClosed,
+	}
+}
+
+// allow reports whether a call may go to the backend, or how long until the breaker lets one through
+func (b *CircuitBreaker) allow(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if wait := b.openedAt.Add(b.openTimeout).Sub(now); wait > 0 {
+			return false, wait
+		}
+		b.setState(breakerHalfOpen)
+		fallthrough
+	case breakerHalfOpen:
+		if b.probing {
+			return false, b.openTimeout
+		}
+		b.probing = true
+	}
+	return true, 0
+}
+
+// record updates the breaker with the outcome of a call
+func (b *CircuitBreaker) record(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !backendFailure(err) {
+		b.failures = 0
+		if b.state != breakerClosed {
+			b.setState(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = now
+		if b.state != breakerOpen {
+			b.setState(breakerOpen)
+		}
+	}
+}
+
+// release lets another probe through after a call ended without telling anything about the backend
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) setState(state string) {
+	level := slog.LevelWarn
+	if state == breakerClosed {
+		level = slog.LevelInfo
+	}
+	slog.Log(context.Background(), level, "gRPC circuit breaker changed state", "from", b.state, "to", state, "failures", b.failures)
+	b.state = state
+}
+
+// backendFailure reports whether err means the backend is unreachable or too slow,
+// as opposed to rejecting the request itself
+func backendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// UnaryClientInterceptor applies the breaker to every call except health checks; a nil breaker allows everything
+func (b *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if b == nil || strings.HasPrefix(method, healthServicePrefix) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ok, wait := b.allow(time.Now())
+		if !ok {
+			return openError(wait)
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		// A call cancelled by the HTTP client says nothing about the backend
+		if errors.Is(ctx.Err(), context.Canceled) {
+			b.release()
+		} else {
+			b.record(time.Now(), err)
+		}
+		return err
+	}
+}
+
+// openError fails a call fast, telling the caller when to try again
+func openError(wait time.Duration) error {
+	st := status.New(codes.Unavailable, "service temporarily unavailable, try again later")
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -1,22 +1,70 @@
 package service
 
 import (
-	"log"
+	"crypto/tls"
+	"log/slog"
+	"time"
 
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type GRPCClient struct {
 	voucherClient protoc.VoucherServiceClient
+	healthClient  healthpb.HealthClient
 	conn          *grpc.ClientConn
 }
 
+// ClientOptions configure the connection to the gRPC server
+type ClientOptions struct {
+	TLS              *tls.Config // Nil connects in plaintext
+	Retry            RetryPolicy
+	Breaker          *CircuitBreaker // Nil disables circuit breaking
+	KeepaliveTime    time.Duration   // Ping the server after this long without activity
+	KeepaliveTimeout time.Duration   // Close the connection if a ping isn't answered in time
+}
+
 // NewGRPCClient creates a new gRPC client connection
-func NewGRPCClient(serverAddress string) (*GRPCClient, error) {
+func NewGRPCClient(serverAddress string, opts ClientOptions) (*GRPCClient, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLS != nil {
+		creds = credentials.NewTLS(opts.TLS)
+	}
+
+	// Retries of idempotent calls and default deadlines per method
+	sc, err := serviceConfig(opts.Retry)
+	if err != nil {
+		return nil, err
+	}
+
 	// Connect to gRPC server
-	conn, err := grpc.NewClient(serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(
+		serverAddress,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(sc),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                opts.KeepaliveTime,
+			Timeout:             opts.KeepaliveTimeout,
+			PermitWithoutStream: true, // Notice a dead server before the next request does
+		}),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),          // Propagate trace context
+		grpc.WithChainUnaryInterceptor(
+			logging.UnaryClientInterceptor(),      // Forward request IDs
+			ratelimit.UnaryClientInterceptor(),    // Forward client IPs for per-client limits and login lockouts
+			opts.Breaker.UnaryClientInterceptor(), // Fail fast while the backend is down
+		),
+		grpc.WithChainStreamInterceptor(
+			logging.StreamClientInterceptor(),
+			ratelimit.StreamClientInterceptor(),
+		),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -24,10 +72,11 @@ func NewGRPCClient(serverAddress string) (*GRPCClient, error) {
 	// Create client
 	voucherClient := protoc.NewVoucherServiceClient(conn)
 
-	log.Printf("Connected to gRPC server at %s", serverAddress)
+	slog.Info("Connected to gRPC server", "addr", serverAddress)
 
 	return &GRPCClient{
 		voucherClient: voucherClient,
+		healthClient:  healthpb.NewHealthClient(conn),
 		conn:          conn,
 	}, nil
 }
@@ -37,6 +86,11 @@ func (c *GRPCClient) GetVoucherClient() protoc.VoucherServiceClient {
 	return c.voucherClient
 }
 
+// GetHealthClient returns the standard gRPC health client of the server
+func (c *GRPCClient) GetHealthClient() healthpb.HealthClient {
+	return c.healthClient
+}
+
 // Close closes the gRPC connection
 func (c *GRPCClient) Close() error {
 	return c.conn.Close()
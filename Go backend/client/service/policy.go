@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+)
+
+// idempotentMethods, which must have an entry in methodTimeouts, may be retried safely: repeating them has no side effects.
+// Purchases and other writes are never retried, so a lost response can't charge a user twice.
+var idempotentMethods = []string{"Search", "GetBalance", "ListTransactions"}
+
+// Default deadlines of VoucherService calls; a shorter route deadline of the HTTP request still wins
+const defaultMethodTimeout = 10 * time.Second
+
+var methodTimeouts = map[string]time.Duration{
+	"Login":            5 * time.Second,
+	"Search":           5 * time.Second,
+	"GetBalance":       3 * time.Second,
+	"ListTransactions": 5 * time.Second,
+	"BuyVoucher":       15 * time.Second,
+}
+
+// streamingMethods stay open as long as the client watches, so they get no default deadline
+var streamingMethods = []string{"WatchTransactions"}
+
+// RetryPolicy configures retries of idempotent calls that failed with codes.Unavailable
+type RetryPolicy struct {
+	MaxAttempts    int // Including the first call; 1 disables retries
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// serviceConfig returns the gRPC service config with the retry policy and method deadlines
+func serviceConfig(retry RetryPolicy) (string, error) {
+	service := protoc.VoucherService_ServiceDesc.ServiceName
+
+	type methodConfig struct {
+		Name        []map[string]string `json:"name"`
+		Timeout     string              `json:"timeout,omitempty"`
+		RetryPolicy map[string]any      `json:"retryPolicy,omitempty"`
+	}
+
+	configs := []methodConfig{{
+		Name:    []map[string]string{{"service": service}}, // Every other method
+		Timeout: grpcDuration(defaultMethodTimeout),
+	}}
+	for _, method := range slices.Sorted(maps.Keys(methodTimeouts)) {
+		mc := methodConfig{
+			Name:    []map[string]string{{"service": service, "method": method}},
+			Timeout: grpcDuration(methodTimeouts[method]),
+		}
+		if retry.MaxAttempts > 1 && slices.Contains(idempotentMethods, method) {
+			mc.RetryPolicy = map[string]any{
+				"maxAttempts":          retry.MaxAttempts,
+				"initialBackoff":       grpcDuration(retry.InitialBackoff),
+				"maxBackoff":           grpcDuration(retry.MaxBackoff),
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE"},
+			}
+		}
+		configs = append(configs, mc)
+	}
+	for _, method := range streamingMethods {
+		configs = append(configs, methodConfig{
+			Name: []map[string]string{{"service": service, "method": method}},
+		})
+	}
+
+	sc, err := json.Marshal(map[string]any{
+		"methodConfig": configs,
+		// Stop retrying when most calls fail, so retries don't pile onto a struggling backend
+		"retryThrottling": map[string]any{"maxTokens": 10, "tokenRatio": 0.1},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(sc), nil
+}
+
+// grpcDuration formats d the way service configs expect, e.g. "0.100s"
+func grpcDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
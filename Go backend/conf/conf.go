@@ -0,0 +1,343 @@
+// Package conf loads configuration shared by the gRPC server and the REST gateway.
+// Each layer overrides the one before it:
+//
+//  1. defaults from `default` struct tags
+//  2. an optional YAML (.yaml, .yml) or TOML (.toml) file named by --config or CONFIG_FILE
+//  3. environment variables, including those from an optional .env file
+//  4. command line flags
+//
+// Config structs describe their settings with struct tags:
+//
+//	Addr string `conf:"addr" default:":8080" usage:"listen address"`
+//
+// The conf tag names the key in config files; nested structs with a conf tag become sections.
+// Environment variable and flag names are derived from the key path (http.addr becomes
+// HTTP_ADDR and --http-addr) unless env or flag tags override them. Fields tagged
+// secret:"true" are redacted by Print.
+package conf
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Options describe how a config was loaded
+type Options struct {
+	File        string   // Config file that was read, if any
+	PrintConfig bool     // --print-config was given
+	Args        []string // Arguments left after the flags
+}
+
+// Validator is implemented by configs that check their values once all layers are applied
+type Validator interface {
+	Validate() error
+}
+
+// field is one setting of a config struct
+type field struct {
+	key    string // Dotted path in config files, e.g. "db.host"
+	env    string
+	flag   string
+	def    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// Load fills cfg, a pointer to a config struct, from its defaults, the config file,
+// the environment and args, the command line arguments of the named program.
+// Load returns flag.ErrHelp if args ask for usage.
+func Load(cfg any, name string, args []string) (Options, error) {
+	var opts Options
+
+	fields, err := fieldsOf(cfg)
+	if err != nil {
+		return opts, err
+	}
+
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return opts, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	// Flags are parsed first because --config picks the file, but they are applied last
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.StringVar(&opts.File, "config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file (env CONFIG_FILE)")
+	flagSet.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	for _, f := range fields {
+		flagSet.Var(&flagValue{field: f}, f.flag, fmt.Sprintf("%s (env %s)", f.usage, f.env))
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return opts, err
+	}
+	opts.Args = flagSet.Args()
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := f.set(f.def); err != nil {
+			return opts, fmt.Errorf("invalid default for %s: %w", f.key, err)
+		}
+	}
+
+	if opts.File != "" {
+		if err := loadFile(opts.File, fields); err != nil {
+			return opts, err
+		}
+	}
+
+	for _, f := range fields {
+		value := os.Getenv(f.env)
+		if value == "" {
+			continue
+		}
+		if err := f.set(value); err != nil {
+			return opts, fmt.Errorf("invalid %s: %w", f.env, err)
+		}
+	}
+
+	flagSet.Visit(func(fl *flag.Flag) {
+		if v, ok := fl.Value.(*flagValue); ok {
+			v.field.set(v.raw) // Already checked by Set
+		}
+	})
+
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return opts, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	return opts, nil
+}
+
+// fieldsOf lists the settings of cfg in declaration order
+func fieldsOf(cfg any) ([]*field, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("conf: config must be a pointer to a struct")
+	}
+
+	var fields []*field
+	if err := walk(v.Elem(), nil, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func walk(v reflect.Value, path []string, fields *[]*field) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		key := sf.Tag.Get("conf")
+		if key == "" || !sf.IsExported() {
+			continue
+		}
+		fieldPath := append(slices.Clip(path), key)
+
+		if sf.Type.Kind() == reflect.Struct {
+			if err := walk(v.Field(i), fieldPath, fields); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f := &field{
+			key:    strings.Join(fieldPath, "."),
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			def:    sf.Tag.Get("default"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		}
+		if !supported(sf.Type) {
+			return fmt.Errorf("conf: unsupported type %s for %s", sf.Type, f.key)
+		}
+		if f.env == "" {
+			f.env = strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+		}
+		if f.flag == "" {
+			f.flag = strings.NewReplacer(".", "-", "_", "-").Replace(f.key)
+		}
+		*fields = append(*fields, f)
+	}
+	return nil
+}
+
+// supported reports whether settings of type t can be parsed
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parse converts a string from any layer to a value of type t
+func parse(t reflect.Type, value string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	value = strings.TrimSpace(value)
+
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a duration", value)
+		}
+		v.SetInt(int64(d))
+	case t.Kind() == reflect.String:
+		v.SetString(value)
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a boolean", value)
+		}
+		v.SetBool(b)
+	case t.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a number", value)
+		}
+		v.SetFloat(f)
+	default:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("%q is not an integer", value)
+		}
+		v.SetInt(n)
+	}
+	return v, nil
+}
+
+// set parses value into the field
+func (f *field) set(value string) error {
+	v, err := parse(f.value.Type(), value)
+	if err != nil {
+		return err
+	}
+	f.value.Set(v)
+	return nil
+}
+
+// flagValue records a flag until the lower layers are applied
+type flagValue struct {
+	field *field
+	raw   string
+}
+
+func (v *flagValue) String() string {
+	if v.field == nil {
+		return ""
+	}
+	return v.field.def
+}
+
+func (v *flagValue) Set(value string) error {
+	if _, err := parse(v.field.value.Type(), value); err != nil {
+		return err
+	}
+	v.raw = value
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as a bare --flag
+func (v *flagValue) IsBoolFlag() bool {
+	return v.field != nil && v.field.value.Kind() == reflect.Bool
+}
+
+// loadFile applies a YAML or TOML config file; unknown keys are an error so typos don't go unnoticed
+func loadFile(path string, fields []*field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return fmt.Errorf("unsupported config file %s: must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(doc, "", values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byKey := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		f, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("%s: unknown key %q", path, key)
+		}
+		if err := f.set(values[key]); err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+// flatten turns nested sections into dotted keys
+func flatten(section map[string]any, prefix string, values map[string]string) error {
+	for name, value := range section {
+		key := prefix + name
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(value, key+".", values); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("%s: lists are not supported", key)
+		case nil:
+			// An empty key keeps the value from the layer below
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// CheckAddr validates a host:port listen or dial address
+func CheckAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid port in address %q", addr)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package conf
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// redacted replaces the values of secret settings in printed configs
+const redacted = "<redacted>"
+
+// Print writes cfg as YAML in the layout Load reads config files, with secrets redacted
+func Print(w io.Writer, cfg any) error {
+	fields, err := fieldsOf(cfg)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.MapSlice
+	for _, f := range fields {
+		doc = insert(doc, strings.Split(f.key, "."), f.display())
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// display returns the value to print for the field
+func (f *field) display() any {
+	switch {
+	case f.secret && !f.value.IsZero():
+		return redacted
+	case f.value.Type() == durationType:
+		return time.Duration(f.value.Int()).String()
+	}
+	return f.value.Interface()
+}
+
+// insert adds value at path, keeping sections in the order their first setting appears
+func insert(section yaml.MapSlice, path []string, value any) yaml.MapSlice {
+	if len(path) == 1 {
+		return append(section, yaml.MapItem{Key: path[0], Value: value})
+	}
+
+	for i, item := range section {
+		if sub, ok := item.Value.(yaml.MapSlice); ok && item.Key == path[0] {
+			section[i].Value = insert(sub, path[1:], value)
+			return section
+		}
+	}
+	return append(section, yaml.MapItem{Key: path[0], Value: insert(nil, path[1:], value)})
+}
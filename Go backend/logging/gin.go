@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GinMiddleware assigns each HTTP request an ID (reusing the caller's X-Request-ID),
+// returns it in the response header and writes one access log line per request at
+// info, warn (4xx) or error (5xx). Routes are matched in cfg.MethodLevels as "METHOD /route/:param".
+func GinMiddleware(cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		ctx := withMethodLevel(c.Request.Context(), cfg.LevelFor(c.Request.Method+" "+route))
+		logger := slog.Default()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("request_id", requestID),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		// Query strings are logged with sensitive parameters redacted
+		if query := c.Request.URL.Query(); len(query) > 0 {
+			params := map[string]any{}
+			for key, values := range query {
+				if IsSensitive(key) {
+					params[key] = redacted
+				} else if len(values) == 1 {
+					params[key] = values[0]
+				} else {
+					params[key] = values
+				}
+			}
+			attrs = append(attrs, slog.Any("query", params))
+		}
+
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		logger.LogAttrs(ctx, level, "HTTP request", attrs...)
+	}
+}
@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor logs each RPC with its user ID, status code and duration; the redacted
+// request is included when the method logs at debug level. Successful calls log at info, client
+// errors at warn and server errors at error. The caller's x-request-id metadata is reused (or a
+// new ID generated), stored in the context and echoed back in the response header.
+func UnaryServerInterceptor(cfg Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		ctx, requestID := serverRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+
+		// Call the handler
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch {
+		case isServerError(code):
+			level = slog.LevelError
+		case code != codes.OK:
+			level = slog.LevelWarn
+		}
+
+		minLevel := cfg.LevelFor(info.FullMethod)
+		logCtx := withMethodLevel(ctx, minLevel)
+		logger := slog.Default()
+		if !logger.Enabled(logCtx, level) {
+			return resp, err
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("request_id", requestID),
+			slog.String("code", code.String()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+
+		if msg, ok := req.(proto.Message); ok {
+			if userID := UserID(msg); userID != 0 {
+				attrs = append(attrs, slog.Int64("user_id", userID))
+			}
+			if minLevel <= slog.LevelDebug {
+				attrs = append(attrs, slog.Any("request", Redact(msg)))
+			}
+		}
+
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+
+		logger.LogAttrs(logCtx, level, "gRPC request", attrs...)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs each streaming RPC when it ends, like UnaryServerInterceptor
+// logs unary ones, and gives the stream's context the request ID
+func StreamServerInterceptor(cfg Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		ctx, requestID := serverRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDHeader, requestID))
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch {
+		case isServerError(code):
+			level = slog.LevelError
+		case code != codes.OK:
+			level = slog.LevelWarn
+		}
+
+		logCtx := withMethodLevel(ctx, cfg.LevelFor(info.FullMethod))
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("request_id", requestID),
+			slog.String("code", code.String()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+
+		slog.Default().LogAttrs(logCtx, level, "gRPC stream", attrs...)
+		return err
+	}
+}
+
+// serverRequestID stores the caller's x-request-id metadata, or a new ID, in the context
+func serverRequestID(ctx context.Context) (context.Context, string) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	return WithRequestID(ctx, requestID), requestID
+}
+
+// serverStream replaces the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor forwards the context's request ID to the server as x-request-id metadata
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor forwards the context's request ID like UnaryClientInterceptor, for streams
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, requestID)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// isServerError reports whether a status code indicates a fault on the server side
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+// Package logging sets up structured slog logging shared by the gRPC server and the REST gateway.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// LevelOff silences a method entirely when used as its log level
+const LevelOff = slog.Level(100)
+
+// RequestIDHeader carries the request ID in HTTP headers and gRPC metadata
+const RequestIDHeader = "x-request-id"
+
+// Config controls log output
+type Config struct {
+	Format       string                // "text" or "json"
+	Level        slog.Level            // Minimum level for everything not listed in MethodLevels
+	MethodLevels map[string]slog.Level // Minimum level per gRPC method ("/voucher.VoucherService/Search") or HTTP route ("GET /api/v1/vouchers/search")
+}
+
+// Settings are the log options as given in config files, environment variables and flags.
+// They live in the "log" section, so LOG_FORMAT, LOG_LEVEL and LOG_METHOD_LEVELS set them.
+type Settings struct {
+	Format       string `conf:"format" default:"text" usage:"log format: text or json"`
+	Level        string `conf:"level" default:"info" usage:"minimum log level: debug, info, warn or error"`
+	MethodLevels string `conf:"method_levels" usage:"per method levels, e.g. \"/voucher.VoucherService/Search=debug,GET /health=off\""`
+}
+
+// Config parses the settings. MethodLevels is a comma separated list of method=level pairs.
+func (s Settings) Config() (Config, error) {
+	cfg := Config{
+		Format:       strings.ToLower(s.Format),
+		MethodLevels: map[string]slog.Level{},
+	}
+
+	if cfg.Format != "text" && cfg.Format != "json" {
+		return cfg, fmt.Errorf("invalid log format %q: must be text or json", cfg.Format)
+	}
+
+	level, err := ParseLevel(s.Level)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid log level: %w", err)
+	}
+	cfg.Level = level
+
+	if err := cfg.parseMethodLevels(s.MethodLevels); err != nil {
+		return cfg, fmt.Errorf("invalid log method levels: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// parseMethodLevels parses "method=level" pairs into MethodLevels
+func (c *Config) parseMethodLevels(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return fmt.Errorf("%q is not a method=level pair", pair)
+		}
+
+		level, err := ParseLevel(pair[i+1:])
+		if err != nil {
+			return err
+		}
+		c.MethodLevels[strings.TrimSpace(pair[:i])] = level
+	}
+
+	return nil
+}
+
+// ParseLevel parses debug, info, warn, error or off
+func ParseLevel(value string) (slog.Level, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "off") {
+		return LevelOff, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", value)
+	}
+
+	return level, nil
+}
+
+// LevelFor returns the minimum level of request logs for a method
+func (c Config) LevelFor(method string) slog.Level {
+	if level, ok := c.MethodLevels[method]; ok {
+		return level
+	}
+	return c.Level
+}
+
+// Setup builds the logger described by cfg and installs it as the slog and log default
+func Setup(cfg Config) *slog.Logger {
+	return setup(os.Stdout, cfg)
+}
+
+func setup(w io.Writer, cfg Config) *slog.Logger {
+	// Per-method levels may be below the global level, so the inner handler lets those records through
+	minLevel := cfg.Level
+	for _, level := range cfg.MethodLevels {
+		if level < minLevel {
+			minLevel = level
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: minLevel}
+
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	logger := slog.New(&levelHandler{Handler: handler, level: cfg.Level})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// levelHandler applies the global level, or a method's own level for request logs
+// whose context carries one (see withMethodLevel)
+type levelHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+type methodLevelKey struct{}
+
+// Enabled reports whether a record at level should be logged
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if methodLevel, ok := ctx.Value(methodLevelKey{}).(slog.Level); ok {
+		return level >= methodLevel
+	}
+	return level >= h.level
+}
+
+// WithAttrs returns a handler with the attributes added
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+// WithGroup returns a handler that nests attributes under name
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
+// withMethodLevel makes records logged with ctx use a method's minimum level
+func withMethodLevel(ctx context.Context, level slog.Level) context.Context {
+	return context.WithValue(ctx, methodLevelKey{}, level)
+}
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random 16 byte hex request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID stores a request ID in ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the default logger with the request ID of ctx attached
+func FromContext(ctx context.Context) *slog.Logger {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return slog.Default().With("request_id", requestID)
+	}
+	return slog.Default()
+}
+
+// Fatal logs msg at error level and exits, replacing log.Fatalf
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redacted replaces the value of sensitive fields
+const redacted = "[REDACTED]"
+
+// IsSensitive reports whether a field name holds a secret: passwords, tokens,
+// secrets, API keys and promo or voucher codes
+func IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "password"),
+		strings.Contains(name, "token"),
+		strings.Contains(name, "secret"),
+		strings.Contains(name, "api_key"),
+		strings.Contains(name, "authorization"):
+		return true
+	case name == "code" || strings.HasSuffix(name, "_code"):
+		return true
+	default:
+		return false
+	}
+}
+
+// Redact converts a proto message to a map for logging with sensitive fields replaced.
+// Unset fields are omitted; the message itself is not modified.
+func Redact(msg proto.Message) map[string]any {
+	if msg == nil {
+		return nil
+	}
+	return redactMessage(msg.ProtoReflect())
+}
+
+func redactMessage(m protoreflect.Message) map[string]any {
+	out := map[string]any{}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if IsSensitive(name) {
+			out[name] = redacted
+			return true
+		}
+		out[name] = redactValue(fd, v)
+		return true
+	})
+	return out
+}
+
+func redactValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]any, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			items = append(items, scalarOrMessage(fd, list.Get(i)))
+		}
+		return items
+	case fd.IsMap():
+		items := map[string]any{}
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			items[k.String()] = scalarOrMessage(fd.MapValue(), mv)
+			return true
+		})
+		return items
+	default:
+		return scalarOrMessage(fd, v)
+	}
+}
+
+func scalarOrMessage(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return redactMessage(v.Message())
+	case protoreflect.BytesKind:
+		return len(v.Bytes()) // Log the size, not the content
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+// UserID returns the user_id (or admin_id) field of a request, or 0 if it has none
+func UserID(msg proto.Message) int64 {
+	if msg == nil {
+		return 0
+	}
+
+	m := msg.ProtoReflect()
+	for _, name := range []protoreflect.Name{"user_id", "admin_id"} {
+		fd := m.Descriptor().Fields().ByName(name)
+		if fd == nil || !m.Has(fd) {
+			continue
+		}
+		switch fd.Kind() {
+		case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+			return m.Get(fd).Int()
+		}
+	}
+
+	return 0
+}
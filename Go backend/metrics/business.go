@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	upiPayments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upi",
+		Name:      "payments_total",
+		Help:      "Mock UPI payment attempts, by result (success or failure).",
+	}, []string{"result"})
+
+	upiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upi",
+		Name:      "payment_duration_seconds",
+		Help:      "Mock UPI payment latency, by result.",
+		Buckets:   []float64{0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 0.75, 1, 2.5},
+	}, []string{"result"})
+
+	vouchersSold = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vouchers_sold_total",
+		Help:      "Vouchers sold, by category.",
+	}, []string{"category"})
+
+	revenue = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_total",
+		Help:      "Amount charged for sold vouchers after discounts, by category.",
+	}, []string{"category"})
+
+	discounts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "discount_total",
+		Help:      "Promo code discounts granted on sold vouchers, by category.",
+	}, []string{"category"})
+
+	refunds = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refunds_total",
+		Help:      "Refunds issued.",
+	})
+
+	refundAmount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refund_amount_total",
+		Help:      "Amount refunded.",
+	})
+)
+
+// ObservePayment records the result and latency of a Mock UPI payment
+func ObservePayment(success bool, duration time.Duration) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	upiPayments.WithLabelValues(result).Inc()
+	upiDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// RecordSale records a committed voucher purchase
+func RecordSale(category string, amount, discount float64) {
+	vouchersSold.WithLabelValues(category).Inc()
+	revenue.WithLabelValues(category).Add(amount)
+	if discount > 0 {
+		discounts.WithLabelValues(category).Add(discount)
+	}
+}
+
+// RecordRefund records a committed refund
+func RecordRefund(amount float64) {
+	refunds.Inc()
+	refundAmount.Add(amount)
+}
@@ -0,0 +1,46 @@
+// Package metrics defines the Prometheus metrics exposed by the gRPC server and the REST gateway.
+// Metrics are kept in the default registry and scraped from /metrics; nothing is pushed,
+// so no external service needs to be running.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "voucher"
+
+// dbUp is registered by RegisterDB, so it is absent when there is no database
+var dbUp = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Subsystem: "db",
+	Name:      "up",
+	Help:      "Whether the last database health check succeeded (1) or failed (0).",
+})
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB exports connection pool stats (open, in use, idle, waits) and health of a database
+func RegisterDB(db *sql.DB, dbName string) error {
+	if err := prometheus.Register(dbUp); err != nil {
+		return err
+	}
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// SetDBUp records the result of a database health check
+func SetDBUp(up bool) {
+	if up {
+		dbUp.Set(1)
+	} else {
+		dbUp.Set(0)
+	}
+}
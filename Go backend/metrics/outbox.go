@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	outboxDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "deliveries_total",
+		Help:      "Outbox event delivery attempts, by sink and result (success or failure).",
+	}, []string{"sink", "result"})
+
+	outboxDeliveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "delivery_duration_seconds",
+		Help:      "Outbox event delivery latency, by sink.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"sink"})
+
+	outboxDeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "dead_lettered_total",
+		Help:      "Outbox events given up on after their last attempt, by event type.",
+	}, []string{"event_type"})
+)
+
+// ObserveOutboxDelivery records the result and latency of delivering an outbox event to a sink
+func ObserveOutboxDelivery(sink string, success bool, duration time.Duration) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	outboxDeliveries.WithLabelValues(sink, result).Inc()
+	outboxDeliveryDuration.WithLabelValues(sink).Observe(duration.Seconds())
+}
+
+// RecordOutboxDeadLetter records an outbox event that will not be retried again
+func RecordOutboxDeadLetter(eventType string) {
+	outboxDeadLettered.WithLabelValues(eventType).Inc()
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "merchant_webhooks",
+		Name:      "deliveries_total",
+		Help:      "Merchant webhook delivery attempts, by result (success or failure).",
+	}, []string{"result"})
+
+	webhookDeliveryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "merchant_webhooks",
+		Name:      "delivery_duration_seconds",
+		Help:      "Merchant webhook request latency.",
+		Buckets:   prometheus.DefBuckets,
+	})
+)
+
+// ObserveWebhookDelivery records the result and latency of an attempt to deliver a merchant webhook
+func ObserveWebhookDelivery(success bool, duration time.Duration) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	webhookDeliveries.WithLabelValues(result).Inc()
+	webhookDeliveryDuration.Observe(duration.Seconds())
+}
@@ -0,0 +1,2 @@
+-- Remove role column from users table
+ALTER TABLE users DROP COLUMN IF EXISTS role;
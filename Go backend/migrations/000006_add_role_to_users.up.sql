@@ -0,0 +1,2 @@
+-- Add role column to users table
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(50) NOT NULL DEFAULT 'customer' CHECK (role IN ('customer', 'admin'));
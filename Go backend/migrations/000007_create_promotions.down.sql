@@ -0,0 +1,7 @@
+-- Remove promotion columns from transactions table
+ALTER TABLE transactions DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS promotion_id;
+
+-- Drop promotion tables
+DROP TABLE IF EXISTS promotion_redemptions CASCADE;
+DROP TABLE IF EXISTS promotions CASCADE;
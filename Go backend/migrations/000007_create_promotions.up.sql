@@ -0,0 +1,45 @@
+-- Create promotions table
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(50) NOT NULL CHECK (discount_type IN ('percentage', 'flat')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    max_discount DECIMAL(10, 2) CHECK (max_discount > 0),
+    min_spend DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (min_spend >= 0),
+    category VARCHAR(100),
+    usage_limit INT CHECK (usage_limit > 0),
+    per_user_limit INT CHECK (per_user_limit > 0),
+    times_used INT NOT NULL DEFAULT 0 CHECK (times_used >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP NOT NULL,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_promotion_valid_dates CHECK (valid_to > valid_from),
+    CONSTRAINT chk_percentage_discount CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create promotion redemptions table
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id SERIAL PRIMARY KEY,
+    promotion_id INT NOT NULL,
+    user_id INT NOT NULL,
+    transaction_id INT NOT NULL,
+    discount_amount DECIMAL(10, 2) NOT NULL CHECK (discount_amount >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- Record the applied promotion on transactions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS promotion_id INT REFERENCES promotions(id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_promotions_code ON promotions(code);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_promotion_user ON promotion_redemptions(promotion_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_promotion_id ON transactions(promotion_id);
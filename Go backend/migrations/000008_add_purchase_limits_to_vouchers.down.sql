@@ -0,0 +1,4 @@
+-- Remove per-user purchase limits from vouchers table
+DROP INDEX IF EXISTS idx_transactions_user_voucher;
+ALTER TABLE vouchers DROP COLUMN IF EXISTS max_per_user_per_day;
+ALTER TABLE vouchers DROP COLUMN IF EXISTS max_per_user;
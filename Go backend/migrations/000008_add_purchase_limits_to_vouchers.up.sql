@@ -0,0 +1,6 @@
+-- Add per-user purchase limits to vouchers table (NULL means unlimited)
+ALTER TABLE vouchers ADD COLUMN IF NOT EXISTS max_per_user INT CHECK (max_per_user > 0);
+ALTER TABLE vouchers ADD COLUMN IF NOT EXISTS max_per_user_per_day INT CHECK (max_per_user_per_day > 0);
+
+-- Create index for counting a user's purchases of a voucher
+CREATE INDEX IF NOT EXISTS idx_transactions_user_voucher ON transactions(user_id, voucher_id, created_at);
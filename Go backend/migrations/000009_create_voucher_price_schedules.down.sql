@@ -0,0 +1,2 @@
+-- Drop voucher price schedules table
+DROP TABLE IF EXISTS voucher_price_schedules CASCADE;
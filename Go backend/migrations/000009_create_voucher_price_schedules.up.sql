@@ -0,0 +1,21 @@
+-- Create voucher price schedules table (flash sales)
+CREATE TABLE IF NOT EXISTS voucher_price_schedules (
+    id SERIAL PRIMARY KEY,
+    voucher_id INT NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    sale_price DECIMAL(10, 2) NOT NULL CHECK (sale_price >= 0),
+    stock_cap INT CHECK (stock_cap > 0),
+    sold_count INT NOT NULL DEFAULT 0 CHECK (sold_count >= 0),
+    is_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_schedule_dates CHECK (ends_at > starts_at),
+    CONSTRAINT chk_schedule_stock CHECK (stock_cap IS NULL OR sold_count <= stock_cap),
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create index for finding the active schedule of a voucher
+CREATE INDEX IF NOT EXISTS idx_price_schedules_voucher_window ON voucher_price_schedules(voucher_id, starts_at, ends_at);
@@ -0,0 +1,2 @@
+-- Drop voucher reservations table
+DROP TABLE IF EXISTS voucher_reservations CASCADE;
@@ -0,0 +1,17 @@
+-- Create voucher reservations table (expiring stock holds)
+CREATE TABLE IF NOT EXISTS voucher_reservations (
+    id SERIAL PRIMARY KEY,
+    voucher_id INT NOT NULL,
+    user_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'consumed', 'released', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create indexes for summing active holds and finding a user's hold
+CREATE INDEX IF NOT EXISTS idx_reservations_voucher_active ON voucher_reservations(voucher_id, status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_reservations_user_voucher ON voucher_reservations(user_id, voucher_id, status);
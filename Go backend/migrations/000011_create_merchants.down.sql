@@ -0,0 +1,11 @@
+-- Demote merchant users and restore the previous role constraint
+UPDATE users SET role = 'customer' WHERE role = 'merchant';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('customer', 'admin'));
+
+-- Remove merchant links
+ALTER TABLE users DROP COLUMN IF EXISTS merchant_id;
+ALTER TABLE vouchers DROP COLUMN IF EXISTS merchant_id;
+
+-- Drop merchants table
+DROP TABLE IF EXISTS merchants CASCADE;
@@ -0,0 +1,21 @@
+-- Create merchants table
+CREATE TABLE IF NOT EXISTS merchants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Link vouchers to their issuing merchant
+ALTER TABLE vouchers ADD COLUMN IF NOT EXISTS merchant_id INT REFERENCES merchants(id) ON DELETE SET NULL;
+
+-- Merchant users belong to a merchant
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merchant_id INT REFERENCES merchants(id) ON DELETE SET NULL;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('customer', 'admin', 'merchant'));
+
+-- Create indexes for merchant lookups
+CREATE INDEX IF NOT EXISTS idx_vouchers_merchant_id ON vouchers(merchant_id);
+CREATE INDEX IF NOT EXISTS idx_users_merchant_id ON users(merchant_id);
@@ -0,0 +1,4 @@
+-- Drop settlement tables and the immutability trigger function
+DROP TABLE IF EXISTS settlement_line_items CASCADE;
+DROP TABLE IF EXISTS settlement_batches CASCADE;
+DROP FUNCTION IF EXISTS prevent_closed_settlement_changes();
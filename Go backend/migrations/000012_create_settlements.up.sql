@@ -0,0 +1,72 @@
+-- Create settlement batches table (one merchant payout per period)
+CREATE TABLE IF NOT EXISTS settlement_batches (
+    id SERIAL PRIMARY KEY,
+    merchant_id INT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    commission_rate DECIMAL(5, 4) NOT NULL CHECK (commission_rate >= 0 AND commission_rate <= 1),
+    gross_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    refund_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    commission_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    net_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    created_by INT,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_settlement_period CHECK (period_end > period_start),
+    FOREIGN KEY (merchant_id) REFERENCES merchants(id),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Create settlement line items table (refunds are stored as negative amounts)
+CREATE TABLE IF NOT EXISTS settlement_line_items (
+    id SERIAL PRIMARY KEY,
+    batch_id INT NOT NULL,
+    transaction_id INT UNIQUE NOT NULL,
+    voucher_id INT NOT NULL,
+    voucher_name VARCHAR(255) NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL CHECK (transaction_type IN ('purchase', 'refund')),
+    amount DECIMAL(10, 2) NOT NULL,
+    commission DECIMAL(10, 2) NOT NULL,
+    net_amount DECIMAL(10, 2) NOT NULL,
+    transaction_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (batch_id) REFERENCES settlement_batches(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+-- A merchant has at most one open batch at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_settlement_batches_open ON settlement_batches(merchant_id) WHERE status = 'open';
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS idx_settlement_batches_merchant ON settlement_batches(merchant_id, period_end);
+CREATE INDEX IF NOT EXISTS idx_settlement_line_items_batch ON settlement_line_items(batch_id);
+
+-- Closed batches and their line items are immutable
+CREATE OR REPLACE FUNCTION prevent_closed_settlement_changes() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'settlement_batches' THEN
+        IF OLD.status = 'closed' THEN
+            RAISE EXCEPTION 'settlement batch % is closed', OLD.id;
+        END IF;
+    ELSIF EXISTS (SELECT 1 FROM settlement_batches WHERE id = OLD.batch_id AND status = 'closed') THEN
+        RAISE EXCEPTION 'settlement batch % is closed', OLD.batch_id;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_settlement_batches_immutable ON settlement_batches;
+CREATE TRIGGER trg_settlement_batches_immutable
+    BEFORE UPDATE OR DELETE ON settlement_batches
+    FOR EACH ROW EXECUTE FUNCTION prevent_closed_settlement_changes();
+
+DROP TRIGGER IF EXISTS trg_settlement_line_items_immutable ON settlement_line_items;
+CREATE TRIGGER trg_settlement_line_items_immutable
+    BEFORE UPDATE OR DELETE ON settlement_line_items
+    FOR EACH ROW EXECUTE FUNCTION prevent_closed_settlement_changes();
@@ -0,0 +1,2 @@
+-- Drop outbox table
+DROP TABLE IF EXISTS outbox CASCADE;
@@ -0,0 +1,21 @@
+-- Create outbox table: domain events written in the same transaction as the change they
+-- describe, delivered to the configured sinks by the server's outbox relay
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead_lettered')),
+    attempts INT NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    delivered_to TEXT[] NOT NULL DEFAULT '{}', -- Sinks that accepted the event
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP, -- Lease of the relay delivering the event; others skip it until then
+    processed_at TIMESTAMP, -- When the event was delivered or dead-lettered
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The relay delivers the oldest pending event of each aggregate first
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(aggregate_type, aggregate_id, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_outbox_processed ON outbox(processed_at) WHERE status = 'delivered';
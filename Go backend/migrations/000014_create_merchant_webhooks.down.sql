@@ -0,0 +1,3 @@
+-- Drop merchant webhook tables
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhook_endpoints CASCADE;
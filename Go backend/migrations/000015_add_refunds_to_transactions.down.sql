@@ -0,0 +1,3 @@
+-- Remove the refund link from transactions table
+DROP INDEX IF EXISTS idx_transactions_refund_of;
+ALTER TABLE transactions DROP COLUMN IF EXISTS original_transaction_id;
@@ -0,0 +1,5 @@
+-- Link refunds to the purchase they return
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS original_transaction_id INT REFERENCES transactions(id) ON DELETE CASCADE;
+
+-- A purchase is refunded at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_refund_of ON transactions(original_transaction_id) WHERE transaction_type = 'refund';
@@ -0,0 +1,2 @@
+-- Remove the redemption time from transactions table
+ALTER TABLE transactions DROP COLUMN IF EXISTS redeemed_at;
@@ -0,0 +1,2 @@
+-- Record when a purchased voucher was redeemed at the merchant
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS redeemed_at TIMESTAMP;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations so the server binary can apply them.
+// Files follow the NNNNNN_name.up.sql / NNNNNN_name.down.sql naming used by golang-migrate.
+package migrations
+
+import "embed"
+
+// FS holds every migration file
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,29 @@
+package ratelimit
+
+import "github.com/gin-gonic/gin"
+
+// GinMiddleware stores the client IP in the request context, so it can be forwarded to the
+// gRPC server, and aborts requests over their route budget ("POST /api/v1/auth/login").
+// The IP is gin's ClientIP, so the engine's trusted proxies decide whether X-Forwarded-For counts.
+// The ResourceExhausted error from ExhaustedError is recorded with c.Error for the
+// gateway's error middleware to render as 429 Too Many Requests with a Retry-After header.
+func GinMiddleware(limiter *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		ctx := WithClientIP(c.Request.Context(), ip)
+		c.Request = c.Request.WithContext(ctx)
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		if wait := limiter.Allow(ctx, c.Request.Method+" "+route, ip); wait > 0 {
+			_ = c.Error(ExhaustedError(ctx, wait, "too many requests, try again later"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// retryAfterHeader tells gRPC callers how many seconds to wait, like HTTP's Retry-After
+const retryAfterHeader = "retry-after"
+
+// Forwarders are the callers, such as the REST gateway, trusted to name the client they call
+// for in x-forwarded-for metadata. The zero value trusts no caller.
+type Forwarders struct {
+	Names []string       // Common or DNS names of verified client certificates (mutual TLS)
+	Addrs []netip.Prefix // Connection addresses, e.g. loopback for a gateway on the same host
+}
+
+// ParseAddrs parses a comma separated list of IPs and CIDRs, e.g. "127.0.0.1,10.0.0.0/8"
+func ParseAddrs(value string) ([]netip.Prefix, error) {
+	var addrs []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			addrs = append(addrs, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP or CIDR", entry)
+		}
+		addrs = append(addrs, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return addrs, nil
+}
+
+// UnaryServerInterceptor stores the caller's IP in the context and rejects calls over their
+// method budget ("/VoucherService/Login") with codes.ResourceExhausted. The IP comes from the
+// connection, or from the x-forwarded-for metadata when the caller is one of forwarders.
+func UnaryServerInterceptor(limiter *Limiter, forwarders Forwarders) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ip := grpcClientIP(ctx, forwarders)
+		ctx = WithClientIP(ctx, ip)
+
+		if wait := limiter.Allow(ctx, info.FullMethod, ip); wait > 0 {
+			return nil, ExhaustedError(ctx, wait, "rate limit exceeded, try again later")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies the method budgets of UnaryServerInterceptor to opening streams
+func StreamServerInterceptor(limiter *Limiter, forwarders Forwarders) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		ip := grpcClientIP(ctx, forwarders)
+		ctx = WithClientIP(ctx, ip)
+
+		if wait := limiter.Allow(ctx, info.FullMethod, ip); wait > 0 {
+			return ExhaustedError(ctx, wait, "rate limit exceeded, try again later")
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream replaces the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcClientIP returns the IP of the client the call is made for
+func grpcClientIP(ctx context.Context, forwarders Forwarders) string {
+	if forwarders.trusted(ctx) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(ForwardedForHeader); len(values) > 0 {
+				// The first entry is the original client, later ones are proxies
+				first, _, _ := strings.Cut(values[0], ",")
+				if ip := strings.TrimSpace(first); ip != "" {
+					return ip
+				}
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// trusted reports whether the caller connected from one of the forwarder addresses, or
+// presented a verified client certificate whose common name or a DNS name is a forwarder name
+func (f Forwarders) trusted(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	if len(f.Addrs) > 0 && p.Addr != nil {
+		if addrPort, err := netip.ParseAddrPort(p.Addr.String()); err == nil {
+			addr := addrPort.Addr().Unmap()
+			if slices.ContainsFunc(f.Addrs, func(prefix netip.Prefix) bool { return prefix.Contains(addr) }) {
+				return true
+			}
+		}
+	}
+
+	if len(f.Names) == 0 {
+		return false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return false
+	}
+
+	leaf := info.State.VerifiedChains[0][0]
+	return slices.Contains(f.Names, leaf.Subject.CommonName) ||
+		slices.ContainsFunc(leaf.DNSNames, func(name string) bool { return slices.Contains(f.Names, name) })
+}
+
+// UnaryClientInterceptor forwards the context's client IP to the server as x-forwarded-for metadata.
+// It replaces any x-forwarded-for already in the metadata, such as the one the REST gateway copies
+// from the request headers. The context's IP is the one GinMiddleware stored, which gin only takes
+// from X-Forwarded-For when the request came through a trusted proxy.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(forwardClientIP(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor forwards the context's client IP like UnaryClientInterceptor, for streams
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(forwardClientIP(ctx), desc, cc, method, opts...)
+	}
+}
+
+// forwardClientIP sets the context's client IP as the outgoing x-forwarded-for metadata
+func forwardClientIP(ctx context.Context) context.Context {
+	ip := ClientIPFromContext(ctx)
+	if ip == "" {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(ForwardedForHeader, ip)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// ExhaustedError returns a codes.ResourceExhausted error asking the caller to retry after wait,
+// given both as RetryInfo status details and a retry-after response header
+func ExhaustedError(ctx context.Context, wait time.Duration, msg string) error {
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, strconv.Itoa(RetryAfterSeconds(wait))))
+
+	st := status.New(codes.ResourceExhausted, msg)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RetryAfter returns the retry delay carried by an error from ExhaustedError
+func RetryAfter(st *status.Status) (time.Duration, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/netip"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGRPCClientIP(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 51234}
+	verified := func(cert *x509.Certificate) credentials.AuthInfo {
+		return credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	}
+	gateway := verified(&x509.Certificate{Subject: pkix.Name{CommonName: "voucher-gateway"}})
+	forwarders := Forwarders{Names: []string{"voucher-gateway"}}
+
+	tests := []struct {
+		name       string
+		authInfo   credentials.AuthInfo
+		noPeer     bool
+		forwarded  []string
+		addr       net.Addr
+		forwarders Forwarders
+		want       string
+	}{
+		{
+			name: "connection address",
+			want: "10.0.0.5",
+		},
+		{
+			name:       "forwarded header from plaintext caller is ignored",
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: forwarders,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "forwarded header from gateway certificate",
+			authInfo:   gateway,
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: forwarders,
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "first forwarded entry is the client",
+			authInfo:   gateway,
+			forwarded:  []string{" 203.0.113.9 , 198.51.100.1"},
+			forwarders: forwarders,
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "gateway without forwarded header",
+			authInfo:   gateway,
+			forwarders: forwarders,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "empty forwarded header",
+			authInfo:   gateway,
+			forwarded:  []string{" , 198.51.100.1"},
+			forwarders: forwarders,
+			want:       "10.0.0.5",
+		},
+		{
+			name:      "no forwarders trusts nobody",
+			authInfo:  gateway,
+			forwarded: []string{"203.0.113.9"},
+			want:      "10.0.0.5",
+		},
+		{
+			name:       "forwarder matched by DNS name",
+			authInfo:   verified(&x509.Certificate{Subject: pkix.Name{CommonName: "gw-1"}, DNSNames: []string{"localhost", "voucher-gateway"}}),
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: forwarders,
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "other client certificate",
+			authInfo:   verified(&x509.Certificate{Subject: pkix.Name{CommonName: "batch-job"}, DNSNames: []string{"localhost"}}),
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: forwarders,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "TLS without a verified client certificate",
+			authInfo:   credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "voucher-gateway"}}}}},
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: forwarders,
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "forwarded header from trusted address",
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: Forwarders{Addrs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "forwarded header from trusted IPv6 loopback",
+			addr:       &net.TCPAddr{IP: net.IPv6loopback, Port: 51234},
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: Forwarders{Addrs: []netip.Prefix{netip.MustParsePrefix("::1/128")}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "forwarded header from other address",
+			forwarded:  []string{"203.0.113.9"},
+			forwarders: Forwarders{Addrs: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}},
+			want:       "10.0.0.5",
+		},
+		{
+			name:   "no peer",
+			noPeer: true,
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if !tt.noPeer {
+				peerAddr := tt.addr
+				if peerAddr == nil {
+					peerAddr = addr
+				}
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: peerAddr, AuthInfo: tt.authInfo})
+			}
+			if tt.forwarded != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.MD{ForwardedForHeader: tt.forwarded})
+			}
+
+			if got := grpcClientIP(ctx, tt.forwarders); got != tt.want {
+				t.Errorf("grpcClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAddrs(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{name: "IPs become single-address prefixes", value: "127.0.0.1, ::1", want: []string{"127.0.0.1/32", "::1/128"}},
+		{name: "CIDRs are masked", value: "10.1.2.3/8", want: []string{"10.0.0.0/8"}},
+		{name: "not an address", value: "gateway", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddrs(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAddrs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseAddrs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].String() != tt.want[i] {
+					t.Errorf("ParseAddrs()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForwardClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientIP string
+		outgoing metadata.MD
+		want     []string
+	}{
+		{name: "no client IP", want: nil},
+		{name: "no client IP keeps existing header", outgoing: metadata.Pairs(ForwardedForHeader, "198.51.100.1"), want: []string{"198.51.100.1"}},
+		{name: "client IP", clientIP: "203.0.113.9", want: []string{"203.0.113.9"}},
+		{name: "client IP replaces copied header", clientIP: "203.0.113.9", outgoing: metadata.Pairs(ForwardedForHeader, "198.51.100.1"), want: []string{"203.0.113.9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.outgoing != nil {
+				ctx = metadata.NewOutgoingContext(ctx, tt.outgoing)
+			}
+			if tt.clientIP != "" {
+				ctx = WithClientIP(ctx, tt.clientIP)
+			}
+
+			md, _ := metadata.FromOutgoingContext(forwardClientIP(ctx))
+			got := md.Get(ForwardedForHeader)
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("x-forwarded-for = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
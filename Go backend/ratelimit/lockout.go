@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// LockoutPolicy decides when repeated failures lock a key and for how long
+type LockoutPolicy struct {
+	MaxFailures int           // Failures allowed before the first lock
+	Base        time.Duration // First lock; each further failure doubles it
+	Max         time.Duration // Longest lock
+	Window      time.Duration // Failures further apart than this start the count over
+}
+
+// Lockout locks keys (e.g. an email address or client IP) out after repeated failures,
+// with exponentially growing locks
+type Lockout struct {
+	store  Store
+	prefix string
+	policy LockoutPolicy
+}
+
+// NewLockout creates a lockout whose keys are stored under prefix
+func NewLockout(store Store, prefix string, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, prefix: prefix, policy: policy}
+}
+
+// Check returns how long key stays locked, 0 if it is not locked
+func (l *Lockout) Check(ctx context.Context, key string) (time.Duration, error) {
+	until, err := l.store.LockedUntil(ctx, l.prefix+key)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, time.Until(until)), nil
+}
+
+// Fail records a failure at key and returns how long it is now locked, 0 if not yet
+func (l *Lockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+	failures, err := l.store.AddFailure(ctx, l.prefix+key, now, l.policy.Window)
+	if err != nil || failures < l.policy.MaxFailures {
+		return 0, err
+	}
+
+	lock := l.policy.Base
+	for i := l.policy.MaxFailures; i < failures && lock < l.policy.Max; i++ {
+		lock *= 2
+	}
+	lock = min(lock, l.policy.Max)
+
+	return lock, l.store.Lock(ctx, l.prefix+key, now.Add(lock))
+}
+
+// Reset forgets the failures at key, e.g. after a successful attempt
+func (l *Lockout) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, l.prefix+key)
+}
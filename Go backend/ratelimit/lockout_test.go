@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLockoutFail(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy LockoutPolicy
+		want   []time.Duration // Lock returned by each consecutive failure
+	}{
+		{
+			name:   "locks after max failures and doubles up to max",
+			policy: LockoutPolicy{MaxFailures: 3, Base: time.Minute, Max: 5 * time.Minute, Window: time.Hour},
+			want:   []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute},
+		},
+		{
+			name:   "first failure locks",
+			policy: LockoutPolicy{MaxFailures: 1, Base: time.Second, Max: time.Hour, Window: time.Hour},
+			want:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:   "base above max is capped",
+			policy: LockoutPolicy{MaxFailures: 2, Base: time.Hour, Max: 30 * time.Minute, Window: time.Hour},
+			want:   []time.Duration{0, 30 * time.Minute, 30 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			lockout := NewLockout(NewMemoryStore(), "login:", tt.policy)
+
+			for i, want := range tt.want {
+				got, err := lockout.Fail(ctx, "user@example.com")
+				if err != nil {
+					t.Fatalf("failure %d: Fail() error = %v", i+1, err)
+				}
+				if got != want {
+					t.Errorf("failure %d: Fail() = %v, want %v", i+1, got, want)
+				}
+
+				locked, err := lockout.Check(ctx, "user@example.com")
+				if err != nil {
+					t.Fatalf("failure %d: Check() error = %v", i+1, err)
+				}
+				if (locked > 0) != (want > 0) || locked > want {
+					t.Errorf("failure %d: Check() = %v, want up to %v", i+1, locked, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLockoutKeys(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	policy := LockoutPolicy{MaxFailures: 1, Base: time.Minute, Max: time.Hour, Window: time.Hour}
+	emails := NewLockout(store, "login:email:", policy)
+	ips := NewLockout(store, "login:ip:", policy)
+
+	if _, err := emails.Fail(ctx, "10.0.0.1"); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		lockout *Lockout
+		key     string
+		locked  bool
+	}{
+		{name: "failed key", lockout: emails, key: "10.0.0.1", locked: true},
+		{name: "other key", lockout: emails, key: "10.0.0.2", locked: false},
+		{name: "same key under another prefix", lockout: ips, key: "10.0.0.1", locked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, err := tt.lockout.Check(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if (wait > 0) != tt.locked {
+				t.Errorf("Check(%q) = %v, want locked %v", tt.key, wait, tt.locked)
+			}
+		})
+	}
+
+	if err := emails.Reset(ctx, "10.0.0.1"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if wait, _ := emails.Check(ctx, "10.0.0.1"); wait != 0 {
+		t.Errorf("Check() after Reset = %v, want 0", wait)
+	}
+}
+
+func TestMemoryStoreAddFailure(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+
+	tests := []struct {
+		name string
+		at   []time.Duration // Failure times since start
+		want int             // Count after the last failure
+	}{
+		{name: "first failure", at: []time.Duration{0}, want: 1},
+		{name: "within window", at: []time.Duration{0, 5 * time.Minute, 10 * time.Minute}, want: 3},
+		{name: "window is measured from the last failure", at: []time.Duration{0, 9 * time.Minute, 18 * time.Minute, 27 * time.Minute}, want: 4},
+		{name: "gap longer than window starts over", at: []time.Duration{0, time.Minute, 12 * time.Minute}, want: 1},
+		{name: "counts again after starting over", at: []time.Duration{0, 11 * time.Minute, 12 * time.Minute}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			var got int
+			for _, at := range tt.at {
+				var err error
+				if got, err = store.AddFailure(context.Background(), "key", start.Add(at), window); err != nil {
+					t.Fatalf("AddFailure() error = %v", err)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("AddFailure() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
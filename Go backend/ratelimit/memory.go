@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps limiter and lockout state in process
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	attempts map[string]*attempts
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+type attempts struct {
+	failures    int
+	lastFailure time.Time
+	window      time.Duration
+	lockedUntil time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		attempts: make(map[string]*attempts),
+	}
+}
+
+// Take removes a token from the bucket at key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+	return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), nil
+}
+
+// refill adds the tokens earned since the last request
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
+
+// AddFailure records a failed attempt at key
+func (s *MemoryStore) AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &attempts{}
+		s.attempts[key] = a
+	}
+	if now.Sub(a.lastFailure) > window {
+		a.failures = 0
+	}
+	a.failures++
+	a.lastFailure = now
+	a.window = window
+	return a.failures, nil
+}
+
+// Lock blocks key until the given time
+func (s *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &attempts{}
+		s.attempts[key] = a
+	}
+	a.lockedUntil = until
+	return nil
+}
+
+// LockedUntil returns when the lock on key ends
+func (s *MemoryStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a, ok := s.attempts[key]; ok {
+		return a.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+// Reset forgets the failures and lock at key
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// StartJanitor drops full buckets and expired attempts every interval until ctx is cancelled,
+// so clients that went away don't use memory forever
+func (s *MemoryStore) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.cleanup(now)
+			}
+		}
+	}()
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, a := range s.attempts {
+		if now.After(a.lockedUntil) && now.Sub(a.lastFailure) > a.window {
+			delete(s.attempts, key)
+		}
+	}
+}
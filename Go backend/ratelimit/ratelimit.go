@@ -0,0 +1,130 @@
+// Package ratelimit provides token bucket rate limiting and failed-attempt lockouts shared by
+// the gRPC server and the REST gateway. State lives in a Store: MemoryStore keeps it in process,
+// which is enough for a single instance; a shared Store lets several instances enforce one budget.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ForwardedForHeader carries the original client IP from the gateway to the gRPC server
+const ForwardedForHeader = "x-forwarded-for"
+
+// Limit is a token bucket budget: Burst requests at once, refilled at Rate per second.
+// A Rate of zero or less means unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimits parses a comma separated list of route=rate:burst pairs, e.g.
+// "/VoucherService/Login=1:10,POST /api/v1/vouchers/buy=2:10"
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not a route=rate:burst pair", pair)
+		}
+		rate, burst, ok := strings.Cut(pair[i+1:], ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not a route=rate:burst pair", pair)
+		}
+
+		limit := Limit{}
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(strings.TrimSpace(rate), 64); err != nil || limit.Rate < 0 {
+			return nil, fmt.Errorf("invalid rate in %q", pair)
+		}
+		if limit.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || limit.Burst < 1 {
+			return nil, fmt.Errorf("invalid burst in %q", pair)
+		}
+		limits[strings.TrimSpace(pair[:i])] = limit
+	}
+	return limits, nil
+}
+
+// Store holds limiter and lockout state
+type Store interface {
+	// Take removes a token from the bucket at key. It returns 0 if a token was available,
+	// otherwise how long until one will be.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error)
+
+	// AddFailure records a failed attempt at key and returns the number of failures, counting
+	// only those no more than window apart
+	AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+
+	// Lock blocks key until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// LockedUntil returns when the lock on key ends; the zero time if it is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+
+	// Reset forgets the failures and lock at key
+	Reset(ctx context.Context, key string) error
+}
+
+// Limiter applies token bucket budgets per route and client.
+// A nil *Limiter allows everything.
+type Limiter struct {
+	store  Store
+	def    Limit
+	routes map[string]Limit
+}
+
+// NewLimiter creates a limiter with a budget for each listed route and def for all others
+func NewLimiter(store Store, def Limit, routes map[string]Limit) *Limiter {
+	return &Limiter{store: store, def: def, routes: routes}
+}
+
+// Allow takes a request of client to route from its budget. It returns 0 if the request may
+// proceed, otherwise how long the client should wait. Store errors let the request through.
+func (l *Limiter) Allow(ctx context.Context, route, client string) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	limit, ok := l.routes[route]
+	if !ok {
+		limit = l.def
+	}
+	if limit.Rate <= 0 {
+		return 0
+	}
+
+	wait, err := l.store.Take(ctx, "rate:"+route+"|"+client, limit, time.Now())
+	if err != nil {
+		slog.Warn("Rate limit store failed, allowing request", "route", route, "error", err)
+		return 0
+	}
+	return wait
+}
+
+// RetryAfterSeconds rounds a wait up to whole seconds for Retry-After headers
+func RetryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
+
+type clientIPKey struct{}
+
+// WithClientIP stores the caller's IP address in ctx
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the caller's IP address stored in ctx, or ""
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
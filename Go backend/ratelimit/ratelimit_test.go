@@ -0,0 +1,231 @@
+package ratelimit
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]Limit
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  map[string]Limit{},
+		},
+		{
+			name:  "grpc and http routes",
+			value: "/VoucherService/Login=1:10, POST /api/v1/vouchers/buy = 2.5 : 10 ,",
+			want: map[string]Limit{
+				"/VoucherService/Login":     {Rate: 1, Burst: 10},
+				"POST /api/v1/vouchers/buy": {Rate: 2.5, Burst: 10},
+			},
+		},
+		{
+			name:  "zero rate is unlimited",
+			value: "/VoucherService/GetVouchers=0:1",
+			want:  map[string]Limit{"/VoucherService/GetVouchers": {Rate: 0, Burst: 1}},
+		},
+		{
+			name:  "route containing equals sign",
+			value: "GET /api/v1/vouchers?sort=price=1:5",
+			want:  map[string]Limit{"GET /api/v1/vouchers?sort=price": {Rate: 1, Burst: 5}},
+		},
+		{name: "missing route", value: "=1:10", wantErr: true},
+		{name: "missing equals sign", value: "/VoucherService/Login", wantErr: true},
+		{name: "missing burst", value: "/VoucherService/Login=1", wantErr: true},
+		{name: "negative rate", value: "/VoucherService/Login=-1:10", wantErr: true},
+		{name: "rate not a number", value: "/VoucherService/Login=fast:10", wantErr: true},
+		{name: "zero burst", value: "/VoucherService/Login=1:0", wantErr: true},
+		{name: "fractional burst", value: "/VoucherService/Login=1:1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimits(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLimits(%q) = %v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLimits(%q) error = %v", tt.value, err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseLimits(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type take struct {
+		key  string
+		at   time.Duration // Since start
+		want time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		limit Limit
+		takes []take
+	}{
+		{
+			name:  "burst then wait for refill",
+			limit: Limit{Rate: 1, Burst: 2},
+			takes: []take{
+				{"a", 0, 0},
+				{"a", 0, 0},
+				{"a", 0, time.Second},
+				{"a", 500 * time.Millisecond, 500 * time.Millisecond},
+				{"a", time.Second, 0},
+				{"a", time.Second, time.Second},
+			},
+		},
+		{
+			name:  "refill is capped at burst",
+			limit: Limit{Rate: 1, Burst: 2},
+			takes: []take{
+				{"a", 0, 0},
+				{"a", 0, 0},
+				{"a", time.Hour, 0},
+				{"a", time.Hour, 0},
+				{"a", time.Hour, time.Second},
+			},
+		},
+		{
+			name:  "fractional rate",
+			limit: Limit{Rate: 0.5, Burst: 1},
+			takes: []take{
+				{"a", 0, 0},
+				{"a", 0, 2 * time.Second},
+				{"a", time.Second, time.Second},
+				{"a", 2 * time.Second, 0},
+			},
+		},
+		{
+			name:  "keys have separate buckets",
+			limit: Limit{Rate: 1, Burst: 1},
+			takes: []take{
+				{"a", 0, 0},
+				{"a", 0, time.Second},
+				{"b", 0, 0},
+				{"b", 0, time.Second},
+			},
+		},
+		{
+			name:  "waiting does not take a token",
+			limit: Limit{Rate: 1, Burst: 1},
+			takes: []take{
+				{"a", 0, 0},
+				{"a", 0, time.Second},
+				{"a", 0, time.Second},
+				{"a", time.Second, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			for i, take := range tt.takes {
+				got, err := store.Take(context.Background(), take.key, tt.limit, start.Add(take.at))
+				if err != nil {
+					t.Fatalf("take %d: Take() error = %v", i, err)
+				}
+				if got != take.want {
+					t.Errorf("take %d: Take(%q, +%v) = %v, want %v", i, take.key, take.at, got, take.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.Take(ctx, "refilled", Limit{Rate: 1, Burst: 2}, start)
+	store.Take(ctx, "draining", Limit{Rate: 0.01, Burst: 2}, start)
+	store.Take(ctx, "draining", Limit{Rate: 0.01, Burst: 2}, start)
+	store.AddFailure(ctx, "stale", start, time.Minute)
+	store.AddFailure(ctx, "recent", start.Add(time.Minute), time.Minute)
+	store.Lock(ctx, "locked", start.Add(time.Hour))
+
+	store.cleanup(start.Add(90 * time.Second))
+
+	for key, want := range map[string]bool{"refilled": false, "draining": true} {
+		if _, ok := store.buckets[key]; ok != want {
+			t.Errorf("bucket %q kept = %v, want %v", key, ok, want)
+		}
+	}
+	for key, want := range map[string]bool{"stale": false, "recent": true, "locked": true} {
+		if _, ok := store.attempts[key]; ok != want {
+			t.Errorf("attempts %q kept = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), Limit{Rate: 1, Burst: 2}, map[string]Limit{
+		"/VoucherService/Login":       {Rate: 1, Burst: 1},
+		"/VoucherService/GetVouchers": {Rate: 0, Burst: 1},
+	})
+
+	tests := []struct {
+		name    string
+		limiter *Limiter
+		route   string
+		client  string
+		allowed bool
+	}{
+		{name: "nil limiter", limiter: nil, route: "/VoucherService/Login", client: "10.0.0.1", allowed: true},
+		{name: "nil limiter again", limiter: nil, route: "/VoucherService/Login", client: "10.0.0.1", allowed: true},
+		{name: "route budget", limiter: limiter, route: "/VoucherService/Login", client: "10.0.0.1", allowed: true},
+		{name: "route budget spent", limiter: limiter, route: "/VoucherService/Login", client: "10.0.0.1", allowed: false},
+		{name: "other client", limiter: limiter, route: "/VoucherService/Login", client: "10.0.0.2", allowed: true},
+		{name: "default budget", limiter: limiter, route: "/VoucherService/BuyVoucher", client: "10.0.0.1", allowed: true},
+		{name: "default budget burst", limiter: limiter, route: "/VoucherService/BuyVoucher", client: "10.0.0.1", allowed: true},
+		{name: "default budget spent", limiter: limiter, route: "/VoucherService/BuyVoucher", client: "10.0.0.1", allowed: false},
+		{name: "default budget is per route", limiter: limiter, route: "/VoucherService/GetWallet", client: "10.0.0.1", allowed: true},
+		{name: "unlimited route", limiter: limiter, route: "/VoucherService/GetVouchers", client: "10.0.0.1", allowed: true},
+		{name: "unlimited route again", limiter: limiter, route: "/VoucherService/GetVouchers", client: "10.0.0.1", allowed: true},
+	}
+
+	// The cases share the limiter's buckets, so they run in order
+	for _, tt := range tests {
+		wait := tt.limiter.Allow(context.Background(), tt.route, tt.client)
+		if allowed := wait == 0; allowed != tt.allowed {
+			t.Errorf("%s: Allow(%q, %q) = %v, want allowed %v", tt.name, tt.route, tt.client, wait, tt.allowed)
+		}
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{time.Minute, 60},
+	}
+
+	for _, tt := range tests {
+		if got := RetryAfterSeconds(tt.wait); got != tt.want {
+			t.Errorf("RetryAfterSeconds(%v) = %d, want %d", tt.wait, got, tt.want)
+		}
+	}
+}
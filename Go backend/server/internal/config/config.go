@@ -1,75 +1,432 @@
 package config
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/certs"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/conf"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/outbox"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 	_ "github.com/lib/pq"
 )
 
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory" // Data is lost on restart; for demos and end-to-end tests
+)
+
+// SSL modes supported by the postgres driver
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// Backoff between attempts to reach the database at startup
+const (
+	connectInitialBackoff = 500 * time.Millisecond
+	connectMaxBackoff     = 5 * time.Second
+)
+
+// Config is the gRPC server configuration. See package conf for how values are layered;
+// run the server with --print-config to see the effective values.
+type Config struct {
+	Storage     string           `conf:"storage" env:"STORAGE_BACKEND" default:"postgres" usage:"storage backend: postgres or memory"`
+	AutoMigrate bool             `conf:"auto_migrate" usage:"apply pending database migrations on startup instead of refusing to start"`
+	DB          DBConfig         `conf:"db"`
+	GRPC        GRPCConfig       `conf:"grpc"`
+	Metrics     MetricsConfig    `conf:"metrics"`
+	Payments    PaymentsConfig   `conf:"payments"`
+	Settlement  SettlementConfig `conf:"settlement"`
+	Health      HealthConfig     `conf:"health"`
+	RateLimit   RateLimitConfig  `conf:"rate_limit"`
+	Login       LoginConfig      `conf:"login"`
+	Outbox      OutboxConfig     `conf:"outbox"`
+	Webhooks    WebhooksConfig   `conf:"merchant_webhooks"`
+	Log         logging.Settings `conf:"log"`
+	Tracing     tracing.Settings `conf:"tracing"`
+}
+
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
+	Host     string `conf:"host" default:"localhost" usage:"database host"`
+	Port     string `conf:"port" default:"5432" usage:"database port"`
+	User     string `conf:"user" default:"postgres" usage:"database user"`
+	Password string `conf:"password" secret:"true" usage:"database password"`
+	DBName   string `conf:"name" default:"capstone_voucher" usage:"database name"`
+
+	SSLMode     string `conf:"sslmode" default:"disable" usage:"SSL mode: disable, require, verify-ca or verify-full"`
+	SSLRootCert string `conf:"sslrootcert" usage:"CA certificate file used to verify the server (verify-ca, verify-full)"`
+	SSLCert     string `conf:"sslcert" usage:"client certificate file"`
+	SSLKey      string `conf:"sslkey" usage:"client private key file"`
+
+	MaxOpenConns        int           `conf:"max_open_conns" default:"25" usage:"maximum open connections in the pool"`
+	MaxIdleConns        int           `conf:"max_idle_conns" default:"10" usage:"maximum idle connections kept in the pool"`
+	ConnMaxLifetime     time.Duration `conf:"conn_max_lifetime" default:"30m" usage:"close connections older than this (0 keeps them forever)"`
+	ConnMaxIdleTime     time.Duration `conf:"conn_max_idle_time" default:"5m" usage:"close connections idle for longer than this (0 keeps them forever)"`
+	StatementTimeout    time.Duration `conf:"statement_timeout" default:"10s" usage:"abort statements running longer than this (0 disables)"`
+	ConnectTimeout      time.Duration `conf:"connect_timeout" default:"5s" usage:"timeout of each connection attempt and health check"`
+	ConnectRetryTimeout time.Duration `conf:"connect_retry_timeout" default:"30s" usage:"keep retrying the initial connection for this long (0 tries once)"`
+	HealthCheckInterval time.Duration `conf:"health_check_interval" default:"10s" usage:"how often readiness pings the database"`
+}
+
+type GRPCConfig struct {
+	Addr      string               `conf:"addr" default:":50051" usage:"gRPC listen address"`
+	Keepalive KeepaliveConfig      `conf:"keepalive"`
+	TLS       certs.ServerSettings `conf:"tls"`
+}
+
+type KeepaliveConfig struct {
+	MinTime time.Duration `conf:"min_time" default:"10s" usage:"shortest keepalive ping interval allowed from clients; more frequent pings close the connection"`
+}
+
+type MetricsConfig struct {
+	Addr string `conf:"addr" default:":9091" usage:"Prometheus metrics listen address"`
+}
+
+type PaymentsConfig struct {
+	MockUPISuccessRate float64 `conf:"mock_upi_success_rate" env:"MOCK_UPI_SUCCESS_RATE" default:"0.95" usage:"share of mock UPI payments that succeed, between 0 and 1"`
+}
+
+type SettlementConfig struct {
+	CommissionRate float64 `conf:"commission_rate" env:"SETTLEMENT_COMMISSION_RATE" default:"0.10" usage:"platform share of each merchant sale kept at settlement, at least 0 and below 1"`
+}
+
+type HealthConfig struct {
+	CheckInterval time.Duration `conf:"check_interval" default:"5s" usage:"how often dependency health is published on grpc.health.v1"`
+	CheckTimeout  time.Duration `conf:"check_timeout" default:"2s" usage:"timeout of each dependency health check"`
 }
 
-// LoadConfig loads environment variables from .env file
-func LoadConfig() (*DBConfig, error) {
-	// Load .env file
-	err := godotenv.Load(".env")
+type RateLimitConfig struct {
+	Enabled           bool    `conf:"enabled" default:"true" usage:"limit how fast each client may call each method"`
+	Rate              float64 `conf:"rate" default:"20" usage:"requests per second per client for methods without their own budget"`
+	Burst             int     `conf:"burst" default:"40" usage:"requests a client may make at once for methods without their own budget"`
+	Methods           string  `conf:"methods" default:"/VoucherService/Login=1:10,/VoucherService/BuyVoucher=2:10" usage:"per method budgets as method=rate:burst pairs"`
+	ForwarderAddrs    string  `conf:"forwarder_addrs" default:"127.0.0.1,::1" usage:"comma-separated IPs or CIDRs of callers trusted to set x-forwarded-for, such as a gateway on the same host (empty trusts none)"`
+	TrustForwardedFor bool    `conf:"trust_forwarded_for" usage:"also take the client IP from x-forwarded-for metadata set by callers with a client certificate named in forwarders (needs mutual TLS)"`
+	Forwarders        string  `conf:"forwarders" default:"voucher-gateway" usage:"comma-separated client certificate names (common or DNS name) trusted to set x-forwarded-for"`
+}
+
+// TrustedForwarders returns the callers whose x-forwarded-for is believed: those connecting
+// from forwarder_addrs and, with trust_forwarded_for, those presenting a named certificate
+func (c *RateLimitConfig) TrustedForwarders() (ratelimit.Forwarders, error) {
+	addrs, err := ratelimit.ParseAddrs(c.ForwarderAddrs)
 	if err != nil {
-		return nil, fmt.Errorf("error loading .env file: %w", err)
+		return ratelimit.Forwarders{}, err
+	}
+	forwarders := ratelimit.Forwarders{Addrs: addrs}
+	if c.TrustForwardedFor {
+		for _, name := range strings.Split(c.Forwarders, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				forwarders.Names = append(forwarders.Names, name)
+			}
+		}
+	}
+	return forwarders, nil
+}
+
+// Limits returns the default and per-method budgets
+func (c *RateLimitConfig) Limits() (ratelimit.Limit, map[string]ratelimit.Limit, error) {
+	methods, err := ratelimit.ParseLimits(c.Methods)
+	return ratelimit.Limit{Rate: c.Rate, Burst: c.Burst}, methods, err
+}
+
+type LoginConfig struct {
+	MaxFailuresPerEmail int           `conf:"max_failures_per_email" default:"5" usage:"failed logins for one email before it is locked out"`
+	MaxFailuresPerIP    int           `conf:"max_failures_per_ip" default:"20" usage:"failed logins from one client IP before it is locked out"`
+	LockoutBase         time.Duration `conf:"lockout_base" default:"30s" usage:"first lockout; doubles with every further failure"`
+	LockoutMax          time.Duration `conf:"lockout_max" default:"15m" usage:"longest lockout"`
+	FailureWindow       time.Duration `conf:"failure_window" default:"15m" usage:"failures further apart than this start the count over"`
+}
+
+// Policies returns the lockout policies for emails and client IPs
+func (c *LoginConfig) Policies() (byEmail, byIP ratelimit.LockoutPolicy) {
+	policy := ratelimit.LockoutPolicy{Base: c.LockoutBase, Max: c.LockoutMax, Window: c.FailureWindow}
+	byEmail, byIP = policy, policy
+	byEmail.MaxFailures = c.MaxFailuresPerEmail
+	byIP.MaxFailures = c.MaxFailuresPerIP
+	return byEmail, byIP
+}
+
+type OutboxConfig struct {
+	WebhookURL      string        `conf:"webhook_url" usage:"POST every domain event as JSON to this URL (empty disables the webhook sink)"`
+	File            string        `conf:"file" usage:"append every domain event as a JSON line to this file (empty disables the file sink)"`
+	PollInterval    time.Duration `conf:"poll_interval" default:"1s" usage:"how often the relay looks for events to deliver"`
+	BatchSize       int           `conf:"batch_size" default:"100" usage:"events the relay fetches at a time"`
+	MaxAttempts     int           `conf:"max_attempts" default:"10" usage:"delivery attempts before an event is dead-lettered"`
+	RetryBase       time.Duration `conf:"retry_base" default:"1s" usage:"delay before retrying a failed delivery; doubles with every attempt"`
+	RetryMax        time.Duration `conf:"retry_max" default:"5m" usage:"longest delay between delivery attempts"`
+	DeliveryTimeout time.Duration `conf:"delivery_timeout" default:"10s" usage:"timeout of each delivery to a sink"`
+	Retention       time.Duration `conf:"retention" default:"168h" usage:"delete delivered events after this long; dead-lettered events are kept"`
+}
+
+// Settings returns the relay settings
+func (c *OutboxConfig) Settings() outbox.Settings {
+	return outbox.Settings{
+		PollInterval:    c.PollInterval,
+		BatchSize:       c.BatchSize,
+		MaxAttempts:     c.MaxAttempts,
+		RetryBase:       c.RetryBase,
+		RetryMax:        c.RetryMax,
+		DeliveryTimeout: c.DeliveryTimeout,
+		Retention:       c.Retention,
+	}
+}
+
+type WebhooksConfig struct {
+	PollInterval time.Duration `conf:"poll_interval" default:"1s" usage:"how often due merchant webhook deliveries are sent"`
+	BatchSize    int           `conf:"batch_size" default:"50" usage:"merchant webhook deliveries sent per poll"`
+	Timeout      time.Duration `conf:"timeout" default:"10s" usage:"timeout of each merchant webhook request"`
+	MaxAttempts  int           `conf:"max_attempts" default:"8" usage:"attempts before a merchant webhook delivery fails and has to be replayed"`
+	RetryBase    time.Duration `conf:"retry_base" default:"10s" usage:"delay before retrying a failed merchant webhook delivery; doubles with every attempt"`
+	RetryMax     time.Duration `conf:"retry_max" default:"1h" usage:"longest delay between merchant webhook delivery attempts"`
+	DisableAfter int           `conf:"disable_after" default:"20" usage:"consecutive failed attempts before a merchant webhook endpoint is disabled"`
+}
+
+// Settings returns the settings of the merchant webhook service
+func (c *WebhooksConfig) Settings() service.WebhookSettings {
+	return service.WebhookSettings{
+		Timeout:      c.Timeout,
+		MaxAttempts:  c.MaxAttempts,
+		RetryBase:    c.RetryBase,
+		RetryMax:     c.RetryMax,
+		DisableAfter: c.DisableAfter,
+		BatchSize:    c.BatchSize,
+	}
+}
+
+// LoadConfig loads the configuration from defaults, the optional config file,
+// the environment (and .env file, if present) and the command line args
+func LoadConfig(args []string) (*Config, conf.Options, error) {
+	config := &Config{}
+	opts, err := conf.Load(config, "server", args)
+	if err != nil {
+		return nil, opts, err
+	}
+	return config, opts, nil
+}
+
+// Validate checks every setting and reports all problems at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Storage != StoragePostgres && c.Storage != StorageMemory {
+		errs = append(errs, fmt.Errorf("invalid storage %q: must be %s or %s", c.Storage, StoragePostgres, StorageMemory))
+	}
+	if c.Storage == StoragePostgres {
+		errs = append(errs, c.DB.validate()...)
+	}
+	if err := conf.CheckAddr(c.GRPC.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("grpc: %w", err))
+	}
+	if c.GRPC.Keepalive.MinTime <= 0 {
+		errs = append(errs, fmt.Errorf("grpc keepalive min_time %s must be positive", c.GRPC.Keepalive.MinTime))
+	}
+	if err := c.GRPC.TLS.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("grpc: %w", err))
+	}
+	if err := conf.CheckAddr(c.Metrics.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if c.Payments.MockUPISuccessRate < 0 || c.Payments.MockUPISuccessRate > 1 {
+		errs = append(errs, fmt.Errorf("invalid mock UPI success rate %v: must be between 0 and 1", c.Payments.MockUPISuccessRate))
+	}
+	if c.Settlement.CommissionRate < 0 || c.Settlement.CommissionRate >= 1 {
+		errs = append(errs, fmt.Errorf("invalid settlement commission rate %v: must be at least 0 and below 1", c.Settlement.CommissionRate))
+	}
+	if c.Health.CheckInterval <= 0 || c.Health.CheckTimeout <= 0 {
+		errs = append(errs, errors.New("health check_interval and check_timeout must be positive"))
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Rate <= 0 || c.RateLimit.Burst < 1 {
+			errs = append(errs, errors.New("rate_limit rate must be positive and burst at least 1"))
+		}
+		if _, _, err := c.RateLimit.Limits(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid rate_limit methods: %w", err))
+		}
+	}
+	// Only a caller that proved its identity may speak for other clients
+	if forwarders, err := c.RateLimit.TrustedForwarders(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid rate_limit forwarder_addrs: %w", err))
+	} else if c.RateLimit.TrustForwardedFor {
+		if c.GRPC.TLS.ClientCAFile == "" {
+			errs = append(errs, errors.New("rate_limit trust_forwarded_for needs mutual TLS (grpc tls client_ca_file)"))
+		}
+		if len(forwarders.Names) == 0 {
+			errs = append(errs, errors.New("rate_limit trust_forwarded_for needs at least one forwarder"))
+		}
+	}
+	if c.Login.MaxFailuresPerEmail < 1 || c.Login.MaxFailuresPerIP < 1 {
+		errs = append(errs, errors.New("login max_failures_per_email and max_failures_per_ip must be at least 1"))
 	}
+	if c.Login.LockoutBase <= 0 || c.Login.LockoutMax < c.Login.LockoutBase || c.Login.FailureWindow <= 0 {
+		errs = append(errs, errors.New("login lockout_base and failure_window must be positive and lockout_max at least lockout_base"))
+	}
+	errs = append(errs, c.Outbox.validate()...)
+	errs = append(errs, c.Webhooks.validate()...)
+	if _, err := c.Log.Config(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.Tracing.ParseExporter(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the database settings
+func (c *DBConfig) validate() []error {
+	var errs []error
 
-	config := &DBConfig{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", ""),
-		DBName:   getEnv("DB_NAME", "capstone_voucher"),
+	if c.Host == "" || c.User == "" || c.DBName == "" {
+		errs = append(errs, errors.New("db host, user and name are required for postgres storage"))
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid db port %q", c.Port))
+	}
+	if !slices.Contains(sslModes, c.SSLMode) {
+		errs = append(errs, fmt.Errorf("invalid db sslmode %q: must be one of %s", c.SSLMode, strings.Join(sslModes, ", ")))
+	}
+	if (c.SSLCert == "") != (c.SSLKey == "") {
+		errs = append(errs, errors.New("db sslcert and sslkey must be set together"))
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("db max_open_conns and max_idle_conns must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("db max_idle_conns (%d) must not exceed max_open_conns (%d)", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	if c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 || c.StatementTimeout < 0 || c.ConnectRetryTimeout < 0 {
+		errs = append(errs, errors.New("db durations must not be negative"))
+	}
+	if c.ConnectTimeout < time.Second {
+		errs = append(errs, fmt.Errorf("db connect_timeout %s must be at least 1s", c.ConnectTimeout))
+	}
+	if c.HealthCheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("db health_check_interval %s must be positive", c.HealthCheckInterval))
+	}
+
+	return errs
+}
+
+// validate checks the outbox relay settings
+func (c *OutboxConfig) validate() []error {
+	var errs []error
+
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid outbox webhook_url %q: must be an http or https URL", c.WebhookURL))
+		}
+	}
+	if c.PollInterval <= 0 || c.DeliveryTimeout <= 0 || c.Retention <= 0 {
+		errs = append(errs, errors.New("outbox poll_interval, delivery_timeout and retention must be positive"))
+	}
+	if c.BatchSize < 1 || c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("outbox batch_size and max_attempts must be at least 1"))
+	}
+	if c.RetryBase <= 0 || c.RetryMax < c.RetryBase {
+		errs = append(errs, errors.New("outbox retry_base must be positive and retry_max at least retry_base"))
 	}
 
-	return config, nil
+	return errs
 }
 
 // GetConnectionString returns PostgreSQL connection string
 func (c *DBConfig) GetConnectionString() string {
-	// Use URL format for better handling of empty passwords
-	if c.Password == "" {
-		return fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=disable",
-			c.User, c.Host, c.Port, c.DBName)
+	// URL format escapes special characters in the user and password
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.User(c.User),
+		Host:   net.JoinHostPort(c.Host, c.Port),
+		Path:   "/" + c.DBName,
+	}
+	if c.Password != "" {
+		dsn.User = url.UserPassword(c.User, c.Password)
 	}
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		c.User, c.Password, c.Host, c.Port, c.DBName)
+
+	query := url.Values{}
+	query.Set("sslmode", c.SSLMode)
+	for key, value := range map[string]string{"sslrootcert": c.SSLRootCert, "sslcert": c.SSLCert, "sslkey": c.SSLKey} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	query.Set("connect_timeout", strconv.Itoa(int(c.ConnectTimeout/time.Second)))
+	if c.StatementTimeout > 0 {
+		// Unknown parameters are sent to the server as session settings
+		query.Set("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
+	dsn.RawQuery = query.Encode()
+
+	return dsn.String()
 }
 
-// ConnectDB creates and returns a database connection
-func ConnectDB(cfg *DBConfig) (*sql.DB, error) {
-	connectionString := cfg.GetConnectionString()
-	
-	db, err := sql.Open("postgres", connectionString)
+// ConnectDB opens a tuned connection pool and waits for the database to accept connections,
+// retrying with exponential backoff so a database that is still starting up does not stop the server
+func ConnectDB(ctx context.Context, cfg *DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.GetConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
 	// Test the connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	deadline := time.Now().Add(cfg.ConnectRetryTimeout)
+	backoff := connectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = PingDB(ctx, db, cfg.ConnectTimeout)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			db.Close()
+			return nil, fmt.Errorf("failed to ping database after %d attempts: %w", attempt, err)
+		}
+
+		slog.Warn("Database not available, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, connectMaxBackoff)
 	}
+}
 
-	return db, nil
+// PingDB checks that the database answers within timeout
+func PingDB(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return db.PingContext(ctx)
 }
 
-// getEnv gets environment variable or returns default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// validate checks the merchant webhook settings
+func (c *WebhooksConfig) validate() []error {
+	var errs []error
+
+	if c.PollInterval <= 0 || c.Timeout <= 0 {
+		errs = append(errs, errors.New("merchant_webhooks poll_interval and timeout must be positive"))
+	}
+	if c.BatchSize < 1 || c.MaxAttempts < 1 || c.DisableAfter < 1 {
+		errs = append(errs, errors.New("merchant_webhooks batch_size, max_attempts and disable_after must be at least 1"))
+	}
+	if c.RetryBase <= 0 || c.RetryMax < c.RetryBase {
+		errs = append(errs, errors.New("merchant_webhooks retry_base must be positive and retry_max at least retry_base"))
 	}
-	return value
-}
 
+	return errs
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const loginMethod = "/VoucherService/Login"
+
+// TestDefaultConfigSeparatesGatewayClients sends login traffic the way the REST gateway does
+// under the default config: over plaintext from the same host, naming each client in
+// x-forwarded-for. Every client must get its own budget and lockout.
+func TestDefaultConfigSeparatesGatewayClients(t *testing.T) {
+	cfg, _, err := LoadConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	store := ratelimit.NewMemoryStore()
+	defaultLimit, methodLimits, err := cfg.RateLimit.Limits()
+	if err != nil {
+		t.Fatalf("Limits() error = %v", err)
+	}
+	forwarders, err := cfg.RateLimit.TrustedForwarders()
+	if err != nil {
+		t.Fatalf("TrustedForwarders() error = %v", err)
+	}
+	interceptor := ratelimit.UnaryServerInterceptor(ratelimit.NewLimiter(store, defaultLimit, methodLimits), forwarders)
+
+	// call makes a login call from the given connection address for the forwarded client
+	// and returns the client IP the server saw
+	call := func(from, client string) (string, error) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(from), Port: 51234}})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ratelimit.ForwardedForHeader, client))
+		ip, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: loginMethod}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return ratelimit.ClientIPFromContext(ctx), nil
+		})
+		if err != nil {
+			return "", err
+		}
+		return ip.(string), nil
+	}
+
+	const attacker, customer = "203.0.113.9", "198.51.100.7"
+	for _, gateway := range []string{"127.0.0.1", "::1"} {
+		if ip, err := call(gateway, customer); err != nil || ip != customer {
+			t.Errorf("call from gateway %s = %q, %v, want %q", gateway, ip, err, customer)
+		}
+	}
+
+	// One client using up the login budget leaves the others theirs
+	for i := 0; i < methodLimits[loginMethod].Burst; i++ {
+		if _, err := call("127.0.0.1", attacker); err != nil {
+			t.Fatalf("login %d within the budget: %v", i+1, err)
+		}
+	}
+	if _, err := call("127.0.0.1", attacker); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("login over the budget: code = %v, want %v", status.Code(err), codes.ResourceExhausted)
+	}
+	if _, err := call("127.0.0.1", customer); err != nil {
+		t.Errorf("another client through the gateway: %v", err)
+	}
+
+	// Callers that are not forwarders cannot pick their IP
+	if ip, err := call("192.0.2.10", customer); err != nil || ip != "192.0.2.10" {
+		t.Errorf("call from a remote caller = %q, %v, want its own address", ip, err)
+	}
+
+	// Failures across many addresses lock out the failing client only
+	emailPolicy, ipPolicy := cfg.Login.Policies()
+	guard := service.NewLoginGuard(store, emailPolicy, ipPolicy)
+	for i := 0; i < cfg.Login.MaxFailuresPerIP; i++ {
+		guard.Failed(context.Background(), fmt.Sprintf("user%d@example.com", i), attacker)
+	}
+	if wait := guard.Check(context.Background(), "someone@example.com", attacker); wait <= 0 {
+		t.Error("failing client is not locked out")
+	}
+	if wait := guard.Check(context.Background(), "someone@example.com", customer); wait != 0 {
+		t.Errorf("other client locked out for %s", wait)
+	}
+}
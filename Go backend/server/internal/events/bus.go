@@ -0,0 +1,140 @@
+// Package events is the in-process event bus: services publish what happened after it is
+// committed, and streaming RPCs forward it to the clients watching.
+package events
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+// TransactionEventType says what happened to a transaction
+type TransactionEventType string
+
+const (
+	TransactionCreated       TransactionEventType = "created"
+	TransactionStatusChanged TransactionEventType = "status_changed"
+)
+
+// TransactionEvent is a change of one of a user's transactions
+type TransactionEvent struct {
+	Type        TransactionEventType
+	Transaction model.Transaction // Copy of the transaction after the change
+	OccurredAt  time.Time
+}
+
+var (
+	// ErrLagged ends a subscription whose subscriber fell so far behind that events were dropped
+	ErrLagged = errors.New("subscriber fell behind, events were dropped")
+	// ErrClosed ends the subscriptions of a closed bus
+	ErrClosed = errors.New("event bus closed")
+)
+
+// Bus delivers transaction events to the subscriptions of the transaction's user. Publishing
+// never blocks: a subscriber whose buffer is full is dropped with ErrLagged, so one slow
+// client can't hold up purchases.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[int]map[*Subscription]struct{} // By user ID
+	buffer int
+	closed bool
+}
+
+// NewBus creates a bus buffering up to buffer events per subscription
+func NewBus(buffer int) *Bus {
+	return &Bus{
+		subs:   make(map[int]map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscription receives the events of one user until it is closed
+type Subscription struct {
+	bus    *Bus
+	userID int
+	events chan TransactionEvent
+	err    error // Why the bus ended the subscription; set before events is closed
+}
+
+// Subscribe starts delivering userID's events. Close the subscription when done.
+func (b *Bus) Subscribe(userID int) *Subscription {
+	sub := &Subscription{bus: b, userID: userID, events: make(chan TransactionEvent, b.buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.err = ErrClosed
+		close(sub.events)
+		return sub
+	}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*Subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers event to the subscriptions of the transaction's user
+func (b *Bus) Publish(event TransactionEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[event.Transaction.UserID] {
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub, ErrLagged)
+		}
+	}
+}
+
+// Close ends every subscription with ErrClosed; later subscriptions end at once
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subs := range b.subs {
+		for sub := range subs {
+			b.remove(sub, ErrClosed)
+		}
+	}
+}
+
+// remove ends sub with err; b.mu must be held
+func (b *Bus) remove(sub *Subscription, err error) {
+	subs, ok := b.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.userID)
+	}
+	sub.err = err
+	close(sub.events)
+}
+
+// Events returns the channel of events, closed when the subscription ends
+func (s *Subscription) Events() <-chan TransactionEvent {
+	return s.events
+}
+
+// Err returns why the bus ended the subscription, once Events is closed
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s, nil)
+}
@@ -0,0 +1,11 @@
+package handler
+
+import (
+	"context"
+	"errors"
+)
+
+// isContextError reports whether err stems from the caller cancelling or timing out the request
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -13,49 +15,66 @@ import (
 
 type LoginHandler struct {
 	userService *service.UserService
+	loginGuard  *service.LoginGuard
 }
 
 // NewLoginHandler creates a new login handler
-func NewLoginHandler(userService *service.UserService) *LoginHandler {
+func NewLoginHandler(userService *service.UserService, loginGuard *service.LoginGuard) *LoginHandler {
 	return &LoginHandler{
 		userService: userService,
+		loginGuard:  loginGuard,
 	}
 }
 
 // Login authenticates a user with email and password
 func (h *LoginHandler) Login(ctx context.Context, req *protoc.LoginRequest) (*protoc.LoginResponse, error) {
-	email := req.GetEmail()
-	password := req.GetPassword()
-
-	if email == "" || password == "" {
-		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	// Locked out callers are turned away before their password is checked
+	ip := ratelimit.ClientIPFromContext(ctx)
+	if wait := h.loginGuard.Check(ctx, req.GetEmail(), ip); wait > 0 {
+		return nil, ratelimit.ExhaustedError(ctx, wait, "too many failed login attempts, try again later")
 	}
 
-	// Call service
-	user, err := h.userService.Login(email, password)
+	// Call service (the validation interceptor has checked the email and password are present)
+	user, err := h.userService.Login(ctx, req.GetEmail(), req.GetPassword())
 	if err != nil {
-		return nil, h.handleError(err)
+		grpcErr := h.handleError(err)
+		if status.Code(grpcErr) == codes.Unauthenticated {
+			h.loginGuard.Failed(ctx, req.GetEmail(), ip)
+		}
+		return nil, grpcErr
 	}
+	h.loginGuard.Succeeded(ctx, req.GetEmail())
+
+	return &protoc.LoginResponse{
+		User:    toProtoUser(user),
+		Message: "Login successful",
+	}, nil
+}
 
-	// Convert domain model to gRPC message
+// toProtoUser converts a domain user to a gRPC message
+func toProtoUser(user *model.User) *protoc.User {
 	pbUser := &protoc.User{
 		Id:        int32(user.ID),
 		Name:      user.Name,
 		Email:     user.Email,
+		Role:      string(user.Role),
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
 	}
 
-	return &protoc.LoginResponse{
-		User:    pbUser,
-		Message: "Login successful",
-	}, nil
+	if user.MerchantID != nil {
+		pbUser.MerchantId = int32(*user.MerchantID)
+	}
+
+	return pbUser
 }
 
 // handleError converts application errors to gRPC status errors
 func (h *LoginHandler) handleError(err error) error {
 	errMsg := err.Error()
 	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
 	case strings.Contains(errMsg, "invalid email or password"):
 		return status.Error(codes.Unauthenticated, errMsg)
 	case strings.Contains(errMsg, "user not found"):
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MerchantHandler struct {
+	merchantService *service.MerchantService
+}
+
+// NewMerchantHandler creates a new merchant handler
+func NewMerchantHandler(merchantService *service.MerchantService) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+	}
+}
+
+// CreateMerchant onboards a new merchant
+func (h *MerchantHandler) CreateMerchant(ctx context.Context, req *protoc.CreateMerchantRequest) (*protoc.CreateMerchantResponse, error) {
+	merchant := &model.Merchant{
+		Name:        req.GetName(),
+		Email:       req.GetEmail(),
+		Description: req.GetDescription(),
+	}
+
+	// Call service
+	merchant, err := h.merchantService.CreateMerchant(ctx, int(req.GetAdminId()), merchant)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreateMerchantResponse{
+		Merchant: &protoc.Merchant{
+			Id:          int32(merchant.ID),
+			Name:        merchant.Name,
+			Email:       merchant.Email,
+			Description: merchant.Description,
+			CreatedAt:   merchant.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:   merchant.UpdatedAt.Format(time.RFC3339),
+		},
+		Message: "Merchant created successfully",
+	}, nil
+}
+
+// AddMerchantUser makes a user a merchant user
+func (h *MerchantHandler) AddMerchantUser(ctx context.Context, req *protoc.AddMerchantUserRequest) (*protoc.AddMerchantUserResponse, error) {
+	// Call service
+	user, err := h.merchantService.AddMerchantUser(ctx, int(req.GetAdminId()), int(req.GetMerchantId()), int(req.GetUserId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.AddMerchantUserResponse{
+		User:    toProtoUser(user),
+		Message: "Merchant user added successfully",
+	}, nil
+}
+
+// ListMerchantVouchers lists the merchant's own vouchers
+func (h *MerchantHandler) ListMerchantVouchers(ctx context.Context, req *protoc.ListMerchantVouchersRequest) (*protoc.ListMerchantVouchersResponse, error) {
+	// Call service
+	vouchers, err := h.merchantService.ListVouchers(ctx, int(req.GetUserId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbVouchers := make([]*protoc.Voucher, 0, len(vouchers))
+	for _, v := range vouchers {
+		pbVouchers = append(pbVouchers, toProtoVoucher(v))
+	}
+
+	return &protoc.ListMerchantVouchersResponse{
+		Vouchers: pbVouchers,
+	}, nil
+}
+
+// CreateMerchantVoucher creates a voucher issued by the merchant
+func (h *MerchantHandler) CreateMerchantVoucher(ctx context.Context, req *protoc.CreateMerchantVoucherRequest) (*protoc.CreateMerchantVoucherResponse, error) {
+	voucher, err := fromProtoVoucherDetails(req.GetVoucher())
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	voucher, err = h.merchantService.CreateVoucher(ctx, int(req.GetUserId()), voucher)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreateMerchantVoucherResponse{
+		Voucher: toProtoVoucher(voucher),
+		Message: "Voucher created successfully",
+	}, nil
+}
+
+// UpdateMerchantVoucher updates one of the merchant's own vouchers
+func (h *MerchantHandler) UpdateMerchantVoucher(ctx context.Context, req *protoc.UpdateMerchantVoucherRequest) (*protoc.UpdateMerchantVoucherResponse, error) {
+	voucher, err := fromProtoVoucherDetails(req.GetVoucher())
+	if err != nil {
+		return nil, err
+	}
+	voucher.ID = int(req.GetVoucherId())
+
+	// Call service
+	voucher, err = h.merchantService.UpdateVoucher(ctx, int(req.GetUserId()), voucher)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.UpdateMerchantVoucherResponse{
+		Voucher: toProtoVoucher(voucher),
+		Message: "Voucher updated successfully",
+	}, nil
+}
+
+// ListMerchantSales lists the merchant's own sales
+func (h *MerchantHandler) ListMerchantSales(ctx context.Context, req *protoc.ListMerchantSalesRequest) (*protoc.ListMerchantSalesResponse, error) {
+	// Call service
+	sales, total, err := h.merchantService.ListSales(ctx, int(req.GetUserId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbSales := make([]*protoc.MerchantSale, 0, len(sales))
+	for _, sale := range sales {
+		pbSales = append(pbSales, &protoc.MerchantSale{
+			TransactionId:  int32(sale.TransactionID),
+			VoucherId:      int32(sale.VoucherID),
+			VoucherName:    sale.VoucherName,
+			UserId:         int32(sale.UserID),
+			Amount:         sale.Amount,
+			DiscountAmount: sale.DiscountAmount,
+			CreatedAt:      sale.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &protoc.ListMerchantSalesResponse{
+		Sales:        pbSales,
+		TotalRevenue: total,
+	}, nil
+}
+
+// fromProtoVoucherDetails converts merchant-editable voucher fields to a domain voucher.
+// The validation interceptor guarantees details are present.
+func fromProtoVoucherDetails(details *protoc.MerchantVoucherDetails) (*model.Voucher, error) {
+	validFrom, err := time.Parse(time.RFC3339, details.GetValidFrom())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "valid_from must be an RFC3339 timestamp")
+	}
+
+	validTo, err := time.Parse(time.RFC3339, details.GetValidTo())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "valid_to must be an RFC3339 timestamp")
+	}
+
+	return &model.Voucher{
+		Name:         details.GetName(),
+		Description:  details.GetDescription(),
+		Category:     details.GetCategory(),
+		RegularPrice: details.GetPrice(),
+		Quantity:     int(details.GetQuantity()),
+		ValidFrom:    validFrom,
+		ValidTo:      validTo,
+	}, nil
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *MerchantHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "admin users cannot be merchant users"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "merchant not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "voucher not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "merchant already exists"):
+		return status.Error(codes.AlreadyExists, errMsg)
+	case strings.Contains(errMsg, "invalid"):
+		// invalid IDs, merchant or voucher details
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
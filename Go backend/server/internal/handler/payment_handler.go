@@ -6,6 +6,7 @@ import (
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -25,9 +26,10 @@ func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
 func (h *PaymentHandler) BuyVoucher(ctx context.Context, req *protoc.BuyVoucherRequest) (*protoc.BuyVoucherResponse, error) {
 	userID := int(req.GetUserId())
 	voucherID := int(req.GetVoucherId())
+	promoCode := req.GetPromoCode()
 
 	// Call service
-	transaction, err := h.paymentService.BuyVoucher(userID, voucherID)
+	transaction, err := h.paymentService.BuyVoucher(ctx, userID, voucherID, promoCode)
 	if err != nil {
 		return nil, h.handleError(err)
 	}
@@ -39,6 +41,7 @@ func (h *PaymentHandler) BuyVoucher(ctx context.Context, req *protoc.BuyVoucherR
 		Amount:          transaction.Amount,
 		TransactionType: string(transaction.TransactionType),
 		PaymentStatus:   string(transaction.PaymentStatus),
+		DiscountAmount:  transaction.DiscountAmount,
 		CreatedAt:       transaction.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:       transaction.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -52,32 +55,115 @@ func (h *PaymentHandler) BuyVoucher(ctx context.Context, req *protoc.BuyVoucherR
 		pbTransaction.PaymentTxnId = *transaction.PaymentTxnID
 	}
 
+	if transaction.PromotionID != nil {
+		pbTransaction.PromotionId = int32(*transaction.PromotionID)
+	}
+
 	return &protoc.BuyVoucherResponse{
 		Transaction: pbTransaction,
 		Message:     "Voucher purchased successfully",
 	}, nil
 }
 
+// RefundPurchase handles refunding a purchase
+func (h *PaymentHandler) RefundPurchase(ctx context.Context, req *protoc.RefundPurchaseRequest) (*protoc.RefundPurchaseResponse, error) {
+	refund, err := h.paymentService.RefundPurchase(ctx, int(req.GetUserId()), int(req.GetTransactionId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.RefundPurchaseResponse{
+		Transaction: toProtoTransaction(refund),
+		Message:     "Purchase refunded successfully",
+	}, nil
+}
+
+// RedeemVoucher handles using a purchased voucher
+func (h *PaymentHandler) RedeemVoucher(ctx context.Context, req *protoc.RedeemVoucherRequest) (*protoc.RedeemVoucherResponse, error) {
+	purchase, err := h.paymentService.RedeemVoucher(ctx, int(req.GetUserId()), int(req.GetTransactionId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.RedeemVoucherResponse{
+		Transaction: toProtoTransaction(purchase),
+		Message:     "Voucher redeemed successfully",
+	}, nil
+}
+
+// TopUpWallet handles adding money to a wallet
+func (h *PaymentHandler) TopUpWallet(ctx context.Context, req *protoc.TopUpWalletRequest) (*protoc.TopUpWalletResponse, error) {
+	transaction, err := h.paymentService.TopUpWallet(ctx, int(req.GetUserId()), req.GetAmount())
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.TopUpWalletResponse{
+		Transaction: toProtoTransaction(transaction),
+		Message:     "Wallet topped up successfully",
+	}, nil
+}
+
 // handleError converts application errors to gRPC status errors
 func (h *PaymentHandler) handleError(err error) error {
 	errMsg := err.Error()
 	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
 	case strings.Contains(errMsg, "user not found"):
 		return status.Error(codes.NotFound, errMsg)
 	case strings.Contains(errMsg, "voucher not found"):
 		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "transaction not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "purchase already refunded"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "only successful purchases can be refunded"),
+		strings.Contains(errMsg, "only successful voucher purchases can be redeemed"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "voucher already redeemed"),
+		strings.Contains(errMsg, "redeemed vouchers can't be refunded"),
+		strings.Contains(errMsg, "refunded vouchers can't be redeemed"):
+		return status.Error(codes.FailedPrecondition, errMsg)
 	case strings.Contains(errMsg, "voucher out of stock"):
 		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "voucher daily purchase limit reached"):
+		return purchaseLimitError(errMsg, "DAILY_PURCHASE_LIMIT_REACHED")
+	case strings.Contains(errMsg, "voucher purchase limit reached"):
+		return purchaseLimitError(errMsg, "PURCHASE_LIMIT_REACHED")
+	case strings.Contains(errMsg, "flash sale sold out"):
+		return status.Error(codes.FailedPrecondition, errMsg)
 	case strings.Contains(errMsg, "voucher expired"):
 		return status.Error(codes.FailedPrecondition, errMsg)
 	case strings.Contains(errMsg, "insufficient wallet balance"):
 		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "promo code not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "invalid promo code"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	case strings.Contains(errMsg, "promo code"):
+		// inactive, expired, usage limit reached, minimum spend, category
+		return status.Error(codes.FailedPrecondition, errMsg)
 	case strings.Contains(errMsg, "payment processing failed"):
 		return status.Error(codes.Internal, errMsg)
-	case strings.Contains(errMsg, "invalid user ID") || strings.Contains(errMsg, "invalid voucher ID"):
+	case strings.Contains(errMsg, "invalid user ID") || strings.Contains(errMsg, "invalid voucher ID"),
+		strings.Contains(errMsg, "invalid transaction ID") || strings.Contains(errMsg, "invalid amount"):
 		return status.Error(codes.InvalidArgument, errMsg)
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
 }
 
+// purchaseLimitError builds a FailedPrecondition status carrying a machine-readable
+// reason, so clients can tell purchase limits apart from stock or balance failures
+func purchaseLimitError(errMsg, reason string) error {
+	st := status.New(codes.FailedPrecondition, errMsg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: "voucher.payment",
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
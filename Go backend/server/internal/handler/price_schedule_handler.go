@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type PriceScheduleHandler struct {
+	priceScheduleService *service.PriceScheduleService
+}
+
+// NewPriceScheduleHandler creates a new price schedule handler
+func NewPriceScheduleHandler(priceScheduleService *service.PriceScheduleService) *PriceScheduleHandler {
+	return &PriceScheduleHandler{
+		priceScheduleService: priceScheduleService,
+	}
+}
+
+// CreatePriceSchedule schedules a flash sale for a voucher
+func (h *PriceScheduleHandler) CreatePriceSchedule(ctx context.Context, req *protoc.CreatePriceScheduleRequest) (*protoc.CreatePriceScheduleResponse, error) {
+	startsAt, err := time.Parse(time.RFC3339, req.GetStartsAt())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "starts_at must be an RFC3339 timestamp")
+	}
+
+	endsAt, err := time.Parse(time.RFC3339, req.GetEndsAt())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "ends_at must be an RFC3339 timestamp")
+	}
+
+	schedule := &model.PriceSchedule{
+		VoucherID: int(req.GetVoucherId()),
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		SalePrice: req.GetSalePrice(),
+	}
+
+	// Zero means uncapped
+	if req.GetStockCap() != 0 {
+		stockCap := int(req.GetStockCap())
+		schedule.StockCap = &stockCap
+	}
+
+	// Call service
+	schedule, err = h.priceScheduleService.CreateSchedule(ctx, int(req.GetAdminId()), schedule)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreatePriceScheduleResponse{
+		Schedule: toProtoPriceSchedule(schedule),
+		Message:  "Price schedule created successfully",
+	}, nil
+}
+
+// CancelPriceSchedule cancels a flash sale
+func (h *PriceScheduleHandler) CancelPriceSchedule(ctx context.Context, req *protoc.CancelPriceScheduleRequest) (*protoc.CancelPriceScheduleResponse, error) {
+	// Call service
+	schedule, err := h.priceScheduleService.CancelSchedule(ctx, int(req.GetAdminId()), int(req.GetScheduleId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CancelPriceScheduleResponse{
+		Schedule: toProtoPriceSchedule(schedule),
+		Message:  "Price schedule cancelled successfully",
+	}, nil
+}
+
+// toProtoPriceSchedule converts a domain price schedule to a gRPC message
+func toProtoPriceSchedule(s *model.PriceSchedule) *protoc.PriceSchedule {
+	pbSchedule := &protoc.PriceSchedule{
+		Id:          int32(s.ID),
+		VoucherId:   int32(s.VoucherID),
+		StartsAt:    s.StartsAt.Format(time.RFC3339),
+		EndsAt:      s.EndsAt.Format(time.RFC3339),
+		SalePrice:   s.SalePrice,
+		SoldCount:   int32(s.SoldCount),
+		IsCancelled: s.IsCancelled,
+		CreatedAt:   s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   s.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if s.StockCap != nil {
+		pbSchedule.StockCap = int32(*s.StockCap)
+	}
+
+	return pbSchedule
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *PriceScheduleHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "voucher not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "price schedule not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "overlaps an existing schedule"):
+		return status.Error(codes.AlreadyExists, errMsg)
+	case strings.Contains(errMsg, "price schedule already"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "invalid price schedule"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	case strings.Contains(errMsg, "invalid user ID") || strings.Contains(errMsg, "invalid voucher ID"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
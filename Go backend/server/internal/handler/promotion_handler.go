@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// CreatePromotion creates a new promo code
+func (h *PromotionHandler) CreatePromotion(ctx context.Context, req *protoc.CreatePromotionRequest) (*protoc.CreatePromotionResponse, error) {
+	validFrom, err := time.Parse(time.RFC3339, req.GetValidFrom())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "valid_from must be an RFC3339 timestamp")
+	}
+
+	validTo, err := time.Parse(time.RFC3339, req.GetValidTo())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "valid_to must be an RFC3339 timestamp")
+	}
+
+	// Zero values in the request mean "not set"
+	promotion := &model.Promotion{
+		Code:          req.GetCode(),
+		Description:   req.GetDescription(),
+		DiscountType:  model.DiscountType(req.GetDiscountType()),
+		DiscountValue: req.GetDiscountValue(),
+		MinSpend:      req.GetMinSpend(),
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+	}
+
+	if req.GetMaxDiscount() > 0 {
+		maxDiscount := req.GetMaxDiscount()
+		promotion.MaxDiscount = &maxDiscount
+	}
+
+	if req.GetCategory() != "" {
+		category := req.GetCategory()
+		promotion.Category = &category
+	}
+
+	if req.GetUsageLimit() > 0 {
+		limit := int(req.GetUsageLimit())
+		promotion.UsageLimit = &limit
+	}
+
+	if req.GetPerUserLimit() > 0 {
+		limit := int(req.GetPerUserLimit())
+		promotion.PerUserLimit = &limit
+	}
+
+	// Call service
+	promotion, err = h.promotionService.CreatePromotion(ctx, int(req.GetAdminId()), promotion)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreatePromotionResponse{
+		Promotion: toProtoPromotion(promotion),
+		Message:   "Promotion created successfully",
+	}, nil
+}
+
+// ListPromotions retrieves all promo codes
+func (h *PromotionHandler) ListPromotions(ctx context.Context, req *protoc.ListPromotionsRequest) (*protoc.ListPromotionsResponse, error) {
+	// Call service
+	promotions, err := h.promotionService.ListPromotions(ctx, int(req.GetAdminId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbPromotions := make([]*protoc.Promotion, 0, len(promotions))
+	for _, p := range promotions {
+		pbPromotions = append(pbPromotions, toProtoPromotion(p))
+	}
+
+	return &protoc.ListPromotionsResponse{
+		Promotions: pbPromotions,
+	}, nil
+}
+
+// DeactivatePromotion disables a promo code
+func (h *PromotionHandler) DeactivatePromotion(ctx context.Context, req *protoc.DeactivatePromotionRequest) (*protoc.DeactivatePromotionResponse, error) {
+	// Call service
+	promotion, err := h.promotionService.DeactivatePromotion(ctx, int(req.GetAdminId()), int(req.GetPromotionId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.DeactivatePromotionResponse{
+		Promotion: toProtoPromotion(promotion),
+		Message:   "Promotion deactivated successfully",
+	}, nil
+}
+
+// toProtoPromotion converts a domain promotion to a gRPC message
+func toProtoPromotion(p *model.Promotion) *protoc.Promotion {
+	pbPromotion := &protoc.Promotion{
+		Id:            int32(p.ID),
+		Code:          p.Code,
+		Description:   p.Description,
+		DiscountType:  string(p.DiscountType),
+		DiscountValue: p.DiscountValue,
+		MinSpend:      p.MinSpend,
+		TimesUsed:     int32(p.TimesUsed),
+		IsActive:      p.IsActive,
+		ValidFrom:     p.ValidFrom.Format(time.RFC3339),
+		ValidTo:       p.ValidTo.Format(time.RFC3339),
+		CreatedAt:     p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     p.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if p.MaxDiscount != nil {
+		pbPromotion.MaxDiscount = *p.MaxDiscount
+	}
+
+	if p.Category != nil {
+		pbPromotion.Category = *p.Category
+	}
+
+	if p.UsageLimit != nil {
+		pbPromotion.UsageLimit = int32(*p.UsageLimit)
+	}
+
+	if p.PerUserLimit != nil {
+		pbPromotion.PerUserLimit = int32(*p.PerUserLimit)
+	}
+
+	return pbPromotion
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *PromotionHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "promotion not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "promo code already exists"):
+		return status.Error(codes.AlreadyExists, errMsg)
+	case strings.Contains(errMsg, "invalid promotion"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	case strings.Contains(errMsg, "invalid user ID"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ReservationHandler struct {
+	reservationService *service.ReservationService
+}
+
+// NewReservationHandler creates a new reservation handler
+func NewReservationHandler(reservationService *service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{
+		reservationService: reservationService,
+	}
+}
+
+// ReserveVoucher holds units of a voucher for a user
+func (h *ReservationHandler) ReserveVoucher(ctx context.Context, req *protoc.ReserveVoucherRequest) (*protoc.ReserveVoucherResponse, error) {
+	// Call service
+	reservation, err := h.reservationService.Reserve(ctx,
+		int(req.GetUserId()),
+		int(req.GetVoucherId()),
+		int(req.GetQuantity()),
+		int(req.GetHoldMinutes()),
+	)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.ReserveVoucherResponse{
+		Reservation: toProtoReservation(reservation),
+		Message:     "Voucher reserved successfully",
+	}, nil
+}
+
+// ReleaseReservation releases a stock hold early
+func (h *ReservationHandler) ReleaseReservation(ctx context.Context, req *protoc.ReleaseReservationRequest) (*protoc.ReleaseReservationResponse, error) {
+	// Call service
+	reservation, err := h.reservationService.Release(ctx, int(req.GetUserId()), int(req.GetReservationId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.ReleaseReservationResponse{
+		Reservation: toProtoReservation(reservation),
+		Message:     "Reservation released successfully",
+	}, nil
+}
+
+// toProtoReservation converts a domain reservation to a gRPC message
+func toProtoReservation(r *model.Reservation) *protoc.Reservation {
+	return &protoc.Reservation{
+		Id:        int32(r.ID),
+		VoucherId: int32(r.VoucherID),
+		UserId:    int32(r.UserID),
+		Quantity:  int32(r.Quantity),
+		Status:    string(r.Status),
+		ExpiresAt: r.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *ReservationHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "voucher not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "reservation not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "voucher expired"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "insufficient stock to reserve"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "reservation not active"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "invalid"):
+		// invalid user/voucher/reservation ID, quantity or hold duration
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
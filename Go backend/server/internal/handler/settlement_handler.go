@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SettlementHandler struct {
+	settlementService *service.SettlementService
+}
+
+// NewSettlementHandler creates a new settlement handler
+func NewSettlementHandler(settlementService *service.SettlementService) *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: settlementService,
+	}
+}
+
+// CreateSettlementBatch settles a merchant's transactions up to a period end
+func (h *SettlementHandler) CreateSettlementBatch(ctx context.Context, req *protoc.CreateSettlementBatchRequest) (*protoc.CreateSettlementBatchResponse, error) {
+	periodEnd, err := time.Parse(time.RFC3339, req.GetPeriodEnd())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "period_end must be an RFC3339 timestamp")
+	}
+
+	// Call service
+	batch, err := h.settlementService.CreateBatch(ctx, int(req.GetAdminId()), int(req.GetMerchantId()), periodEnd)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreateSettlementBatchResponse{
+		Batch:   toProtoSettlementBatch(batch),
+		Message: "Settlement batch created successfully",
+	}, nil
+}
+
+// CloseSettlementBatch closes a settlement batch
+func (h *SettlementHandler) CloseSettlementBatch(ctx context.Context, req *protoc.CloseSettlementBatchRequest) (*protoc.CloseSettlementBatchResponse, error) {
+	// Call service
+	batch, err := h.settlementService.CloseBatch(ctx, int(req.GetAdminId()), int(req.GetBatchId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CloseSettlementBatchResponse{
+		Batch:   toProtoSettlementBatch(batch),
+		Message: "Settlement batch closed successfully",
+	}, nil
+}
+
+// DiscardSettlementBatch discards an open settlement batch
+func (h *SettlementHandler) DiscardSettlementBatch(ctx context.Context, req *protoc.DiscardSettlementBatchRequest) (*protoc.DiscardSettlementBatchResponse, error) {
+	// Call service
+	if err := h.settlementService.DiscardBatch(ctx, int(req.GetAdminId()), int(req.GetBatchId())); err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.DiscardSettlementBatchResponse{
+		Message: "Settlement batch discarded successfully",
+	}, nil
+}
+
+// GetSettlementBatch retrieves a settlement batch with its line items
+func (h *SettlementHandler) GetSettlementBatch(ctx context.Context, req *protoc.GetSettlementBatchRequest) (*protoc.GetSettlementBatchResponse, error) {
+	// Call service
+	batch, err := h.settlementService.GetBatch(ctx, int(req.GetUserId()), int(req.GetBatchId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.GetSettlementBatchResponse{
+		Batch: toProtoSettlementBatch(batch),
+	}, nil
+}
+
+// ListSettlementBatches retrieves settlement batches
+func (h *SettlementHandler) ListSettlementBatches(ctx context.Context, req *protoc.ListSettlementBatchesRequest) (*protoc.ListSettlementBatchesResponse, error) {
+	// Call service
+	batches, err := h.settlementService.ListBatches(ctx, int(req.GetUserId()), int(req.GetMerchantId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbBatches := make([]*protoc.SettlementBatch, 0, len(batches))
+	for _, b := range batches {
+		pbBatches = append(pbBatches, toProtoSettlementBatch(b))
+	}
+
+	return &protoc.ListSettlementBatchesResponse{
+		Batches: pbBatches,
+	}, nil
+}
+
+// ExportSettlementBatch renders a settlement batch as CSV
+func (h *SettlementHandler) ExportSettlementBatch(ctx context.Context, req *protoc.ExportSettlementBatchRequest) (*protoc.ExportSettlementBatchResponse, error) {
+	// Call service
+	content, filename, err := h.settlementService.ExportBatchCSV(ctx, int(req.GetUserId()), int(req.GetBatchId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.ExportSettlementBatchResponse{
+		Filename: filename,
+		Content:  content,
+	}, nil
+}
+
+// toProtoSettlementBatch converts a domain settlement batch to a gRPC message
+func toProtoSettlementBatch(b *model.SettlementBatch) *protoc.SettlementBatch {
+	pbBatch := &protoc.SettlementBatch{
+		Id:               int32(b.ID),
+		MerchantId:       int32(b.MerchantID),
+		PeriodStart:      b.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:        b.PeriodEnd.Format(time.RFC3339),
+		CommissionRate:   b.CommissionRate,
+		GrossAmount:      b.GrossAmount,
+		RefundAmount:     b.RefundAmount,
+		CommissionAmount: b.CommissionAmount,
+		NetAmount:        b.NetAmount,
+		Status:           string(b.Status),
+		CreatedAt:        b.CreatedAt.Format(time.RFC3339),
+	}
+
+	if b.ClosedAt != nil {
+		pbBatch.ClosedAt = b.ClosedAt.Format(time.RFC3339)
+	}
+
+	for _, item := range b.Items {
+		pbBatch.Items = append(pbBatch.Items, &protoc.SettlementLineItem{
+			Id:              int32(item.ID),
+			TransactionId:   int32(item.TransactionID),
+			VoucherId:       int32(item.VoucherID),
+			VoucherName:     item.VoucherName,
+			TransactionType: string(item.TransactionType),
+			Amount:          item.Amount,
+			Commission:      item.Commission,
+			NetAmount:       item.NetAmount,
+			TransactionAt:   item.TransactionAt.Format(time.RFC3339),
+		})
+	}
+
+	return pbBatch
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *SettlementHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "merchant not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "settlement batch not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "settlement batch already open"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "settlement batch already closed"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "transaction already settled"):
+		return status.Error(codes.Aborted, errMsg)
+	case strings.Contains(errMsg, "invalid"):
+		// invalid IDs or settlement period
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
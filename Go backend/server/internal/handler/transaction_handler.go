@@ -2,14 +2,21 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/events"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// subscribedEvent is the type of the first event of a WatchTransactions stream
+const subscribedEvent = "subscribed"
+
 type TransactionHandler struct {
 	transactionService *service.TransactionService
 }
@@ -26,7 +33,7 @@ func (h *TransactionHandler) ListTransactions(ctx context.Context, req *protoc.L
 	userID := int(req.GetUserId())
 
 	// Call service
-	transactions, err := h.transactionService.ListTransactions(userID)
+	transactions, err := h.transactionService.ListTransactions(ctx, userID)
 	if err != nil {
 		return nil, h.handleError(err)
 	}
@@ -34,43 +41,106 @@ func (h *TransactionHandler) ListTransactions(ctx context.Context, req *protoc.L
 	// Convert domain models to gRPC messages
 	pbTransactions := make([]*protoc.Transaction, 0, len(transactions))
 	for _, t := range transactions {
-		pbTxn := &protoc.Transaction{
-			Id:              int32(t.ID),
-			UserId:          int32(t.UserID),
-			Amount:          t.Amount,
-			TransactionType: string(t.TransactionType),
-			PaymentStatus:   string(t.PaymentStatus),
-			CreatedAt:       t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:       t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+		pbTransactions = append(pbTransactions, toProtoTransaction(t))
+	}
 
-		if t.VoucherID != nil {
-			vID := int32(*t.VoucherID)
-			pbTxn.VoucherId = vID
-		}
+	return &protoc.ListTransactionsResponse{
+		Transactions: pbTransactions,
+	}, nil
+}
+
+// WatchTransactions streams the user's transaction events until the client goes away
+func (h *TransactionHandler) WatchTransactions(req *protoc.WatchTransactionsRequest, stream protoc.VoucherService_WatchTransactionsServer) error {
+	ctx := stream.Context()
+
+	// Call service
+	sub, err := h.transactionService.WatchTransactions(ctx, int(req.GetUserId()))
+	if err != nil {
+		return h.handleError(err)
+	}
+	defer sub.Close()
+
+	// Tell the client it is subscribed: transactions listed from now on miss no events
+	err = stream.Send(&protoc.TransactionEvent{
+		Type:       subscribedEvent,
+		OccurredAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
+	})
+	if err != nil {
+		return err
+	}
 
-		if t.PaymentTxnID != nil {
-			pbTxn.PaymentTxnId = *t.PaymentTxnID
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return h.handleError(sub.Err())
+			}
+			err := stream.Send(&protoc.TransactionEvent{
+				Type:        string(event.Type),
+				Transaction: toProtoTransaction(&event.Transaction),
+				OccurredAt:  event.OccurredAt.Format("2006-01-02T15:04:05Z07:00"),
+			})
+			if err != nil {
+				return err
+			}
 		}
+	}
+}
 
-		pbTransactions = append(pbTransactions, pbTxn)
+// toProtoTransaction converts a domain transaction to its gRPC message
+func toProtoTransaction(t *model.Transaction) *protoc.Transaction {
+	pbTxn := &protoc.Transaction{
+		Id:              int32(t.ID),
+		UserId:          int32(t.UserID),
+		Amount:          t.Amount,
+		TransactionType: string(t.TransactionType),
+		PaymentStatus:   string(t.PaymentStatus),
+		DiscountAmount:  t.DiscountAmount,
+		CreatedAt:       t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
-	return &protoc.ListTransactionsResponse{
-		Transactions: pbTransactions,
-	}, nil
+	if t.VoucherID != nil {
+		pbTxn.VoucherId = int32(*t.VoucherID)
+	}
+
+	if t.PaymentTxnID != nil {
+		pbTxn.PaymentTxnId = *t.PaymentTxnID
+	}
+
+	if t.PromotionID != nil {
+		pbTxn.PromotionId = int32(*t.PromotionID)
+	}
+
+	if t.OriginalTransactionID != nil {
+		pbTxn.OriginalTransactionId = int32(*t.OriginalTransactionID)
+	}
+
+	if t.RedeemedAt != nil {
+		pbTxn.RedeemedAt = t.RedeemedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return pbTxn
 }
 
 // handleError converts application errors to gRPC status errors
 func (h *TransactionHandler) handleError(err error) error {
 	errMsg := err.Error()
 	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
 	case strings.Contains(errMsg, "user not found"):
 		return status.Error(codes.NotFound, errMsg)
 	case strings.Contains(errMsg, "invalid user ID"):
 		return status.Error(codes.InvalidArgument, errMsg)
 	case strings.Contains(errMsg, "transaction not found"):
 		return status.Error(codes.NotFound, errMsg)
+	case errors.Is(err, events.ErrLagged):
+		return status.Error(codes.Aborted, "stream fell behind, reconnect and list transactions")
+	case errors.Is(err, events.ErrClosed):
+		return status.Error(codes.Unavailable, "server is shutting down, reconnect")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
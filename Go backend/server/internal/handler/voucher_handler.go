@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -38,8 +39,14 @@ func (h *VoucherHandler) Search(ctx context.Context, req *protoc.SearchRequest)
 		maxPrice = &price
 	}
 
+	var merchantID *int
+	if req.GetMerchantId() != 0 {
+		id := int(req.GetMerchantId())
+		merchantID = &id
+	}
+
 	// Call service
-	vouchers, err := h.voucherService.SearchVouchers(category, minPrice, maxPrice)
+	vouchers, err := h.voucherService.SearchVouchers(ctx, int(req.GetUserId()), category, merchantID, minPrice, maxPrice)
 	if err != nil {
 		return nil, h.handleError(err)
 	}
@@ -47,18 +54,7 @@ func (h *VoucherHandler) Search(ctx context.Context, req *protoc.SearchRequest)
 	// Convert domain models to gRPC messages
 	pbVouchers := make([]*protoc.Voucher, 0, len(vouchers))
 	for _, v := range vouchers {
-		pbVouchers = append(pbVouchers, &protoc.Voucher{
-			Id:          int32(v.ID),
-			Name:        v.Name,
-			Description: v.Description,
-			Category:    v.Category,
-			Price:       v.Price,
-			Quantity:    int32(v.Quantity),
-			ValidFrom:   v.ValidFrom.Format(time.RFC3339),
-			ValidTo:     v.ValidTo.Format(time.RFC3339),
-			CreatedAt:   v.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   v.UpdatedAt.Format(time.RFC3339),
-		})
+		pbVouchers = append(pbVouchers, toProtoVoucher(v))
 	}
 
 	return &protoc.SearchResponse{
@@ -66,10 +62,82 @@ func (h *VoucherHandler) Search(ctx context.Context, req *protoc.SearchRequest)
 	}, nil
 }
 
+// SetVoucherPurchaseLimits configures per-user purchase limits on a voucher
+func (h *VoucherHandler) SetVoucherPurchaseLimits(ctx context.Context, req *protoc.SetVoucherPurchaseLimitsRequest) (*protoc.SetVoucherPurchaseLimitsResponse, error) {
+	// Zero means unlimited
+	var maxPerUser, maxPerUserPerDay *int
+	if req.GetMaxPerUser() != 0 {
+		limit := int(req.GetMaxPerUser())
+		maxPerUser = &limit
+	}
+	if req.GetMaxPerUserPerDay() != 0 {
+		limit := int(req.GetMaxPerUserPerDay())
+		maxPerUserPerDay = &limit
+	}
+
+	// Call service
+	voucher, err := h.voucherService.SetPurchaseLimits(ctx, int(req.GetAdminId()), int(req.GetVoucherId()), maxPerUser, maxPerUserPerDay)
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.SetVoucherPurchaseLimitsResponse{
+		Voucher: toProtoVoucher(voucher),
+		Message: "Purchase limits updated successfully",
+	}, nil
+}
+
+// toProtoVoucher converts a domain voucher to a gRPC message
+func toProtoVoucher(v *model.Voucher) *protoc.Voucher {
+	pbVoucher := &protoc.Voucher{
+		Id:                 int32(v.ID),
+		Name:               v.Name,
+		Description:        v.Description,
+		Category:           v.Category,
+		Price:              v.Price,
+		Quantity:           int32(v.Quantity - v.Reserved),
+		ValidFrom:          v.ValidFrom.Format(time.RFC3339),
+		ValidTo:            v.ValidTo.Format(time.RFC3339),
+		CreatedAt:          v.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          v.UpdatedAt.Format(time.RFC3339),
+		RemainingAllowance: -1,
+	}
+
+	if v.MerchantID != nil {
+		pbVoucher.MerchantId = int32(*v.MerchantID)
+	}
+
+	if v.MaxPerUser != nil {
+		pbVoucher.MaxPerUser = int32(*v.MaxPerUser)
+	}
+
+	if v.MaxPerUserPerDay != nil {
+		pbVoucher.MaxPerUserPerDay = int32(*v.MaxPerUserPerDay)
+	}
+
+	if v.RemainingAllowance != nil {
+		pbVoucher.RemainingAllowance = int32(*v.RemainingAllowance)
+	}
+
+	pbVoucher.RegularPrice = v.RegularPrice
+	if v.ActiveSale != nil {
+		pbVoucher.OnSale = true
+		pbVoucher.SaleEndsAt = v.ActiveSale.EndsAt.Format(time.RFC3339)
+		pbVoucher.SaleStockRemaining = -1
+		if v.ActiveSale.StockCap != nil {
+			pbVoucher.SaleStockRemaining = int32(*v.ActiveSale.StockCap - v.ActiveSale.SoldCount)
+		}
+	}
+
+	return pbVoucher
+}
+
 // handleError converts application errors to gRPC status errors
 func (h *VoucherHandler) handleError(err error) error {
 	errMsg := err.Error()
 	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
 	case strings.Contains(errMsg, "voucher not found"):
 		return status.Error(codes.NotFound, errMsg)
 	case strings.Contains(errMsg, "voucher out of stock"):
@@ -78,6 +146,12 @@ func (h *VoucherHandler) handleError(err error) error {
 		return status.Error(codes.FailedPrecondition, errMsg)
 	case strings.Contains(errMsg, "invalid voucher ID"):
 		return status.Error(codes.InvalidArgument, errMsg)
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "invalid purchase limit"):
+		return status.Error(codes.InvalidArgument, errMsg)
+	case strings.Contains(errMsg, "invalid merchant ID"):
+		return status.Error(codes.InvalidArgument, errMsg)
 	case strings.Contains(errMsg, "invalid price"):
 		return status.Error(codes.InvalidArgument, errMsg)
 	case strings.Contains(errMsg, "user not found"):
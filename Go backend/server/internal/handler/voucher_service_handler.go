@@ -15,6 +15,12 @@ type VoucherServiceHandler struct {
 	paymentHandler      *PaymentHandler
 	walletHandler       *WalletHandler
 	transactionHandler  *TransactionHandler
+	promotionHandler    *PromotionHandler
+	priceScheduleHandler *PriceScheduleHandler
+	reservationHandler  *ReservationHandler
+	merchantHandler     *MerchantHandler
+	settlementHandler   *SettlementHandler
+	webhookHandler      *WebhookHandler
 }
 
 // NewVoucherServiceHandler creates a new combined handler
@@ -24,13 +30,26 @@ func NewVoucherServiceHandler(
 	paymentService *service.PaymentService,
 	walletService *service.WalletService,
 	transactionService *service.TransactionService,
+	promotionService *service.PromotionService,
+	priceScheduleService *service.PriceScheduleService,
+	reservationService *service.ReservationService,
+	merchantService *service.MerchantService,
+	settlementService *service.SettlementService,
+	webhookService *service.WebhookService,
+	loginGuard *service.LoginGuard,
 ) *VoucherServiceHandler {
 	return &VoucherServiceHandler{
-		loginHandler:       NewLoginHandler(userService),
+		loginHandler:       NewLoginHandler(userService, loginGuard),
 		voucherHandler:     NewVoucherHandler(voucherService),
 		paymentHandler:     NewPaymentHandler(paymentService),
 		walletHandler:      NewWalletHandler(walletService),
 		transactionHandler: NewTransactionHandler(transactionService),
+		promotionHandler:   NewPromotionHandler(promotionService),
+		priceScheduleHandler: NewPriceScheduleHandler(priceScheduleService),
+		reservationHandler: NewReservationHandler(reservationService),
+		merchantHandler:    NewMerchantHandler(merchantService),
+		settlementHandler:  NewSettlementHandler(settlementService),
+		webhookHandler:     NewWebhookHandler(webhookService),
 	}
 }
 
@@ -49,6 +68,21 @@ func (h *VoucherServiceHandler) BuyVoucher(ctx context.Context, req *protoc.BuyV
 	return h.paymentHandler.BuyVoucher(ctx, req)
 }
 
+// RefundPurchase delegates to PaymentHandler
+func (h *VoucherServiceHandler) RefundPurchase(ctx context.Context, req *protoc.RefundPurchaseRequest) (*protoc.RefundPurchaseResponse, error) {
+	return h.paymentHandler.RefundPurchase(ctx, req)
+}
+
+// RedeemVoucher delegates to PaymentHandler
+func (h *VoucherServiceHandler) RedeemVoucher(ctx context.Context, req *protoc.RedeemVoucherRequest) (*protoc.RedeemVoucherResponse, error) {
+	return h.paymentHandler.RedeemVoucher(ctx, req)
+}
+
+// TopUpWallet delegates to PaymentHandler
+func (h *VoucherServiceHandler) TopUpWallet(ctx context.Context, req *protoc.TopUpWalletRequest) (*protoc.TopUpWalletResponse, error) {
+	return h.paymentHandler.TopUpWallet(ctx, req)
+}
+
 // GetBalance delegates to WalletHandler
 func (h *VoucherServiceHandler) GetBalance(ctx context.Context, req *protoc.GetBalanceRequest) (*protoc.GetBalanceResponse, error) {
 	return h.walletHandler.GetBalance(ctx, req)
@@ -59,3 +93,137 @@ func (h *VoucherServiceHandler) ListTransactions(ctx context.Context, req *proto
 	return h.transactionHandler.ListTransactions(ctx, req)
 }
 
+// WatchTransactions delegates to TransactionHandler
+func (h *VoucherServiceHandler) WatchTransactions(req *protoc.WatchTransactionsRequest, stream protoc.VoucherService_WatchTransactionsServer) error {
+	return h.transactionHandler.WatchTransactions(req, stream)
+}
+
+// CreatePromotion delegates to PromotionHandler
+func (h *VoucherServiceHandler) CreatePromotion(ctx context.Context, req *protoc.CreatePromotionRequest) (*protoc.CreatePromotionResponse, error) {
+	return h.promotionHandler.CreatePromotion(ctx, req)
+}
+
+// ListPromotions delegates to PromotionHandler
+func (h *VoucherServiceHandler) ListPromotions(ctx context.Context, req *protoc.ListPromotionsRequest) (*protoc.ListPromotionsResponse, error) {
+	return h.promotionHandler.ListPromotions(ctx, req)
+}
+
+// DeactivatePromotion delegates to PromotionHandler
+func (h *VoucherServiceHandler) DeactivatePromotion(ctx context.Context, req *protoc.DeactivatePromotionRequest) (*protoc.DeactivatePromotionResponse, error) {
+	return h.promotionHandler.DeactivatePromotion(ctx, req)
+}
+
+// SetVoucherPurchaseLimits delegates to VoucherHandler
+func (h *VoucherServiceHandler) SetVoucherPurchaseLimits(ctx context.Context, req *protoc.SetVoucherPurchaseLimitsRequest) (*protoc.SetVoucherPurchaseLimitsResponse, error) {
+	return h.voucherHandler.SetVoucherPurchaseLimits(ctx, req)
+}
+
+// CreatePriceSchedule delegates to PriceScheduleHandler
+func (h *VoucherServiceHandler) CreatePriceSchedule(ctx context.Context, req *protoc.CreatePriceScheduleRequest) (*protoc.CreatePriceScheduleResponse, error) {
+	return h.priceScheduleHandler.CreatePriceSchedule(ctx, req)
+}
+
+// CancelPriceSchedule delegates to PriceScheduleHandler
+func (h *VoucherServiceHandler) CancelPriceSchedule(ctx context.Context, req *protoc.CancelPriceScheduleRequest) (*protoc.CancelPriceScheduleResponse, error) {
+	return h.priceScheduleHandler.CancelPriceSchedule(ctx, req)
+}
+
+// ReserveVoucher delegates to ReservationHandler
+func (h *VoucherServiceHandler) ReserveVoucher(ctx context.Context, req *protoc.ReserveVoucherRequest) (*protoc.ReserveVoucherResponse, error) {
+	return h.reservationHandler.ReserveVoucher(ctx, req)
+}
+
+// ReleaseReservation delegates to ReservationHandler
+func (h *VoucherServiceHandler) ReleaseReservation(ctx context.Context, req *protoc.ReleaseReservationRequest) (*protoc.ReleaseReservationResponse, error) {
+	return h.reservationHandler.ReleaseReservation(ctx, req)
+}
+
+// CreateMerchant delegates to MerchantHandler
+func (h *VoucherServiceHandler) CreateMerchant(ctx context.Context, req *protoc.CreateMerchantRequest) (*protoc.CreateMerchantResponse, error) {
+	return h.merchantHandler.CreateMerchant(ctx, req)
+}
+
+// AddMerchantUser delegates to MerchantHandler
+func (h *VoucherServiceHandler) AddMerchantUser(ctx context.Context, req *protoc.AddMerchantUserRequest) (*protoc.AddMerchantUserResponse, error) {
+	return h.merchantHandler.AddMerchantUser(ctx, req)
+}
+
+// ListMerchantVouchers delegates to MerchantHandler
+func (h *VoucherServiceHandler) ListMerchantVouchers(ctx context.Context, req *protoc.ListMerchantVouchersRequest) (*protoc.ListMerchantVouchersResponse, error) {
+	return h.merchantHandler.ListMerchantVouchers(ctx, req)
+}
+
+// CreateMerchantVoucher delegates to MerchantHandler
+func (h *VoucherServiceHandler) CreateMerchantVoucher(ctx context.Context, req *protoc.CreateMerchantVoucherRequest) (*protoc.CreateMerchantVoucherResponse, error) {
+	return h.merchantHandler.CreateMerchantVoucher(ctx, req)
+}
+
+// UpdateMerchantVoucher delegates to MerchantHandler
+func (h *VoucherServiceHandler) UpdateMerchantVoucher(ctx context.Context, req *protoc.UpdateMerchantVoucherRequest) (*protoc.UpdateMerchantVoucherResponse, error) {
+	return h.merchantHandler.UpdateMerchantVoucher(ctx, req)
+}
+
+// ListMerchantSales delegates to MerchantHandler
+func (h *VoucherServiceHandler) ListMerchantSales(ctx context.Context, req *protoc.ListMerchantSalesRequest) (*protoc.ListMerchantSalesResponse, error) {
+	return h.merchantHandler.ListMerchantSales(ctx, req)
+}
+
+// CreateSettlementBatch delegates to SettlementHandler
+func (h *VoucherServiceHandler) CreateSettlementBatch(ctx context.Context, req *protoc.CreateSettlementBatchRequest) (*protoc.CreateSettlementBatchResponse, error) {
+	return h.settlementHandler.CreateSettlementBatch(ctx, req)
+}
+
+// CloseSettlementBatch delegates to SettlementHandler
+func (h *VoucherServiceHandler) CloseSettlementBatch(ctx context.Context, req *protoc.CloseSettlementBatchRequest) (*protoc.CloseSettlementBatchResponse, error) {
+	return h.settlementHandler.CloseSettlementBatch(ctx, req)
+}
+
+// DiscardSettlementBatch delegates to SettlementHandler
+func (h *VoucherServiceHandler) DiscardSettlementBatch(ctx context.Context, req *protoc.DiscardSettlementBatchRequest) (*protoc.DiscardSettlementBatchResponse, error) {
+	return h.settlementHandler.DiscardSettlementBatch(ctx, req)
+}
+
+// GetSettlementBatch delegates to SettlementHandler
+func (h *VoucherServiceHandler) GetSettlementBatch(ctx context.Context, req *protoc.GetSettlementBatchRequest) (*protoc.GetSettlementBatchResponse, error) {
+	return h.settlementHandler.GetSettlementBatch(ctx, req)
+}
+
+// ListSettlementBatches delegates to SettlementHandler
+func (h *VoucherServiceHandler) ListSettlementBatches(ctx context.Context, req *protoc.ListSettlementBatchesRequest) (*protoc.ListSettlementBatchesResponse, error) {
+	return h.settlementHandler.ListSettlementBatches(ctx, req)
+}
+
+// ExportSettlementBatch delegates to SettlementHandler
+func (h *VoucherServiceHandler) ExportSettlementBatch(ctx context.Context, req *protoc.ExportSettlementBatchRequest) (*protoc.ExportSettlementBatchResponse, error) {
+	return h.settlementHandler.ExportSettlementBatch(ctx, req)
+}
+
+// CreateWebhookEndpoint delegates to WebhookHandler
+func (h *VoucherServiceHandler) CreateWebhookEndpoint(ctx context.Context, req *protoc.CreateWebhookEndpointRequest) (*protoc.CreateWebhookEndpointResponse, error) {
+	return h.webhookHandler.CreateWebhookEndpoint(ctx, req)
+}
+
+// ListWebhookEndpoints delegates to WebhookHandler
+func (h *VoucherServiceHandler) ListWebhookEndpoints(ctx context.Context, req *protoc.ListWebhookEndpointsRequest) (*protoc.ListWebhookEndpointsResponse, error) {
+	return h.webhookHandler.ListWebhookEndpoints(ctx, req)
+}
+
+// EnableWebhookEndpoint delegates to WebhookHandler
+func (h *VoucherServiceHandler) EnableWebhookEndpoint(ctx context.Context, req *protoc.EnableWebhookEndpointRequest) (*protoc.EnableWebhookEndpointResponse, error) {
+	return h.webhookHandler.EnableWebhookEndpoint(ctx, req)
+}
+
+// DeleteWebhookEndpoint delegates to WebhookHandler
+func (h *VoucherServiceHandler) DeleteWebhookEndpoint(ctx context.Context, req *protoc.DeleteWebhookEndpointRequest) (*protoc.DeleteWebhookEndpointResponse, error) {
+	return h.webhookHandler.DeleteWebhookEndpoint(ctx, req)
+}
+
+// ListWebhookDeliveries delegates to WebhookHandler
+func (h *VoucherServiceHandler) ListWebhookDeliveries(ctx context.Context, req *protoc.ListWebhookDeliveriesRequest) (*protoc.ListWebhookDeliveriesResponse, error) {
+	return h.webhookHandler.ListWebhookDeliveries(ctx, req)
+}
+
+// ReplayWebhookDelivery delegates to WebhookHandler
+func (h *VoucherServiceHandler) ReplayWebhookDelivery(ctx context.Context, req *protoc.ReplayWebhookDeliveryRequest) (*protoc.ReplayWebhookDeliveryResponse, error) {
+	return h.webhookHandler.ReplayWebhookDelivery(ctx, req)
+}
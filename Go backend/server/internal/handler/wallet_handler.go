@@ -26,7 +26,7 @@ func (h *WalletHandler) GetBalance(ctx context.Context, req *protoc.GetBalanceRe
 	userID := int(req.GetUserId())
 
 	// Call service
-	balance, err := h.walletService.GetBalance(userID)
+	balance, err := h.walletService.GetBalance(ctx, userID)
 	if err != nil {
 		return nil, h.handleError(err)
 	}
@@ -40,6 +40,8 @@ func (h *WalletHandler) GetBalance(ctx context.Context, req *protoc.GetBalanceRe
 func (h *WalletHandler) handleError(err error) error {
 	errMsg := err.Error()
 	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
 	case strings.Contains(errMsg, "user not found"):
 		return status.Error(codes.NotFound, errMsg)
 	case strings.Contains(errMsg, "invalid user ID"):
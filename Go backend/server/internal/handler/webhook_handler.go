@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookEndpoint registers a webhook endpoint for the merchant user's merchant
+func (h *WebhookHandler) CreateWebhookEndpoint(ctx context.Context, req *protoc.CreateWebhookEndpointRequest) (*protoc.CreateWebhookEndpointResponse, error) {
+	// Call service
+	endpoint, err := h.webhookService.CreateEndpoint(ctx, int(req.GetUserId()), req.GetUrl(), req.GetEventTypes())
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.CreateWebhookEndpointResponse{
+		Endpoint: toProtoWebhookEndpoint(endpoint),
+		Secret:   endpoint.Secret,
+		Message:  "Webhook endpoint created successfully; store the secret, it is not shown again",
+	}, nil
+}
+
+// ListWebhookEndpoints retrieves the webhook endpoints of the merchant user's merchant
+func (h *WebhookHandler) ListWebhookEndpoints(ctx context.Context, req *protoc.ListWebhookEndpointsRequest) (*protoc.ListWebhookEndpointsResponse, error) {
+	// Call service
+	endpoints, err := h.webhookService.ListEndpoints(ctx, int(req.GetUserId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbEndpoints := make([]*protoc.WebhookEndpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		pbEndpoints = append(pbEndpoints, toProtoWebhookEndpoint(e))
+	}
+
+	return &protoc.ListWebhookEndpointsResponse{
+		Endpoints: pbEndpoints,
+	}, nil
+}
+
+// EnableWebhookEndpoint re-enables a disabled webhook endpoint
+func (h *WebhookHandler) EnableWebhookEndpoint(ctx context.Context, req *protoc.EnableWebhookEndpointRequest) (*protoc.EnableWebhookEndpointResponse, error) {
+	// Call service
+	endpoint, err := h.webhookService.EnableEndpoint(ctx, int(req.GetUserId()), int(req.GetEndpointId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.EnableWebhookEndpointResponse{
+		Endpoint: toProtoWebhookEndpoint(endpoint),
+		Message:  "Webhook endpoint enabled successfully",
+	}, nil
+}
+
+// DeleteWebhookEndpoint removes a webhook endpoint and its delivery log
+func (h *WebhookHandler) DeleteWebhookEndpoint(ctx context.Context, req *protoc.DeleteWebhookEndpointRequest) (*protoc.DeleteWebhookEndpointResponse, error) {
+	// Call service
+	if err := h.webhookService.DeleteEndpoint(ctx, int(req.GetUserId()), int(req.GetEndpointId())); err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.DeleteWebhookEndpointResponse{
+		Message: "Webhook endpoint deleted successfully",
+	}, nil
+}
+
+// ListWebhookDeliveries retrieves the delivery log of a webhook endpoint
+func (h *WebhookHandler) ListWebhookDeliveries(ctx context.Context, req *protoc.ListWebhookDeliveriesRequest) (*protoc.ListWebhookDeliveriesResponse, error) {
+	// Call service
+	deliveries, err := h.webhookService.ListDeliveries(ctx, int(req.GetUserId()), int(req.GetEndpointId()), req.GetStatus())
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	// Convert domain models to gRPC messages
+	pbDeliveries := make([]*protoc.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		pbDeliveries = append(pbDeliveries, toProtoWebhookDelivery(d))
+	}
+
+	return &protoc.ListWebhookDeliveriesResponse{
+		Deliveries: pbDeliveries,
+	}, nil
+}
+
+// ReplayWebhookDelivery sends a webhook delivery again
+func (h *WebhookHandler) ReplayWebhookDelivery(ctx context.Context, req *protoc.ReplayWebhookDeliveryRequest) (*protoc.ReplayWebhookDeliveryResponse, error) {
+	// Call service
+	delivery, err := h.webhookService.ReplayDelivery(ctx, int(req.GetUserId()), int(req.GetDeliveryId()))
+	if err != nil {
+		return nil, h.handleError(err)
+	}
+
+	return &protoc.ReplayWebhookDeliveryResponse{
+		Delivery: toProtoWebhookDelivery(delivery),
+		Message:  "Webhook delivery queued for replay",
+	}, nil
+}
+
+// toProtoWebhookEndpoint converts a domain webhook endpoint to a gRPC message
+func toProtoWebhookEndpoint(e *model.WebhookEndpoint) *protoc.WebhookEndpoint {
+	pbEndpoint := &protoc.WebhookEndpoint{
+		Id:                  int32(e.ID),
+		MerchantId:          int32(e.MerchantID),
+		Url:                 e.URL,
+		EventTypes:          e.EventTypes,
+		Status:              string(e.Status),
+		ConsecutiveFailures: int32(e.ConsecutiveFailures),
+		CreatedAt:           e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           e.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if e.DisabledReason != nil {
+		pbEndpoint.DisabledReason = *e.DisabledReason
+	}
+	if e.DisabledAt != nil {
+		pbEndpoint.DisabledAt = e.DisabledAt.Format(time.RFC3339)
+	}
+
+	return pbEndpoint
+}
+
+// toProtoWebhookDelivery converts a domain webhook delivery to a gRPC message
+func toProtoWebhookDelivery(d *model.WebhookDelivery) *protoc.WebhookDelivery {
+	pbDelivery := &protoc.WebhookDelivery{
+		Id:         int32(d.ID),
+		EndpointId: int32(d.EndpointID),
+		EventId:    int64(d.EventID),
+		EventType:  d.EventType,
+		Payload:    string(d.Payload),
+		Status:     string(d.Status),
+		Attempts:   int32(d.Attempts),
+		CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+	}
+
+	if d.Status == model.WebhookDeliveryPending {
+		pbDelivery.NextAttemptAt = d.NextAttemptAt.Format(time.RFC3339)
+	}
+	if d.LastStatusCode != nil {
+		pbDelivery.LastStatusCode = int32(*d.LastStatusCode)
+	}
+	if d.LastError != nil {
+		pbDelivery.LastError = *d.LastError
+	}
+	if d.LastAttemptAt != nil {
+		pbDelivery.LastAttemptAt = d.LastAttemptAt.Format(time.RFC3339)
+	}
+	if d.DeliveredAt != nil {
+		pbDelivery.DeliveredAt = d.DeliveredAt.Format(time.RFC3339)
+	}
+
+	return pbDelivery
+}
+
+// handleError converts application errors to gRPC status errors
+func (h *WebhookHandler) handleError(err error) error {
+	errMsg := err.Error()
+	switch {
+	case isContextError(err):
+		return status.FromContextError(err).Err()
+	case strings.Contains(errMsg, "permission denied"):
+		return status.Error(codes.PermissionDenied, errMsg)
+	case strings.Contains(errMsg, "user not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "webhook endpoint not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "webhook delivery not found"):
+		return status.Error(codes.NotFound, errMsg)
+	case strings.Contains(errMsg, "can't be replayed"):
+		return status.Error(codes.FailedPrecondition, errMsg)
+	case strings.Contains(errMsg, "invalid"):
+		// invalid IDs, URL or event types
+		return status.Error(codes.InvalidArgument, errMsg)
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,80 @@
+// Package health tracks whether the server's dependencies are usable, so readiness
+// probes can take an instance out of rotation while the database is unreachable.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/config"
+)
+
+// errNotChecked is reported until the first health check finishes
+var errNotChecked = errors.New("database not checked yet")
+
+// DBMonitor pings the database in the background and remembers the outcome
+type DBMonitor struct {
+	db      *sql.DB
+	timeout time.Duration
+
+	mu  sync.RWMutex
+	err error
+}
+
+// NewDBMonitor creates a monitor whose pings give up after timeout
+func NewDBMonitor(db *sql.DB, timeout time.Duration) *DBMonitor {
+	return &DBMonitor{db: db, timeout: timeout, err: errNotChecked}
+}
+
+// Start checks the database now and then every interval until ctx is cancelled
+func (m *DBMonitor) Start(ctx context.Context, interval time.Duration) {
+	m.Check(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check pings the database once and records the result
+func (m *DBMonitor) Check(ctx context.Context) error {
+	err := config.PingDB(ctx, m.db, m.timeout)
+	if ctx.Err() != nil {
+		return err // Shutting down; keep the last real result
+	}
+
+	m.mu.Lock()
+	previous := m.err
+	m.err = err
+	m.mu.Unlock()
+
+	metrics.SetDBUp(err == nil)
+	switch {
+	case err != nil && previous == nil:
+		slog.Warn("Database health check failed", "error", err)
+	case err == nil && previous != nil:
+		slog.Info("Database is healthy")
+	}
+	return err
+}
+
+// Err returns nil if the last check reached the database, or why it did not
+func (m *DBMonitor) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Dependencies reported by the gRPC health service under their own service names.
+// The empty service name (and every registered gRPC service) reports the server as
+// a whole, which is SERVING only while all dependencies are.
+const (
+	DependencyDB             = "db"
+	DependencyPaymentGateway = "payment_gateway"
+)
+
+// Check reports whether a dependency is usable; nil means healthy
+type Check func(ctx context.Context) error
+
+// Reporter runs dependency checks and publishes the results on the standard grpc.health.v1 service
+type Reporter struct {
+	server   *health.Server
+	timeout  time.Duration
+	services []string // gRPC services that follow the overall status
+
+	mu     sync.RWMutex
+	names  []string // Dependency names in the order they were added
+	checks map[string]Check
+	errs   map[string]error // Last result of each check
+}
+
+// NewReporter creates a reporter whose checks give up after timeout. The overall status
+// is also published for services, normally the gRPC services the server registers.
+func NewReporter(timeout time.Duration, services ...string) *Reporter {
+	r := &Reporter{
+		server:   health.NewServer(),
+		timeout:  timeout,
+		services: services,
+		checks:   make(map[string]Check),
+		errs:     make(map[string]error),
+	}
+	r.publish("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, service := range services {
+		r.publish(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return r
+}
+
+// Server returns the grpc.health.v1 implementation to register on the gRPC server
+func (r *Reporter) Server() healthpb.HealthServer {
+	return r.server
+}
+
+// Add registers a dependency check; it is NOT_SERVING until it first passes
+func (r *Reporter) Add(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.names = append(r.names, name)
+	r.checks[name] = check
+	r.errs[name] = errors.New("not checked yet")
+	r.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Start runs the checks now and then every interval until ctx is cancelled
+func (r *Reporter) Start(ctx context.Context, interval time.Duration) {
+	r.CheckAll(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.CheckAll(ctx)
+			}
+		}
+	}()
+}
+
+// CheckAll runs every check and updates the published statuses
+func (r *Reporter) CheckAll(ctx context.Context) {
+	r.mu.RLock()
+	names := slices.Clone(r.names)
+	r.mu.RUnlock()
+
+	for _, name := range names {
+		r.mu.RLock()
+		check := r.checks[name]
+		r.mu.RUnlock()
+
+		checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		err := check(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return // Shutting down; keep the last real results
+		}
+
+		r.mu.Lock()
+		previous := r.errs[name]
+		r.errs[name] = err
+		r.mu.Unlock()
+
+		switch {
+		case err != nil && previous == nil:
+			slog.Warn("Dependency is unhealthy", "dependency", name, "error", err)
+		case err == nil && previous != nil:
+			slog.Info("Dependency is healthy", "dependency", name)
+		}
+		r.publish(name, servingStatus(err))
+	}
+
+	overall := servingStatus(r.Ready())
+	r.publish("", overall)
+	for _, service := range r.services {
+		r.publish(service, overall)
+	}
+}
+
+// Ready returns nil if every dependency passed its last check, or the first one that did not
+func (r *Reporter) Ready() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, name := range r.names {
+		if err := r.errs[name]; err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown reports NOT_SERVING for everything so clients stop sending requests while the server drains
+func (r *Reporter) Shutdown() {
+	r.server.Shutdown()
+}
+
+func (r *Reporter) publish(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	r.server.SetServingStatus(service, status)
+}
+
+func servingStatus(err error) healthpb.HealthCheckResponse_ServingStatus {
+	if err != nil {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,51 @@
+// Package interceptor holds the gRPC server interceptors that are specific to the voucher service.
+// Shared interceptors (request logging, metrics) live in the logging and metrics packages.
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery turns a panic in a handler into a codes.Internal error instead of crashing the server.
+// The panic and its stack trace are logged; the client only sees a generic message.
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(ctx).Error("Recovered from panic in gRPC handler",
+					"method", info.FullMethod,
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				resp, err = nil, status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery is Recovery for streaming handlers
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(ss.Context()).Error("Recovered from panic in gRPC handler",
+					"method", info.FullMethod,
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
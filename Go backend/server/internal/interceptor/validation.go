@@ -0,0 +1,202 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Validation rejects requests that break the (validate.rules) field options declared in
+// voucher.proto with codes.InvalidArgument, before the handler runs. Every violation is
+// listed in the message and as a BadRequest field violation in the status details.
+func Validation() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := validationError(msg); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidation is Validation for streaming RPCs: every message the client sends is checked
+// as the handler receives it
+func StreamValidation() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ss})
+	}
+}
+
+// validatingStream validates the messages it receives
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+// RecvMsg receives m and returns an InvalidArgument error if it breaks its rules
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return validationError(msg)
+	}
+	return nil
+}
+
+// validationError returns the InvalidArgument error listing msg's violations, or nil if there are none
+func validationError(msg proto.Message) error {
+	violations := validate(msg.ProtoReflect(), "")
+	if len(violations) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(violations))
+	for i, v := range violations {
+		descriptions[i] = v.GetField() + " " + v.GetDescription()
+	}
+	st := status.New(codes.InvalidArgument, "invalid request: "+strings.Join(descriptions, "; "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// fieldRule is a field of a message type together with its declared rules
+type fieldRule struct {
+	field protoreflect.FieldDescriptor
+	rules *protoc.FieldRules // Nil for message fields without rules, which are still checked recursively
+}
+
+// ruleCache maps message full names to their []fieldRule, read once per type
+var ruleCache sync.Map
+
+// rulesFor returns the fields of a message type that have rules or may contain fields with rules
+func rulesFor(desc protoreflect.MessageDescriptor) []fieldRule {
+	if cached, ok := ruleCache.Load(desc.FullName()); ok {
+		return cached.([]fieldRule)
+	}
+
+	var rules []fieldRule
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.IsList() || fd.IsMap() {
+			continue
+		}
+
+		r, _ := proto.GetExtension(fd.Options(), protoc.E_Rules).(*protoc.FieldRules)
+		if r != nil || fd.Kind() == protoreflect.MessageKind {
+			rules = append(rules, fieldRule{field: fd, rules: r})
+		}
+	}
+
+	ruleCache.Store(desc.FullName(), rules)
+	return rules
+}
+
+// validate checks m and the messages nested in it; prefix is the path of m in the request
+func validate(m protoreflect.Message, prefix string) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, fr := range rulesFor(m.Descriptor()) {
+		fd := fr.field
+		path := prefix + string(fd.Name())
+
+		if fd.Kind() == protoreflect.MessageKind {
+			switch {
+			case m.Has(fd):
+				violations = append(violations, validate(m.Get(fd).Message(), path+".")...)
+			case fr.rules.GetRequired():
+				violations = append(violations, violation(path, "is required"))
+			}
+			continue
+		}
+
+		if fr.rules.GetIgnoreEmpty() && !m.Has(fd) {
+			continue
+		}
+		if problem := checkField(fd, m.Get(fd), fr.rules); problem != "" {
+			violations = append(violations, violation(path, problem))
+		}
+	}
+	return violations
+}
+
+// checkField returns what is wrong with a scalar value, or "" if it follows the rules
+func checkField(fd protoreflect.FieldDescriptor, v protoreflect.Value, rules *protoc.FieldRules) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return checkString(v.String(), rules)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return checkNumber(float64(v.Int()), rules)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return checkNumber(float64(v.Uint()), rules)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return checkNumber(v.Float(), rules)
+	}
+	return ""
+}
+
+func checkString(s string, rules *protoc.FieldRules) string {
+	switch {
+	case rules.GetRequired() && strings.TrimSpace(s) == "":
+		return "is required"
+	case rules.GetMaxLen() > 0 && utf8.RuneCountInString(s) > int(rules.GetMaxLen()):
+		return fmt.Sprintf("must be at most %d characters", rules.GetMaxLen())
+	case rules.GetEmail() && !isEmail(s):
+		return "must be a valid email address"
+	case rules.GetRfc3339() && !isRFC3339(s):
+		return "must be an RFC3339 timestamp"
+	case len(rules.GetIn()) > 0 && !slices.Contains(rules.GetIn(), s):
+		return "must be one of " + strings.Join(rules.GetIn(), ", ")
+	}
+	return ""
+}
+
+func checkNumber(n float64, rules *protoc.FieldRules) string {
+	switch {
+	case rules.Gt != nil && n <= rules.GetGt():
+		return "must be greater than " + formatNumber(rules.GetGt())
+	case rules.Gte != nil && n < rules.GetGte():
+		return "must be at least " + formatNumber(rules.GetGte())
+	case rules.Lt != nil && n >= rules.GetLt():
+		return "must be less than " + formatNumber(rules.GetLt())
+	case rules.Lte != nil && n > rules.GetLte():
+		return "must be at most " + formatNumber(rules.GetLte())
+	}
+	return ""
+}
+
+// isEmail accepts a bare address such as "user@example.com", without a display name
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func isRFC3339(s string) bool {
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func violation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: description}
+}
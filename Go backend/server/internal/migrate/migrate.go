@@ -0,0 +1,298 @@
+// Package migrate applies the embedded SQL migrations to PostgreSQL.
+// Progress is kept in the schema_migrations table used by golang-migrate,
+// so databases migrated with that tool are picked up where they left off.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// advisoryLockID keeps concurrent runners (e.g. several servers starting with auto-migrate) from interleaving
+const advisoryLockID = 7260314
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one schema change with the SQL to apply and revert it
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Runner applies migrations to a database
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration // Sorted by version
+}
+
+// New creates a runner for the migrations found in fsys
+func New(db *sql.DB, fsys fs.FS) (*Runner, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, migrations: migrations}, nil
+}
+
+// load reads and pairs up the migration files; every version needs both an up and a down file
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names", version)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return int(a.Version) - int(b.Version) })
+
+	return migrations, nil
+}
+
+// Latest returns the schema version this build expects
+func (r *Runner) Latest() uint {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Version returns the database's schema version (0 if never migrated) and whether
+// a migration failed halfway, which needs manual repair
+func (r *Runner) Version(ctx context.Context) (uint, bool, error) {
+	if err := r.ensureTable(ctx, r.db); err != nil {
+		return 0, false, err
+	}
+	return currentVersion(ctx, r.db)
+}
+
+// Status lists every migration and whether the database has it
+func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	version, _, err := r.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		statuses = append(statuses, MigrationStatus{Migration: m, Applied: m.Version <= version})
+	}
+	return statuses, nil
+}
+
+// Check verifies the database is at the version this build expects
+func (r *Runner) Check(ctx context.Context) error {
+	version, dirty, err := r.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch latest := r.Latest(); {
+	case dirty:
+		return fmt.Errorf("database schema is dirty at version %d: a migration failed and must be repaired by hand", version)
+	case version > latest:
+		return fmt.Errorf("database schema version %d is newer than this build expects (%d)", version, latest)
+	case version < latest:
+		return &OutdatedError{Version: version, Latest: latest}
+	}
+	return nil
+}
+
+// OutdatedError reports a database that is missing migrations
+type OutdatedError struct {
+	Version uint
+	Latest  uint
+}
+
+func (e *OutdatedError) Error() string {
+	return fmt.Sprintf("database schema version %d is behind this build (%d)", e.Version, e.Latest)
+}
+
+// IsOutdated reports whether err means migrations are pending
+func IsOutdated(err error) bool {
+	var outdated *OutdatedError
+	return errors.As(err, &outdated)
+}
+
+// Up applies every pending migration and returns the ones applied
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := r.locked(ctx, func(conn *sql.Conn, version uint) error {
+		for _, m := range r.migrations {
+			if m.Version <= version {
+				continue
+			}
+			if err := apply(ctx, conn, m.Up, m.Version); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns the ones reverted
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be positive")
+	}
+
+	var reverted []Migration
+	err := r.locked(ctx, func(conn *sql.Conn, version uint) error {
+		for i := len(r.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := r.migrations[i]
+			if m.Version > version {
+				continue
+			}
+
+			var previous uint
+			if i > 0 {
+				previous = r.migrations[i-1].Version
+			}
+			if err := apply(ctx, conn, m.Down, previous); err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// locked runs fn on one connection holding the migration lock, with the current version
+func (r *Runner) locked(ctx context.Context, fn func(conn *sql.Conn, version uint) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Waiting for the lock and long schema changes must not hit the server's statement timeout
+	if _, err := conn.ExecContext(ctx, `SET statement_timeout = 0`); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `RESET statement_timeout`)
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	if err := r.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d: a migration failed and must be repaired by hand", version)
+	}
+
+	return fn(conn, version)
+}
+
+// execer is implemented by *sql.DB and *sql.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r *Runner) ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func currentVersion(ctx context.Context, db execer) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// apply runs one migration script and records the resulting version in a single transaction,
+// so a failed script leaves neither schema changes nor a dirty version behind
+func apply(ctx context.Context, conn *sql.Conn, script string, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, FALSE)`, version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(body)}
+	}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			files: fstest.MapFS{},
+			want:  []Migration{},
+		},
+		{
+			name: "pairs up and down files",
+			files: fstest.MapFS{
+				"000001_create_users.up.sql":   file("CREATE TABLE users ();"),
+				"000001_create_users.down.sql": file("DROP TABLE users;"),
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_users", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+			},
+		},
+		{
+			name: "sorted numerically by version",
+			files: fstest.MapFS{
+				"10_ten.up.sql":       file("up 10"),
+				"10_ten.down.sql":     file("down 10"),
+				"000002_two.up.sql":   file("up 2"),
+				"000002_two.down.sql": file("down 2"),
+				"9_nine.up.sql":       file("up 9"),
+				"9_nine.down.sql":     file("down 9"),
+			},
+			want: []Migration{
+				{Version: 2, Name: "two", Up: "up 2", Down: "down 2"},
+				{Version: 9, Name: "nine", Up: "up 9", Down: "down 9"},
+				{Version: 10, Name: "ten", Up: "up 10", Down: "down 10"},
+			},
+		},
+		{
+			name: "ignores other files and directories",
+			files: fstest.MapFS{
+				"000001_init.up.sql":      file("up"),
+				"000001_init.down.sql":    file("down"),
+				"migrations.go":           file("package migrations"),
+				"README.md":               file("docs"),
+				"000002_draft.sql":        file("draft"),
+				"000003_dir.up.sql/x.sql": file("nested"),
+			},
+			want: []Migration{
+				{Version: 1, Name: "init", Up: "up", Down: "down"},
+			},
+		},
+		{
+			name: "missing down file",
+			files: fstest.MapFS{
+				"000001_init.up.sql": file("up"),
+			},
+			wantErr: "migration 1_init needs both an up and a down file",
+		},
+		{
+			name: "missing up file",
+			files: fstest.MapFS{
+				"000001_init.up.sql":    file("up"),
+				"000001_init.down.sql":  file("down"),
+				"000002_index.down.sql": file("down"),
+			},
+			wantErr: "migration 2_index needs both an up and a down file",
+		},
+		{
+			name: "empty up file",
+			files: fstest.MapFS{
+				"000001_init.up.sql":   file(""),
+				"000001_init.down.sql": file("down"),
+			},
+			wantErr: "migration 1_init needs both an up and a down file",
+		},
+		{
+			name: "names differ",
+			files: fstest.MapFS{
+				"000001_init.up.sql":      file("up"),
+				"000001_initial.down.sql": file("down"),
+			},
+			wantErr: "migration 1 has files with different names",
+		},
+		{
+			name: "version zero",
+			files: fstest.MapFS{
+				"000000_init.up.sql":   file("up"),
+				"000000_init.down.sql": file("down"),
+			},
+			wantErr: "invalid migration version in 000000_init.down.sql",
+		},
+		{
+			name: "version out of range",
+			files: fstest.MapFS{
+				"99999999999_init.up.sql":   file("up"),
+				"99999999999_init.down.sql": file("down"),
+			},
+			wantErr: "invalid migration version in 99999999999_init.down.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("load() = %d migrations, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("migration %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	loaded, err := load(migrations.FS)
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("load() found no migrations")
+	}
+
+	// Versions must run 1, 2, 3... so a gap means a file was lost or misnumbered
+	for i, m := range loaded {
+		if m.Version != uint(i+1) {
+			t.Errorf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+	}
+}
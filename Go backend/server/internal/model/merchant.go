@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Merchant represents a brand that issues vouchers
+type Merchant struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Email       string    `json:"email" db:"email"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantSale represents a successful purchase of a merchant's voucher
+type MerchantSale struct {
+	TransactionID  int       `json:"transaction_id" db:"transaction_id"`
+	VoucherID      int       `json:"voucher_id" db:"voucher_id"`
+	VoucherName    string    `json:"voucher_name" db:"voucher_name"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Amount         float64   `json:"amount" db:"amount"`
+	DiscountAmount float64   `json:"discount_amount" db:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
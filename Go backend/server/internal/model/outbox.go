@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// OutboxStatus represents the delivery state of an outbox event
+type OutboxStatus string
+
+const (
+	OutboxStatusPending      OutboxStatus = "pending"
+	OutboxStatusDelivered    OutboxStatus = "delivered"     // Every sink accepted the event
+	OutboxStatusDeadLettered OutboxStatus = "dead_lettered" // Attempts ran out; kept for inspection
+)
+
+// Aggregate types of outbox events. Events of one aggregate are delivered in the order they were recorded.
+const (
+	AggregateUser = "user" // Events about a user's money, keyed by user ID
+)
+
+// Outbox event types
+const (
+	EventVoucherPurchased = "voucher.purchased"
+	EventVoucherRefunded  = "voucher.refunded"
+	EventVoucherRedeemed  = "voucher.redeemed"
+	EventWalletToppedUp   = "wallet.topped_up"
+)
+
+// OutboxEvent is a domain event recorded in the same transaction as the change it describes.
+// The outbox relay delivers it at least once to every configured sink.
+type OutboxEvent struct {
+	ID            int             `json:"id" db:"id"`
+	AggregateType string          `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id" db:"aggregate_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Status        OutboxStatus    `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	DeliveredTo   []string        `json:"delivered_to" db:"delivered_to"`       // Sinks that accepted the event
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"` // Nullable
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	ProcessedAt   *time.Time      `json:"processed_at,omitempty" db:"processed_at"` // Nullable, set when delivered or dead-lettered
+	LockedUntil   *time.Time      `json:"locked_until,omitempty" db:"locked_until"` // Nullable, set while a relay holds the event
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// DeliveredToSink reports whether sink already accepted the event
+func (e *OutboxEvent) DeliveredToSink(sink string) bool {
+	return slices.Contains(e.DeliveredTo, sink)
+}
+
+// TransactionEventPayload is the payload of the events recorded for committed transactions
+type TransactionEventPayload struct {
+	Transaction Transaction `json:"transaction"`
+	VoucherName string      `json:"voucher_name,omitempty"`
+	Category    string      `json:"category,omitempty"`
+	MerchantID  *int        `json:"merchant_id,omitempty"` // Issuing merchant of the voucher, if any
+}
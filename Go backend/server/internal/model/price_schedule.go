@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// PriceSchedule represents a time-boxed sale price for a voucher (flash sale)
+type PriceSchedule struct {
+	ID          int       `json:"id" db:"id"`
+	VoucherID   int       `json:"voucher_id" db:"voucher_id"`
+	StartsAt    time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time `json:"ends_at" db:"ends_at"`
+	SalePrice   float64   `json:"sale_price" db:"sale_price"`
+	StockCap    *int      `json:"stock_cap,omitempty" db:"stock_cap"` // Nullable, units sellable at the sale price
+	SoldCount   int       `json:"sold_count" db:"sold_count"`
+	IsCancelled bool      `json:"is_cancelled" db:"is_cancelled"`
+	CreatedBy   *int      `json:"created_by,omitempty" db:"created_by"` // Nullable
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsActiveAt reports whether the schedule sets the voucher price at the given time
+func (s *PriceSchedule) IsActiveAt(t time.Time) bool {
+	if s.IsCancelled || t.Before(s.StartsAt) || !t.Before(s.EndsAt) {
+		return false
+	}
+	return s.StockCap == nil || s.SoldCount < *s.StockCap
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// DiscountType represents how a promotion discount is calculated
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFlat       DiscountType = "flat"
+)
+
+// Promotion represents an admin-created promo code
+type Promotion struct {
+	ID            int          `json:"id" db:"id"`
+	Code          string       `json:"code" db:"code"`
+	Description   string       `json:"description" db:"description"`
+	DiscountType  DiscountType `json:"discount_type" db:"discount_type"`
+	DiscountValue float64      `json:"discount_value" db:"discount_value"`
+	MaxDiscount   *float64     `json:"max_discount,omitempty" db:"max_discount"` // Nullable, caps percentage discounts
+	MinSpend      float64      `json:"min_spend" db:"min_spend"`
+	Category      *string      `json:"category,omitempty" db:"category"`             // Nullable, restricts to one category
+	UsageLimit    *int         `json:"usage_limit,omitempty" db:"usage_limit"`       // Nullable, global redemption limit
+	PerUserLimit  *int         `json:"per_user_limit,omitempty" db:"per_user_limit"` // Nullable, redemptions per user
+	TimesUsed     int          `json:"times_used" db:"times_used"`
+	IsActive      bool         `json:"is_active" db:"is_active"`
+	ValidFrom     time.Time    `json:"valid_from" db:"valid_from"`
+	ValidTo       time.Time    `json:"valid_to" db:"valid_to"`
+	CreatedBy     *int         `json:"created_by,omitempty" db:"created_by"` // Nullable
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// PromotionRedemption records a promotion applied to a purchase
+type PromotionRedemption struct {
+	ID             int       `json:"id" db:"id"`
+	PromotionID    int       `json:"promotion_id" db:"promotion_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	TransactionID  int       `json:"transaction_id" db:"transaction_id"`
+	DiscountAmount float64   `json:"discount_amount" db:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
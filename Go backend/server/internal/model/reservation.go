@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ReservationStatus represents the state of a stock hold
+type ReservationStatus string
+
+const (
+	ReservationStatusActive   ReservationStatus = "active"
+	ReservationStatusConsumed ReservationStatus = "consumed"
+	ReservationStatusReleased ReservationStatus = "released"
+	ReservationStatusExpired  ReservationStatus = "expired"
+)
+
+// Reservation represents units of a voucher held for a user until it expires
+type Reservation struct {
+	ID        int               `json:"id" db:"id"`
+	VoucherID int               `json:"voucher_id" db:"voucher_id"`
+	UserID    int               `json:"user_id" db:"user_id"`
+	Quantity  int               `json:"quantity" db:"quantity"` // Units still held; decremented as purchases consume the hold
+	Status    ReservationStatus `json:"status" db:"status"`
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// SettlementStatus represents the state of a settlement batch
+type SettlementStatus string
+
+const (
+	SettlementStatusOpen   SettlementStatus = "open"
+	SettlementStatusClosed SettlementStatus = "closed"
+)
+
+// SettlementBatch represents what a merchant is owed for a settlement period.
+// Closed batches are immutable; refunds recorded later roll into the next batch.
+type SettlementBatch struct {
+	ID               int                   `json:"id" db:"id"`
+	MerchantID       int                   `json:"merchant_id" db:"merchant_id"`
+	PeriodStart      time.Time             `json:"period_start" db:"period_start"`
+	PeriodEnd        time.Time             `json:"period_end" db:"period_end"`
+	CommissionRate   float64               `json:"commission_rate" db:"commission_rate"`
+	GrossAmount      float64               `json:"gross_amount" db:"gross_amount"`
+	RefundAmount     float64               `json:"refund_amount" db:"refund_amount"`
+	CommissionAmount float64               `json:"commission_amount" db:"commission_amount"`
+	NetAmount        float64               `json:"net_amount" db:"net_amount"`
+	Status           SettlementStatus      `json:"status" db:"status"`
+	CreatedBy        *int                  `json:"created_by,omitempty" db:"created_by"` // Nullable
+	ClosedAt         *time.Time            `json:"closed_at,omitempty" db:"closed_at"`   // Nullable, set when closed
+	CreatedAt        time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at" db:"updated_at"`
+	Items            []*SettlementLineItem `json:"items,omitempty" db:"-"`
+}
+
+// SettlementLineItem represents one settled transaction; refunds carry negative amounts
+type SettlementLineItem struct {
+	ID              int             `json:"id" db:"id"`
+	BatchID         int             `json:"batch_id" db:"batch_id"`
+	TransactionID   int             `json:"transaction_id" db:"transaction_id"`
+	VoucherID       int             `json:"voucher_id" db:"voucher_id"`
+	VoucherName     string          `json:"voucher_name" db:"voucher_name"`
+	TransactionType TransactionType `json:"transaction_type" db:"transaction_type"`
+	Amount          float64         `json:"amount" db:"amount"`
+	Commission      float64         `json:"commission" db:"commission"`
+	NetAmount       float64         `json:"net_amount" db:"net_amount"`
+	TransactionAt   time.Time       `json:"transaction_at" db:"transaction_at"`
+}
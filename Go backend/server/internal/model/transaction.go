@@ -22,14 +22,17 @@ const (
 
 // Transaction represents a transaction in the system
 type Transaction struct {
-	ID                int             `json:"id" db:"id"`
-	UserID            int             `json:"user_id" db:"user_id"`
-	VoucherID         *int            `json:"voucher_id,omitempty" db:"voucher_id"` // Nullable
-	Amount            float64         `json:"amount" db:"amount"`
-	TransactionType  TransactionType `json:"transaction_type" db:"transaction_type"`
-	PaymentStatus     PaymentStatus    `json:"payment_status" db:"payment_status"`
-	PaymentTxnID      *string          `json:"payment_txn_id,omitempty" db:"payment_txn_id"` // Nullable, from Mock UPI
-	CreatedAt         time.Time        `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time        `json:"updated_at" db:"updated_at"`
+	ID                    int             `json:"id" db:"id"`
+	UserID                int             `json:"user_id" db:"user_id"`
+	VoucherID             *int            `json:"voucher_id,omitempty" db:"voucher_id"` // Nullable
+	Amount                float64         `json:"amount" db:"amount"`
+	TransactionType       TransactionType `json:"transaction_type" db:"transaction_type"`
+	PaymentStatus         PaymentStatus   `json:"payment_status" db:"payment_status"`
+	PaymentTxnID          *string         `json:"payment_txn_id,omitempty" db:"payment_txn_id"` // Nullable, from Mock UPI
+	PromotionID           *int            `json:"promotion_id,omitempty" db:"promotion_id"`     // Nullable, applied promo code
+	DiscountAmount        float64         `json:"discount_amount" db:"discount_amount"`
+	OriginalTransactionID *int            `json:"original_transaction_id,omitempty" db:"original_transaction_id"` // Nullable, the purchase a refund returns
+	RedeemedAt            *time.Time      `json:"redeemed_at,omitempty" db:"redeemed_at"`                         // Nullable, set once a purchased voucher is redeemed
+	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
 }
-
@@ -2,13 +2,23 @@ package model
 
 import "time"
 
+// UserRole represents the role of a user
+type UserRole string
+
+const (
+	UserRoleCustomer UserRole = "customer"
+	UserRoleAdmin    UserRole = "admin"
+	UserRoleMerchant UserRole = "merchant"
+)
+
 // User represents a user in the system
 type User struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Email     string    `json:"email" db:"email"`
-	Password  string    `json:"-" db:"password"` // Password is not exposed in JSON
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         int       `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Email      string    `json:"email" db:"email"`
+	Password   string    `json:"-" db:"password"` // Password is not exposed in JSON
+	Role       UserRole  `json:"role" db:"role"`
+	MerchantID *int      `json:"merchant_id,omitempty" db:"merchant_id"` // Nullable, set for merchant users
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
-
@@ -4,15 +4,21 @@ import "time"
 
 // Voucher represents a voucher in the system
 type Voucher struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Category    string    `json:"category" db:"category"`
-	Price       float64   `json:"price" db:"price"`
-	Quantity    int       `json:"quantity" db:"quantity"`
-	ValidFrom   time.Time `json:"valid_from" db:"valid_from"`
-	ValidTo     time.Time `json:"valid_to" db:"valid_to"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int            `json:"id" db:"id"`
+	Name               string         `json:"name" db:"name"`
+	Description        string         `json:"description" db:"description"`
+	Category           string         `json:"category" db:"category"`
+	MerchantID         *int           `json:"merchant_id,omitempty" db:"merchant_id"` // Nullable, issuing merchant
+	Price              float64        `json:"price" db:"price"`                       // Effective price, the sale price while a flash sale is active
+	RegularPrice       float64        `json:"regular_price" db:"-"`
+	ActiveSale         *PriceSchedule `json:"active_sale,omitempty" db:"-"` // Nil unless a flash sale is active
+	Quantity           int            `json:"quantity" db:"quantity"`
+	Reserved           int            `json:"reserved" db:"-"` // Units held by active reservations
+	ValidFrom          time.Time      `json:"valid_from" db:"valid_from"`
+	ValidTo            time.Time      `json:"valid_to" db:"valid_to"`
+	MaxPerUser         *int           `json:"max_per_user,omitempty" db:"max_per_user"`                 // Nullable, lifetime purchases per user
+	MaxPerUserPerDay   *int           `json:"max_per_user_per_day,omitempty" db:"max_per_user_per_day"` // Nullable, purchases per user per day
+	RemainingAllowance *int           `json:"remaining_allowance,omitempty" db:"-"`                     // Computed for the requesting user, nil if unlimited
+	CreatedAt          time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at" db:"updated_at"`
 }
-
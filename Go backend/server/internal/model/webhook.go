@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEndpointStatus represents whether an endpoint receives deliveries
+type WebhookEndpointStatus string
+
+const (
+	WebhookEndpointActive   WebhookEndpointStatus = "active"
+	WebhookEndpointDisabled WebhookEndpointStatus = "disabled" // Turned off after failing repeatedly, until re-enabled
+)
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Attempts ran out; can be replayed
+)
+
+// Event types merchants can receive on their webhook endpoints
+const (
+	WebhookEventVoucherSold     = "voucher.sold"
+	WebhookEventVoucherRefunded = "voucher.refunded"
+	WebhookEventVoucherRedeemed = "voucher.redeemed"
+)
+
+// WebhookEventTypes lists every event type merchants can subscribe to
+var WebhookEventTypes = []string{WebhookEventVoucherSold, WebhookEventVoucherRefunded, WebhookEventVoucherRedeemed}
+
+// WebhookEndpoint is a URL a merchant registered to be notified of its events
+type WebhookEndpoint struct {
+	ID                  int                   `json:"id" db:"id"`
+	MerchantID          int                   `json:"merchant_id" db:"merchant_id"`
+	URL                 string                `json:"url" db:"url"`
+	Secret              string                `json:"-" db:"secret"`                // Signs the payloads
+	EventTypes          []string              `json:"event_types" db:"event_types"` // Empty for every event type
+	Status              WebhookEndpointStatus `json:"status" db:"status"`
+	ConsecutiveFailures int                   `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledReason      *string               `json:"disabled_reason,omitempty" db:"disabled_reason"` // Nullable, set while disabled
+	DisabledAt          *time.Time            `json:"disabled_at,omitempty" db:"disabled_at"`         // Nullable, set while disabled
+	CreatedAt           time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one endpoint; its attempts form the delivery log
+type WebhookDelivery struct {
+	ID             int                   `json:"id" db:"id"`
+	EndpointID     int                   `json:"endpoint_id" db:"endpoint_id"`
+	EventID        int                   `json:"event_id" db:"event_id"` // Outbox event the delivery is for
+	EventType      string                `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"` // Body sent on every attempt
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty" db:"last_status_code"` // Nullable, unset when unreachable
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`             // Nullable
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty" db:"last_attempt_at"` // Nullable
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`       // Nullable
+	LockedUntil    *time.Time            `json:"-" db:"locked_until"`                            // Nullable, set while a dispatcher holds the delivery
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookPayload is the JSON body merchants receive
+type WebhookPayload struct {
+	ID         int                `json:"id"` // Event ID; the same on every attempt and replay
+	Type       string             `json:"type"`
+	MerchantID int                `json:"merchant_id"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Data       WebhookVoucherSale `json:"data"`
+}
+
+// WebhookVoucherSale is what a sold, refunded or redeemed voucher event tells the merchant
+type WebhookVoucherSale struct {
+	TransactionID  int        `json:"transaction_id"`
+	VoucherID      int        `json:"voucher_id"`
+	VoucherName    string     `json:"voucher_name"`
+	UserID         int        `json:"user_id"`
+	Amount         float64    `json:"amount"`
+	DiscountAmount float64    `json:"discount_amount"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RedeemedAt     *time.Time `json:"redeemed_at,omitempty"` // Set on redeemed events
+}
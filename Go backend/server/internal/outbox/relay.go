@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+const (
+	relayWorkers  = 8         // Events of different aggregates delivered at once
+	pruneInterval = time.Hour // How often delivered events past retention are deleted
+)
+
+// Settings tune the relay
+type Settings struct {
+	PollInterval    time.Duration // How often due events are fetched
+	BatchSize       int           // Events fetched per poll
+	MaxAttempts     int           // Attempts before an event is dead-lettered
+	RetryBase       time.Duration // Delay before the second attempt; doubles with every attempt
+	RetryMax        time.Duration // Longest delay between attempts
+	DeliveryTimeout time.Duration // Timeout of each delivery to a sink
+	Retention       time.Duration // Delivered events are deleted after this; dead-lettered ones are kept
+}
+
+// Relay delivers recorded events to its sinks, at least once each. A sink that accepted an
+// event isn't sent it again when another sink fails. An aggregate's events are delivered in the
+// order they were recorded: while its oldest pending event is retried, the later ones wait.
+// Every server runs a relay; each claims the events it delivers for a lease, so relays sharing a
+// database never work on the same event at once. The events of a relay that stops mid-delivery
+// are picked up by another once their lease runs out.
+type Relay struct {
+	repo     repository.OutboxRepository
+	sinks    []Sink
+	settings Settings
+	done     chan struct{}
+}
+
+// NewRelay creates a relay delivering to sinks
+func NewRelay(repo repository.OutboxRepository, settings Settings, sinks ...Sink) *Relay {
+	return &Relay{repo: repo, sinks: sinks, settings: settings, done: make(chan struct{})}
+}
+
+// Start delivers due events every poll interval until ctx is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		defer close(r.done)
+
+		poll := time.NewTicker(r.settings.PollInterval)
+		defer poll.Stop()
+		prune := time.NewTicker(pruneInterval)
+		defer prune.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+				// Keep going while full batches come back, so a backlog drains without waiting for ticks
+				for {
+					n, err := r.RelayDue(ctx)
+					if err != nil {
+						if ctx.Err() == nil {
+							slog.Error("Outbox relay failed", "error", err)
+						}
+						break
+					}
+					if n < r.settings.BatchSize {
+						break
+					}
+				}
+			case now := <-prune.C:
+				deleted, err := r.repo.DeleteDeliveredBefore(ctx, now.Add(-r.settings.Retention))
+				if err != nil {
+					slog.Error("Outbox prune failed", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					slog.Info("Outbox pruned delivered events", "count", deleted)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until the relay started by Start has stopped
+func (r *Relay) Wait() {
+	<-r.done
+}
+
+// RelayDue makes one delivery attempt for each due event and returns how many were attempted
+func (r *Relay) RelayDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.repo.ClaimDueEvents(ctx, now, now.Add(r.lease()), r.settings.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due outbox events: %w", err)
+	}
+
+	// Due events belong to different aggregates, so they can be delivered concurrently
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, relayWorkers)
+	for _, event := range events {
+		workers <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-workers }()
+			r.deliver(ctx, event)
+		})
+	}
+	wg.Wait()
+	return len(events), ctx.Err()
+}
+
+// deliver sends event to the sinks that haven't accepted it and records the outcome
+func (r *Relay) deliver(ctx context.Context, event *model.OutboxEvent) {
+	msg := newMessage(event)
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if event.DeliveredToSink(sink.Name()) {
+			continue
+		}
+
+		start := time.Now()
+		sinkCtx, cancel := context.WithTimeout(ctx, r.settings.DeliveryTimeout)
+		err := sink.Deliver(sinkCtx, msg)
+		cancel()
+		if ctx.Err() != nil {
+			return // Shutting down; the attempt doesn't count and the event is retried after a restart
+		}
+
+		metrics.ObserveOutboxDelivery(sink.Name(), err == nil, time.Since(start))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		event.DeliveredTo = append(event.DeliveredTo, sink.Name())
+	}
+
+	now := time.Now()
+	event.Attempts++
+	switch {
+	case len(errs) == 0:
+		event.Status = model.OutboxStatusDelivered
+		event.LastError = nil
+		event.ProcessedAt = &now
+	case event.Attempts >= r.settings.MaxAttempts:
+		event.Status = model.OutboxStatusDeadLettered
+		event.ProcessedAt = &now
+		metrics.RecordOutboxDeadLetter(event.EventType)
+	default:
+		event.NextAttemptAt = now.Add(r.backoff(event.Attempts))
+	}
+	if len(errs) > 0 {
+		lastError := errors.Join(errs...).Error()
+		event.LastError = &lastError
+	}
+
+	if err := r.repo.SaveDelivery(ctx, event); err != nil {
+		// The event stays as it was and is delivered again; sinks that accepted it see it twice
+		slog.Error("Failed to save outbox delivery", "event_id", event.ID, "error", err)
+		return
+	}
+
+	switch event.Status {
+	case model.OutboxStatusDeadLettered:
+		slog.Error("Outbox event dead-lettered", "event_id", event.ID, "event_type", event.EventType, "attempts", event.Attempts, "error", *event.LastError)
+	case model.OutboxStatusPending:
+		slog.Warn("Outbox delivery failed, retrying", "event_id", event.ID, "event_type", event.EventType, "attempt", event.Attempts, "retry_at", event.NextAttemptAt, "error", *event.LastError)
+	}
+}
+
+// lease returns how long claimed events are held: long enough to wait for every batch worker
+// and for each sink to time out, with one more timeout to spare for saving the outcome
+func (r *Relay) lease() time.Duration {
+	rounds := (r.settings.BatchSize + relayWorkers - 1) / relayWorkers
+	return time.Duration(rounds*(len(r.sinks)+1)) * r.settings.DeliveryTimeout
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.settings.RetryBase
+	for i := 1; i < attempts && delay < r.settings.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, r.settings.RetryMax)
+}
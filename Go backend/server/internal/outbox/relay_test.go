@@ -0,0 +1,275 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/memory"
+)
+
+func TestRelayBackoff(t *testing.T) {
+	settings := Settings{RetryBase: time.Second, RetryMax: time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{8, time.Minute},
+		{1000, time.Minute},
+	}
+
+	relay := NewRelay(nil, settings)
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRelayBackoffBaseAboveMax(t *testing.T) {
+	relay := NewRelay(nil, Settings{RetryBase: time.Hour, RetryMax: time.Minute})
+	for _, attempts := range []int{1, 2, 10} {
+		if got := relay.backoff(attempts); got != time.Minute {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, time.Minute)
+		}
+	}
+}
+
+func TestRelayLease(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		sinks     int
+		want      time.Duration
+	}{
+		{name: "one round, one sink", batchSize: 1, sinks: 1, want: 2 * time.Second},
+		{name: "full round of workers", batchSize: relayWorkers, sinks: 1, want: 2 * time.Second},
+		{name: "one more than the workers", batchSize: relayWorkers + 1, sinks: 1, want: 4 * time.Second},
+		{name: "every sink may time out", batchSize: 100, sinks: 2, want: 39 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sinks := make([]Sink, tt.sinks)
+			relay := NewRelay(nil, Settings{BatchSize: tt.batchSize, DeliveryTimeout: time.Second}, sinks...)
+			if got := relay.lease(); got != tt.want {
+				t.Errorf("lease() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testSink fails its first failures deliveries (every delivery if negative) and records the
+// events it accepted
+type testSink struct {
+	name     string
+	failures int
+
+	mu       sync.Mutex
+	attempts int
+	accepted []int
+}
+
+func (s *testSink) Name() string {
+	return s.name
+}
+
+func (s *testSink) Deliver(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.failures < 0 || s.attempts <= s.failures {
+		return errors.New("unavailable")
+	}
+	s.accepted = append(s.accepted, msg.ID)
+	return nil
+}
+
+// savedRepo keeps the last saved state of every event
+type savedRepo struct {
+	repository.OutboxRepository
+	saved map[int]model.OutboxEvent
+}
+
+func (r *savedRepo) SaveDelivery(ctx context.Context, event *model.OutboxEvent) error {
+	r.saved[event.ID] = *event
+	return r.OutboxRepository.SaveDelivery(ctx, event)
+}
+
+func TestRelayDue(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     []int // Of each sink
+		rounds       int
+		wantStatus   model.OutboxStatus
+		wantAttempts int
+		wantAccepted []int // Deliveries each sink accepted
+		wantError    string
+	}{
+		{
+			name:         "every sink accepts",
+			failures:     []int{0, 0},
+			rounds:       1,
+			wantStatus:   model.OutboxStatusDelivered,
+			wantAttempts: 1,
+			wantAccepted: []int{1, 1},
+		},
+		{
+			name:         "failed sink is retried alone",
+			failures:     []int{0, 1},
+			rounds:       1,
+			wantStatus:   model.OutboxStatusPending,
+			wantAttempts: 1,
+			wantAccepted: []int{1, 0},
+			wantError:    "sink-1: unavailable",
+		},
+		{
+			name:         "retry delivers to the failed sink only",
+			failures:     []int{0, 1},
+			rounds:       2,
+			wantStatus:   model.OutboxStatusDelivered,
+			wantAttempts: 2,
+			wantAccepted: []int{1, 1},
+		},
+		{
+			name:         "dead-lettered after max attempts",
+			failures:     []int{-1, 0},
+			rounds:       3,
+			wantStatus:   model.OutboxStatusDeadLettered,
+			wantAttempts: 3,
+			wantAccepted: []int{0, 1},
+			wantError:    "sink-0: unavailable",
+		},
+		{
+			name:         "dead-lettered events are not retried",
+			failures:     []int{-1},
+			rounds:       5,
+			wantStatus:   model.OutboxStatusDeadLettered,
+			wantAttempts: 3,
+			wantAccepted: []int{0},
+			wantError:    "sink-0: unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &savedRepo{OutboxRepository: memory.NewOutboxRepository(memory.NewStore()), saved: make(map[int]model.OutboxEvent)}
+
+			sinks := make([]Sink, len(tt.failures))
+			for i, failures := range tt.failures {
+				sinks[i] = &testSink{name: "sink-" + strconv.Itoa(i), failures: failures}
+			}
+			// A zero retry delay makes failed events due again right away
+			relay := NewRelay(repo, Settings{BatchSize: 10, MaxAttempts: 3, DeliveryTimeout: time.Second}, sinks...)
+
+			event := &model.OutboxEvent{AggregateType: "transaction", AggregateID: "1", EventType: model.EventVoucherPurchased, Payload: []byte(`{}`)}
+			if err := repo.AddEvent(ctx, nil, event); err != nil {
+				t.Fatalf("AddEvent() error = %v", err)
+			}
+
+			for i := 0; i < tt.rounds; i++ {
+				if _, err := relay.RelayDue(ctx); err != nil {
+					t.Fatalf("round %d: RelayDue() error = %v", i+1, err)
+				}
+			}
+
+			saved := repo.saved[event.ID]
+			if saved.Status != tt.wantStatus || saved.Attempts != tt.wantAttempts {
+				t.Errorf("event = %s after %d attempts, want %s after %d", saved.Status, saved.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			for i, sink := range sinks {
+				if got := len(sink.(*testSink).accepted); got != tt.wantAccepted[i] {
+					t.Errorf("%s accepted %d deliveries, want %d", sink.Name(), got, tt.wantAccepted[i])
+				}
+			}
+
+			var lastError string
+			if saved.LastError != nil {
+				lastError = *saved.LastError
+			}
+			if lastError != tt.wantError {
+				t.Errorf("last error = %q, want %q", lastError, tt.wantError)
+			}
+			if saved.Status != model.OutboxStatusPending && saved.ProcessedAt == nil {
+				t.Errorf("processed_at not set on %s event", saved.Status)
+			}
+		})
+	}
+}
+
+func TestRelayDueOrdersAggregateEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewOutboxRepository(memory.NewStore())
+	sink := &testSink{name: "sink"}
+	relay := NewRelay(repo, Settings{BatchSize: 10, MaxAttempts: 3, DeliveryTimeout: time.Second}, sink)
+
+	for _, aggregate := range []string{"1", "2", "1", "1"} {
+		event := &model.OutboxEvent{AggregateType: "transaction", AggregateID: aggregate, EventType: model.EventVoucherPurchased, Payload: []byte(`{}`)}
+		if err := repo.AddEvent(ctx, nil, event); err != nil {
+			t.Fatalf("AddEvent() error = %v", err)
+		}
+	}
+
+	// Each round delivers only the oldest pending event of each aggregate
+	wantRounds := [][]int{{1, 2}, {3}, {4}, nil}
+	for i, want := range wantRounds {
+		sink.accepted = nil
+		n, err := relay.RelayDue(ctx)
+		if err != nil {
+			t.Fatalf("round %d: RelayDue() error = %v", i+1, err)
+		}
+
+		slices.Sort(sink.accepted)
+		if n != len(want) || !slices.Equal(sink.accepted, want) {
+			t.Errorf("round %d: delivered %v (%d attempted), want %v", i+1, sink.accepted, n, want)
+		}
+	}
+}
+
+func TestClaimDueEventsLease(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewOutboxRepository(memory.NewStore())
+
+	event := &model.OutboxEvent{AggregateType: "transaction", AggregateID: "1", EventType: model.EventVoucherPurchased, Payload: []byte(`{}`)}
+	if err := repo.AddEvent(ctx, nil, event); err != nil {
+		t.Fatalf("AddEvent() error = %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int
+	}{
+		{name: "first relay claims", at: now, want: 1},
+		{name: "second relay skips the held event", at: now.Add(30 * time.Second), want: 0},
+		{name: "expired lease is claimed again", at: now.Add(time.Minute), want: 1},
+	}
+
+	// The cases claim in turn, each holding the event for a minute
+	for _, tt := range tests {
+		events, err := repo.ClaimDueEvents(ctx, tt.at, tt.at.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("%s: ClaimDueEvents() error = %v", tt.name, err)
+		}
+		if len(events) != tt.want {
+			t.Errorf("%s: claimed %d events, want %d", tt.name, len(events), tt.want)
+		}
+	}
+}
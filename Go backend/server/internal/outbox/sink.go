@@ -0,0 +1,47 @@
+// Package outbox delivers the domain events that services record in the outbox table.
+// Events are written in the same database transaction as the change they describe, so an
+// event exists exactly when its change was committed; the Relay then delivers it at least once
+// to every Sink, retrying with backoff and dead-lettering events that keep failing.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+// Message is an outbox event as sinks see it. Its JSON form is what the webhook sink posts
+// and the file sink writes. Delivery is at least once, so consumers dedupe by ID.
+type Message struct {
+	ID            int             `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempt       int             `json:"attempt"` // 1 on the first delivery
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// newMessage returns the message of event's next delivery attempt
+func newMessage(event *model.OutboxEvent) Message {
+	return Message{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt,
+		Attempt:       event.Attempts + 1,
+		Payload:       event.Payload,
+	}
+}
+
+// Sink receives outbox events. Deliver returns nil once the event is stored or handled;
+// any error has the relay retry it later.
+type Sink interface {
+	// Name identifies the sink in the outbox table, which records the sinks that accepted
+	// each event; it must stay the same across restarts
+	Name() string
+	Deliver(ctx context.Context, msg Message) error
+}
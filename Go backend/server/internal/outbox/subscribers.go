@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AllEvents subscribes a handler to every event type
+const AllEvents = "*"
+
+// Handler handles an event in process. An error has the event retried, and handlers of the
+// same event that succeeded run again too, so handlers must be idempotent.
+type Handler func(ctx context.Context, msg Message) error
+
+// Subscribers is the sink of in-process consumers: it hands each event to the handlers
+// subscribed to its type. Unlike the event bus, handlers only see committed events, get them
+// at least once, and get retried when they fail.
+type Subscribers struct {
+	mu       sync.RWMutex
+	handlers map[string][]namedHandler // By event type
+}
+
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// NewSubscribers creates a sink without subscribers
+func NewSubscribers() *Subscribers {
+	return &Subscribers{handlers: make(map[string][]namedHandler)}
+}
+
+// Subscribe has handler receive events of eventType, or every event with AllEvents.
+// name identifies the handler in delivery errors.
+func (s *Subscribers) Subscribe(eventType, name string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[eventType] = append(s.handlers[eventType], namedHandler{name: name, handler: handler})
+}
+
+// Name implements Sink
+func (s *Subscribers) Name() string {
+	return "in_process"
+}
+
+// Deliver implements Sink; every handler runs even when an earlier one fails
+func (s *Subscribers) Deliver(ctx context.Context, msg Message) error {
+	s.mu.RLock()
+	handlers := append(append([]namedHandler(nil), s.handlers[msg.Type]...), s.handlers[AllEvents]...)
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.handler(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// maxErrorBody is how much of a failed webhook response is kept in the error
+const maxErrorBody = 512
+
+// WebhookSink POSTs each event as JSON to a URL. Any 2xx response accepts the event.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink posting to url; the relay bounds each request with its delivery timeout
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{}}
+}
+
+// Name implements Sink
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Deliver implements Sink
+func (s *WebhookSink) Deliver(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Receivers can dedupe and route without parsing the body
+	req.Header.Set("X-Event-ID", strconv.Itoa(msg.ID))
+	req.Header.Set("X-Event-Type", msg.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook answered %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) // Lets the connection be reused
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+type MerchantRepository struct {
+	store *Store
+}
+
+// NewMerchantRepository creates a new in-memory merchant repository
+func NewMerchantRepository(store *Store) *MerchantRepository {
+	return &MerchantRepository{store: store}
+}
+
+// CreateMerchant creates a new merchant; names are unique
+func (r *MerchantRepository) CreateMerchant(ctx context.Context, merchant *model.Merchant) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		for _, m := range t.merchants {
+			if m.Name == merchant.Name {
+				return errors.New("merchant already exists")
+			}
+		}
+
+		now := time.Now()
+		merchant.ID = r.store.nextID("merchants")
+		merchant.CreatedAt = now
+		merchant.UpdatedAt = now
+		t.merchants[merchant.ID] = *merchant
+		return nil
+	})
+}
+
+// GetMerchantByID retrieves a merchant by ID
+func (r *MerchantRepository) GetMerchantByID(ctx context.Context, id int) (*model.Merchant, error) {
+	var merchant *model.Merchant
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if m, ok := t.merchants[id]; ok {
+			merchant = &m
+		}
+		return nil
+	})
+	return merchant, err
+}
+
+// GetSalesByMerchantID retrieves successful purchases of a merchant's vouchers, newest first
+func (r *MerchantRepository) GetSalesByMerchantID(ctx context.Context, merchantID int) ([]*model.MerchantSale, error) {
+	var sales []*model.MerchantSale
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, txn := range t.transactions {
+			if txn.VoucherID == nil || txn.TransactionType != model.TransactionTypePurchase || txn.PaymentStatus != model.PaymentStatusSuccess {
+				continue
+			}
+			voucher, ok := t.vouchers[*txn.VoucherID]
+			if !ok || voucher.MerchantID == nil || *voucher.MerchantID != merchantID {
+				continue
+			}
+			sales = append(sales, &model.MerchantSale{
+				TransactionID:  txn.ID,
+				VoucherID:      voucher.ID,
+				VoucherName:    voucher.Name,
+				UserID:         txn.UserID,
+				Amount:         txn.Amount,
+				DiscountAmount: txn.DiscountAmount,
+				CreatedAt:      txn.CreatedAt,
+			})
+		}
+		return nil
+	})
+
+	slices.SortFunc(sales, func(a, b *model.MerchantSale) int {
+		return newestFirst(a.CreatedAt, a.TransactionID, b.CreatedAt, b.TransactionID)
+	})
+	return sales, err
+}
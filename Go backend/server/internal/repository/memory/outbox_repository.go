@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type OutboxRepository struct {
+	store *Store
+}
+
+// NewOutboxRepository creates a new in-memory outbox repository
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+// cloneOutboxEvent copies the event's slices so the store and callers never share them
+func cloneOutboxEvent(event model.OutboxEvent) model.OutboxEvent {
+	event.Payload = slices.Clone(event.Payload)
+	event.DeliveredTo = slices.Clone(event.DeliveredTo)
+	return event
+}
+
+// AddEvent records a pending event (used in transactions)
+func (r *OutboxRepository) AddEvent(ctx context.Context, tx repository.Tx, event *model.OutboxEvent) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		now := time.Now()
+		event.ID = r.store.nextID("outbox")
+		event.Status = model.OutboxStatusPending
+		event.Attempts = 0
+		event.DeliveredTo = []string{}
+		event.NextAttemptAt = now
+		event.CreatedAt = now
+		t.outbox[event.ID] = cloneOutboxEvent(*event)
+		return nil
+	})
+}
+
+// ClaimDueEvents locks the oldest pending event of each aggregate until lockedUntil, if it is due
+// and no relay holds it
+func (r *OutboxRepository) ClaimDueEvents(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		type aggregate struct{ typ, id string }
+		heads := make(map[aggregate]model.OutboxEvent)
+		for _, event := range t.outbox {
+			if event.Status != model.OutboxStatusPending {
+				continue
+			}
+			key := aggregate{event.AggregateType, event.AggregateID}
+			if head, ok := heads[key]; !ok || event.ID < head.ID {
+				heads[key] = event
+			}
+		}
+
+		for _, head := range heads {
+			if !head.NextAttemptAt.After(now) && (head.LockedUntil == nil || !head.LockedUntil.After(now)) {
+				events = append(events, &head)
+			}
+		}
+		slices.SortFunc(events, func(a, b *model.OutboxEvent) int { return a.ID - b.ID })
+		if len(events) > limit {
+			events = events[:limit]
+		}
+
+		for i, event := range events {
+			event.LockedUntil = &lockedUntil
+			t.outbox[event.ID] = cloneOutboxEvent(*event)
+			claimed := cloneOutboxEvent(*event)
+			events[i] = &claimed
+		}
+		return nil
+	})
+	return events, err
+}
+
+// SaveDelivery stores the delivery state of an event
+func (r *OutboxRepository) SaveDelivery(ctx context.Context, event *model.OutboxEvent) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		stored, ok := t.outbox[event.ID]
+		if !ok {
+			return nil
+		}
+		if event.Attempts < 0 {
+			return checkViolation("outbox", "outbox_attempts_check")
+		}
+		stored.Status = event.Status
+		stored.Attempts = event.Attempts
+		stored.DeliveredTo = event.DeliveredTo
+		stored.LastError = event.LastError
+		stored.NextAttemptAt = event.NextAttemptAt
+		stored.ProcessedAt = event.ProcessedAt
+		stored.LockedUntil = nil
+		t.outbox[event.ID] = cloneOutboxEvent(stored)
+		return nil
+	})
+}
+
+// DeleteDeliveredBefore removes events delivered before the given time
+func (r *OutboxRepository) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		for id, event := range t.outbox {
+			if event.Status == model.OutboxStatusDelivered && event.ProcessedAt != nil && event.ProcessedAt.Before(before) {
+				delete(t.outbox, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
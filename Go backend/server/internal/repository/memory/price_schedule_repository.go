@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type PriceScheduleRepository struct {
+	store *Store
+}
+
+// NewPriceScheduleRepository creates a new in-memory price schedule repository
+func NewPriceScheduleRepository(store *Store) *PriceScheduleRepository {
+	return &PriceScheduleRepository{store: store}
+}
+
+// CreateSchedule creates a new price schedule
+func (r *PriceScheduleRepository) CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		if _, ok := t.vouchers[schedule.VoucherID]; !ok {
+			return errors.New("voucher not found")
+		}
+
+		now := time.Now()
+		schedule.ID = r.store.nextID("voucher_price_schedules")
+		schedule.SoldCount = 0
+		schedule.IsCancelled = false
+		schedule.CreatedAt = now
+		schedule.UpdatedAt = now
+		t.schedules[schedule.ID] = *schedule
+		return nil
+	})
+}
+
+// GetScheduleByID retrieves a price schedule by ID
+func (r *PriceScheduleRepository) GetScheduleByID(ctx context.Context, id int) (*model.PriceSchedule, error) {
+	var schedule *model.PriceSchedule
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if s, ok := t.schedules[id]; ok {
+			schedule = &s
+		}
+		return nil
+	})
+	return schedule, err
+}
+
+// HasOverlappingSchedule checks if a voucher already has a non-cancelled schedule overlapping the window
+func (r *PriceScheduleRepository) HasOverlappingSchedule(ctx context.Context, voucherID int, startsAt, endsAt time.Time) (bool, error) {
+	var exists bool
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, s := range t.schedules {
+			if s.VoucherID == voucherID && !s.IsCancelled && s.StartsAt.Before(endsAt) && s.EndsAt.After(startsAt) {
+				exists = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+// CancelSchedule marks a price schedule as cancelled
+func (r *PriceScheduleRepository) CancelSchedule(ctx context.Context, id int) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		s, ok := t.schedules[id]
+		if !ok {
+			return nil
+		}
+		s.IsCancelled = true
+		s.UpdatedAt = time.Now()
+		t.schedules[id] = s
+		return nil
+	})
+}
+
+// IncrementSoldCount records a unit sold at the sale price (used in transactions)
+func (r *PriceScheduleRepository) IncrementSoldCount(ctx context.Context, tx repository.Tx, id int) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		s, ok := t.schedules[id]
+		if !ok || (s.StockCap != nil && s.SoldCount >= *s.StockCap) {
+			return errors.New("flash sale sold out")
+		}
+		s.SoldCount++
+		s.UpdatedAt = time.Now()
+		t.schedules[id] = s
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type PromotionRepository struct {
+	store *Store
+}
+
+// NewPromotionRepository creates a new in-memory promotion repository
+func NewPromotionRepository(store *Store) *PromotionRepository {
+	return &PromotionRepository{store: store}
+}
+
+// CreatePromotion creates a new promotion; codes are unique
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, promotion *model.Promotion) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		for _, p := range t.promotions {
+			if p.Code == promotion.Code {
+				return errors.New("promo code already exists")
+			}
+		}
+
+		now := time.Now()
+		promotion.ID = r.store.nextID("promotions")
+		promotion.TimesUsed = 0
+		promotion.CreatedAt = now
+		promotion.UpdatedAt = now
+		t.promotions[promotion.ID] = *promotion
+		return nil
+	})
+}
+
+// GetPromotionByID retrieves a promotion by ID
+func (r *PromotionRepository) GetPromotionByID(ctx context.Context, id int) (*model.Promotion, error) {
+	var promotion *model.Promotion
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if p, ok := t.promotions[id]; ok {
+			promotion = &p
+		}
+		return nil
+	})
+	return promotion, err
+}
+
+// GetPromotionByCodeForUpdate retrieves a promotion by code inside a transaction.
+// The transaction holds the store's writer slot, so usage counts cannot change until it ends.
+func (r *PromotionRepository) GetPromotionByCodeForUpdate(ctx context.Context, tx repository.Tx, code string) (*model.Promotion, error) {
+	var promotion *model.Promotion
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		for _, p := range t.promotions {
+			if p.Code == code {
+				promotion = &p
+				return nil
+			}
+		}
+		return nil
+	})
+	return promotion, err
+}
+
+// ListPromotions retrieves all promotions, newest first
+func (r *PromotionRepository) ListPromotions(ctx context.Context) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, p := range t.promotions {
+			promotions = append(promotions, &p)
+		}
+		return nil
+	})
+
+	slices.SortFunc(promotions, func(a, b *model.Promotion) int {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return promotions, err
+}
+
+// SetPromotionActive enables or disables a promotion
+func (r *PromotionRepository) SetPromotionActive(ctx context.Context, id int, active bool) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		p, ok := t.promotions[id]
+		if !ok {
+			return nil
+		}
+		p.IsActive = active
+		p.UpdatedAt = time.Now()
+		t.promotions[id] = p
+		return nil
+	})
+}
+
+// CountUserRedemptions counts how many times a user has redeemed a promotion
+func (r *PromotionRepository) CountUserRedemptions(ctx context.Context, tx repository.Tx, promotionID, userID int) (int, error) {
+	var count int
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		for _, red := range t.redemptions {
+			if red.PromotionID == promotionID && red.UserID == userID {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// CreateRedemption records a redemption and increments the promotion usage counter
+func (r *PromotionRepository) CreateRedemption(ctx context.Context, tx repository.Tx, redemption *model.PromotionRedemption) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		p, ok := t.promotions[redemption.PromotionID]
+		if !ok {
+			return errors.New("promotion not found")
+		}
+
+		now := time.Now()
+		redemption.ID = r.store.nextID("promotion_redemptions")
+		redemption.CreatedAt = now
+		t.redemptions[redemption.ID] = *redemption
+
+		p.TimesUsed++
+		p.UpdatedAt = now
+		t.promotions[p.ID] = p
+		return nil
+	})
+}
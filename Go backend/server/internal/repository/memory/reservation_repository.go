@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type ReservationRepository struct {
+	store *Store
+}
+
+// NewReservationRepository creates a new in-memory reservation repository
+func NewReservationRepository(store *Store) *ReservationRepository {
+	return &ReservationRepository{store: store}
+}
+
+// CreateReservation creates a new active reservation (used in transactions)
+func (r *ReservationRepository) CreateReservation(ctx context.Context, tx repository.Tx, reservation *model.Reservation) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		if reservation.Quantity < 0 {
+			return checkViolation("voucher_reservations", "voucher_reservations_quantity_check")
+		}
+
+		now := time.Now()
+		reservation.ID = r.store.nextID("voucher_reservations")
+		reservation.CreatedAt = now
+		reservation.UpdatedAt = now
+		t.reservations[reservation.ID] = *reservation
+		return nil
+	})
+}
+
+// GetReservationByID retrieves a reservation by ID
+func (r *ReservationRepository) GetReservationByID(ctx context.Context, id int) (*model.Reservation, error) {
+	return r.getReservation(ctx, nil, id)
+}
+
+// GetReservationByIDForUpdate retrieves a reservation by ID inside a transaction.
+// The transaction holds the store's writer slot, so the reservation cannot change until it ends.
+func (r *ReservationRepository) GetReservationByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Reservation, error) {
+	return r.getReservation(ctx, tx, id)
+}
+
+func (r *ReservationRepository) getReservation(ctx context.Context, tx repository.Tx, id int) (*model.Reservation, error) {
+	var reservation *model.Reservation
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		if res, ok := t.reservations[id]; ok {
+			reservation = &res
+		}
+		return nil
+	})
+	return reservation, err
+}
+
+// GetActiveReservationForUpdate retrieves the user's oldest unexpired hold on a voucher inside a transaction
+func (r *ReservationRepository) GetActiveReservationForUpdate(ctx context.Context, tx repository.Tx, userID, voucherID int) (*model.Reservation, error) {
+	var reservation *model.Reservation
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		now := time.Now()
+		for _, res := range t.reservations {
+			if res.UserID != userID || res.VoucherID != voucherID || res.Status != model.ReservationStatusActive || !res.ExpiresAt.After(now) {
+				continue
+			}
+			if reservation == nil || oldestFirst(res.CreatedAt, res.ID, reservation.CreatedAt, reservation.ID) < 0 {
+				reservation = &res
+			}
+		}
+		return nil
+	})
+	return reservation, err
+}
+
+// SumActiveHolds returns the units of a voucher held by unexpired reservations
+func (r *ReservationRepository) SumActiveHolds(ctx context.Context, tx repository.Tx, voucherID int) (int, error) {
+	var held int
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		held = t.heldUnits(voucherID, time.Now())
+		return nil
+	})
+	return held, err
+}
+
+// UpdateReservation updates the held quantity and status of a reservation (used in transactions)
+func (r *ReservationRepository) UpdateReservation(ctx context.Context, tx repository.Tx, id, quantity int, status model.ReservationStatus) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		res, ok := t.reservations[id]
+		if !ok {
+			return nil
+		}
+		if quantity < 0 {
+			return checkViolation("voucher_reservations", "voucher_reservations_quantity_check")
+		}
+		res.Quantity = quantity
+		res.Status = status
+		res.UpdatedAt = time.Now()
+		t.reservations[id] = res
+		return nil
+	})
+}
+
+// ExpireReservations marks active holds past their expiry as expired and returns how many were released
+func (r *ReservationRepository) ExpireReservations(ctx context.Context, now time.Time) (int64, error) {
+	var expired int64
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		for id, res := range t.reservations {
+			if res.Status == model.ReservationStatusActive && !res.ExpiresAt.After(now) {
+				res.Status = model.ReservationStatusExpired
+				res.UpdatedAt = now
+				t.reservations[id] = res
+				expired++
+			}
+		}
+		return nil
+	})
+	return expired, err
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+// Demo accounts created in every new in-memory store. There is no sign-up RPC,
+// so without them nobody could log in.
+const (
+	DemoPassword      = "password"
+	DemoAdminEmail    = "admin@example.com"
+	DemoCustomerEmail = "customer@example.com"
+	demoWalletBalance = 1000
+)
+
+// seed adds an admin, a customer with a funded wallet and a voucher to buy
+func (s *Store) seed() {
+	now := time.Now()
+
+	for _, u := range []model.User{
+		{Name: "Demo Admin", Email: DemoAdminEmail, Role: model.UserRoleAdmin},
+		{Name: "Demo Customer", Email: DemoCustomerEmail, Role: model.UserRoleCustomer},
+	} {
+		u.ID = s.nextID("users")
+		u.Password = DemoPassword
+		u.CreatedAt = now
+		u.UpdatedAt = now
+		s.data.users[u.ID] = u
+
+		s.data.wallets[u.ID] = model.Wallet{
+			ID:        s.nextID("wallets"),
+			UserID:    u.ID,
+			Balance:   demoWalletBalance,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	voucherID := s.nextID("vouchers")
+	s.data.vouchers[voucherID] = model.Voucher{
+		ID:           voucherID,
+		Name:         "Demo Coffee Voucher",
+		Description:  "One free coffee",
+		Category:     "food",
+		RegularPrice: 150,
+		Quantity:     100,
+		ValidFrom:    now,
+		ValidTo:      now.AddDate(1, 0, 0),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type SettlementRepository struct {
+	store *Store
+}
+
+// NewSettlementRepository creates a new in-memory settlement repository
+func NewSettlementRepository(store *Store) *SettlementRepository {
+	return &SettlementRepository{store: store}
+}
+
+// GetLatestPeriodEnd returns the end of the merchant's most recent batch, or nil if none exist (used in transactions)
+func (r *SettlementRepository) GetLatestPeriodEnd(ctx context.Context, tx repository.Tx, merchantID int) (*time.Time, error) {
+	var periodEnd *time.Time
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		for _, b := range t.batches {
+			if b.MerchantID == merchantID && (periodEnd == nil || b.PeriodEnd.After(*periodEnd)) {
+				end := b.PeriodEnd
+				periodEnd = &end
+			}
+		}
+		return nil
+	})
+	return periodEnd, err
+}
+
+// GetUnsettledTransactions retrieves successful purchases and refunds of the merchant's vouchers
+// created before periodEnd that are not in any batch yet (used in transactions).
+// Amounts are returned unsigned; the caller applies commission and signs refunds.
+func (r *SettlementRepository) GetUnsettledTransactions(ctx context.Context, tx repository.Tx, merchantID int, periodEnd time.Time) ([]*model.SettlementLineItem, error) {
+	var items []*model.SettlementLineItem
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		settled := make(map[int]bool, len(t.lineItems))
+		for _, item := range t.lineItems {
+			settled[item.TransactionID] = true
+		}
+
+		for _, txn := range t.transactions {
+			if txn.VoucherID == nil || settled[txn.ID] || txn.PaymentStatus != model.PaymentStatusSuccess || !txn.CreatedAt.Before(periodEnd) {
+				continue
+			}
+			if txn.TransactionType != model.TransactionTypePurchase && txn.TransactionType != model.TransactionTypeRefund {
+				continue
+			}
+			voucher, ok := t.vouchers[*txn.VoucherID]
+			if !ok || voucher.MerchantID == nil || *voucher.MerchantID != merchantID {
+				continue
+			}
+			items = append(items, &model.SettlementLineItem{
+				TransactionID:   txn.ID,
+				VoucherID:       voucher.ID,
+				VoucherName:     voucher.Name,
+				TransactionType: txn.TransactionType,
+				Amount:          txn.Amount,
+				TransactionAt:   txn.CreatedAt,
+			})
+		}
+		return nil
+	})
+
+	sortLineItems(items)
+	return items, err
+}
+
+// CreateBatch creates an open settlement batch with its line items (used in transactions)
+func (r *SettlementRepository) CreateBatch(ctx context.Context, tx repository.Tx, batch *model.SettlementBatch) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		for _, b := range t.batches {
+			if b.MerchantID == batch.MerchantID && b.Status == model.SettlementStatusOpen {
+				return errors.New("settlement batch already open")
+			}
+		}
+
+		settled := make(map[int]bool, len(t.lineItems))
+		for _, item := range t.lineItems {
+			settled[item.TransactionID] = true
+		}
+		for _, item := range batch.Items {
+			if settled[item.TransactionID] {
+				return errors.New("transaction already settled")
+			}
+			settled[item.TransactionID] = true
+		}
+
+		now := time.Now()
+		batch.ID = r.store.nextID("settlement_batches")
+		batch.CreatedAt = now
+		batch.UpdatedAt = now
+
+		stored := *batch
+		stored.Items = nil
+		t.batches[batch.ID] = stored
+
+		for _, item := range batch.Items {
+			item.ID = r.store.nextID("settlement_line_items")
+			item.BatchID = batch.ID
+			t.lineItems[item.ID] = *item
+		}
+		return nil
+	})
+}
+
+// GetBatchByID retrieves a settlement batch by ID without its line items
+func (r *SettlementRepository) GetBatchByID(ctx context.Context, id int) (*model.SettlementBatch, error) {
+	var batch *model.SettlementBatch
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if b, ok := t.batches[id]; ok {
+			batch = &b
+		}
+		return nil
+	})
+	return batch, err
+}
+
+// GetLineItems retrieves the line items of a batch in transaction order
+func (r *SettlementRepository) GetLineItems(ctx context.Context, batchID int) ([]*model.SettlementLineItem, error) {
+	var items []*model.SettlementLineItem
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, item := range t.lineItems {
+			if item.BatchID == batchID {
+				items = append(items, &item)
+			}
+		}
+		return nil
+	})
+
+	sortLineItems(items)
+	return items, err
+}
+
+// sortLineItems orders line items by transaction time
+func sortLineItems(items []*model.SettlementLineItem) {
+	slices.SortFunc(items, func(a, b *model.SettlementLineItem) int {
+		return oldestFirst(a.TransactionAt, a.TransactionID, b.TransactionAt, b.TransactionID)
+	})
+}
+
+// ListBatches retrieves settlement batches, newest period first.
+// A nil merchantID lists batches of all merchants.
+func (r *SettlementRepository) ListBatches(ctx context.Context, merchantID *int) ([]*model.SettlementBatch, error) {
+	var batches []*model.SettlementBatch
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, b := range t.batches {
+			if merchantID == nil || b.MerchantID == *merchantID {
+				batches = append(batches, &b)
+			}
+		}
+		return nil
+	})
+
+	slices.SortFunc(batches, func(a, b *model.SettlementBatch) int {
+		return newestFirst(a.PeriodEnd, a.ID, b.PeriodEnd, b.ID)
+	})
+	return batches, err
+}
+
+// CloseBatch marks an open batch as closed; returns false if the batch was not open
+func (r *SettlementRepository) CloseBatch(ctx context.Context, id int, closedAt time.Time) (bool, error) {
+	var closed bool
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		b, ok := t.batches[id]
+		if !ok || b.Status != model.SettlementStatusOpen {
+			return nil
+		}
+		b.Status = model.SettlementStatusClosed
+		b.ClosedAt = &closedAt
+		b.UpdatedAt = closedAt
+		t.batches[id] = b
+		closed = true
+		return nil
+	})
+	return closed, err
+}
+
+// DeleteOpenBatch deletes an open batch and its line items; returns false if the batch was not open
+func (r *SettlementRepository) DeleteOpenBatch(ctx context.Context, id int) (bool, error) {
+	var deleted bool
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		b, ok := t.batches[id]
+		if !ok || b.Status != model.SettlementStatusOpen {
+			return nil
+		}
+		for itemID, item := range t.lineItems {
+			if item.BatchID == id {
+				delete(t.lineItems, itemID)
+			}
+		}
+		delete(t.batches, id)
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
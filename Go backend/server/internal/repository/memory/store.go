@@ -0,0 +1,239 @@
+// Package memory implements the repositories in process memory.
+// Nothing is persisted; it lets the server run without a database for demos and end-to-end tests.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+// New creates in-memory repositories sharing one store, seeded with demo data
+func New() *repository.Repositories {
+	store := NewStore()
+	store.seed()
+
+	return &repository.Repositories{
+		Tx:             store,
+		Users:          NewUserRepository(store),
+		Vouchers:       NewVoucherRepository(store),
+		Wallets:        NewWalletRepository(store),
+		Transactions:   NewTransactionRepository(store),
+		Promotions:     NewPromotionRepository(store),
+		PriceSchedules: NewPriceScheduleRepository(store),
+		Reservations:   NewReservationRepository(store),
+		Merchants:      NewMerchantRepository(store),
+		Settlements:    NewSettlementRepository(store),
+		Outbox:         NewOutboxRepository(store),
+		Webhooks:       NewWebhookRepository(store),
+	}
+}
+
+// tables holds every row by ID. Rows are stored by value so callers never share memory with the store.
+type tables struct {
+	users        map[int]model.User
+	wallets      map[int]model.Wallet // Keyed by user ID
+	vouchers     map[int]model.Voucher
+	transactions map[int]model.Transaction
+	promotions   map[int]model.Promotion
+	redemptions  map[int]model.PromotionRedemption
+	schedules    map[int]model.PriceSchedule
+	reservations map[int]model.Reservation
+	merchants    map[int]model.Merchant
+	batches      map[int]model.SettlementBatch
+	lineItems    map[int]model.SettlementLineItem
+	outbox       map[int]model.OutboxEvent
+	endpoints    map[int]model.WebhookEndpoint
+	deliveries   map[int]model.WebhookDelivery
+}
+
+func newTables() *tables {
+	return &tables{
+		users:        make(map[int]model.User),
+		wallets:      make(map[int]model.Wallet),
+		vouchers:     make(map[int]model.Voucher),
+		transactions: make(map[int]model.Transaction),
+		promotions:   make(map[int]model.Promotion),
+		redemptions:  make(map[int]model.PromotionRedemption),
+		schedules:    make(map[int]model.PriceSchedule),
+		reservations: make(map[int]model.Reservation),
+		merchants:    make(map[int]model.Merchant),
+		batches:      make(map[int]model.SettlementBatch),
+		lineItems:    make(map[int]model.SettlementLineItem),
+		outbox:       make(map[int]model.OutboxEvent),
+		endpoints:    make(map[int]model.WebhookEndpoint),
+		deliveries:   make(map[int]model.WebhookDelivery),
+	}
+}
+
+// clone copies every table so a transaction can change them without touching committed rows
+func (t *tables) clone() *tables {
+	return &tables{
+		users:        maps.Clone(t.users),
+		wallets:      maps.Clone(t.wallets),
+		vouchers:     maps.Clone(t.vouchers),
+		transactions: maps.Clone(t.transactions),
+		promotions:   maps.Clone(t.promotions),
+		redemptions:  maps.Clone(t.redemptions),
+		schedules:    maps.Clone(t.schedules),
+		reservations: maps.Clone(t.reservations),
+		merchants:    maps.Clone(t.merchants),
+		batches:      maps.Clone(t.batches),
+		lineItems:    maps.Clone(t.lineItems),
+		outbox:       maps.Clone(t.outbox),
+		endpoints:    maps.Clone(t.endpoints),
+		deliveries:   maps.Clone(t.deliveries),
+	}
+}
+
+// Store holds the committed rows. One writer runs at a time: a transaction keeps the
+// writer slot until it commits or rolls back, which stands in for row locks.
+// Reads outside a transaction see committed rows only.
+type Store struct {
+	writer chan struct{}
+	mu     sync.RWMutex // Guards data
+	data   *tables
+
+	seqMu sync.Mutex
+	seq   map[string]int
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		writer: make(chan struct{}, 1),
+		data:   newTables(),
+		seq:    make(map[string]int),
+	}
+}
+
+// nextID returns the next ID of a table; like database sequences, IDs are not reused after a rollback
+func (s *Store) nextID(table string) int {
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// BeginTx starts a transaction, waiting for the running one to finish
+func (s *Store) BeginTx(ctx context.Context) (repository.Tx, error) {
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	data := s.data.clone()
+	s.mu.RUnlock()
+
+	return &storeTx{ctx: ctx, store: s, data: data}, nil
+}
+
+func (s *Store) acquire(ctx context.Context) error {
+	select {
+	case s.writer <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Store) release() {
+	<-s.writer
+}
+
+// view runs fn against the transaction's rows when tx is set, otherwise against committed rows
+func (s *Store) view(ctx context.Context, tx repository.Tx, fn func(t *tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if t, ok := tx.(*storeTx); ok && t != nil {
+		if t.done {
+			return sql.ErrTxDone
+		}
+		return fn(t.data)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.data)
+}
+
+// update runs fn inside the transaction when tx is set, otherwise as its own write.
+// fn must check every constraint before changing a row so a failed write leaves no partial changes.
+func (s *Store) update(ctx context.Context, tx repository.Tx, fn func(t *tables) error) error {
+	if t, ok := tx.(*storeTx); ok && t != nil {
+		return s.view(ctx, tx, fn)
+	}
+
+	if err := s.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.release()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+// storeTx is a transaction over a private copy of the rows, published on commit
+type storeTx struct {
+	ctx   context.Context
+	store *Store
+	data  *tables
+	done  bool
+}
+
+// Commit publishes the transaction's rows; like a database transaction it fails once ctx is cancelled
+func (t *storeTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	defer t.store.release()
+
+	if err := t.ctx.Err(); err != nil {
+		return err
+	}
+
+	t.store.mu.Lock()
+	t.store.data = t.data
+	t.store.mu.Unlock()
+	return nil
+}
+
+// Rollback discards the transaction's changes
+func (t *storeTx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	t.store.release()
+	return nil
+}
+
+// checkViolation reports a row that breaks a table constraint, as the database would reject it
+func checkViolation(table, constraint string) error {
+	return fmt.Errorf("new row for relation %q violates check constraint %q", table, constraint)
+}
+
+// newestFirst orders rows by creation time descending, breaking ties by ID
+func newestFirst(aTime time.Time, aID int, bTime time.Time, bID int) int {
+	return oldestFirst(bTime, bID, aTime, aID)
+}
+
+// oldestFirst orders rows by time ascending, breaking ties by ID
+func oldestFirst(aTime time.Time, aID int, bTime time.Time, bID int) int {
+	if c := aTime.Compare(bTime); c != 0 {
+		return c
+	}
+	return cmp.Compare(aID, bID)
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	const customerID, voucherID = 2, 1 // Seeded customer and voucher
+
+	tests := []struct {
+		name       string
+		debit      float64 // Taken from the customer's wallet inside the transaction
+		commit     bool
+		wantErr    bool // The debit is rejected
+		wantStored bool // The purchase is visible after the transaction ends
+	}{
+		{name: "commit publishes every write", debit: 150, commit: true, wantStored: true},
+		{name: "rollback discards every write", debit: 150},
+		{name: "rejected write is rolled back with the rest", debit: demoWalletBalance + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewStore()
+			store.seed()
+			vouchers, wallets, transactions := NewVoucherRepository(store), NewWalletRepository(store), NewTransactionRepository(store)
+
+			tx, err := store.BeginTx(ctx)
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+
+			txn := &model.Transaction{
+				UserID:          customerID,
+				VoucherID:       ptr(voucherID),
+				Amount:          tt.debit,
+				TransactionType: model.TransactionTypePurchase,
+				PaymentStatus:   model.PaymentStatusSuccess,
+			}
+			if err := transactions.CreateTransaction(ctx, tx, txn); err != nil {
+				t.Fatalf("CreateTransaction() error = %v", err)
+			}
+			if err := vouchers.UpdateVoucherQuantity(ctx, tx, voucherID, -1); err != nil {
+				t.Fatalf("UpdateVoucherQuantity() error = %v", err)
+			}
+			err = wallets.UpdateBalance(ctx, tx, customerID, -tt.debit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateBalance() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			// The transaction reads its own writes while others still see the committed rows
+			locked, err := vouchers.GetVoucherByIDForUpdate(ctx, tx, voucherID)
+			if err != nil || locked.Quantity != 99 {
+				t.Errorf("voucher in transaction = %+v, %v; want quantity 99", locked, err)
+			}
+			if committed, _ := vouchers.GetVoucherByID(ctx, voucherID); committed.Quantity != 100 {
+				t.Errorf("committed voucher quantity = %d before the transaction ends, want 100", committed.Quantity)
+			}
+			if balance, _ := wallets.GetBalance(ctx, customerID); balance != demoWalletBalance {
+				t.Errorf("committed balance = %v before the transaction ends, want %v", balance, demoWalletBalance)
+			}
+
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatalf("ending the transaction: %v", err)
+			}
+
+			wantQuantity, wantBalance, wantPurchases := 100, float64(demoWalletBalance), 0
+			if tt.wantStored {
+				wantQuantity, wantBalance, wantPurchases = 99, demoWalletBalance-tt.debit, 1
+			}
+			voucher, _ := vouchers.GetVoucherByID(ctx, voucherID)
+			balance, _ := wallets.GetBalance(ctx, customerID)
+			purchases, _ := transactions.GetTransactionsByUserID(ctx, customerID)
+			if voucher.Quantity != wantQuantity || balance != wantBalance || len(purchases) != wantPurchases {
+				t.Errorf("after the transaction: quantity %d, balance %v, %d purchases; want %d, %v, %d",
+					voucher.Quantity, balance, len(purchases), wantQuantity, wantBalance, wantPurchases)
+			}
+
+			// An ended transaction cannot be reused
+			if err := vouchers.UpdateVoucherQuantity(ctx, tx, voucherID, -1); !errors.Is(err, sql.ErrTxDone) {
+				t.Errorf("write after the transaction ended: error = %v, want %v", err, sql.ErrTxDone)
+			}
+			if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+				t.Errorf("second Commit() error = %v, want %v", err, sql.ErrTxDone)
+			}
+
+			// The writer slot is free again
+			next, err := store.BeginTx(ctx)
+			if err != nil {
+				t.Fatalf("BeginTx() after the transaction ended: %v", err)
+			}
+			next.Rollback()
+		})
+	}
+}
+
+func TestBeginTxWaitsForWriter(t *testing.T) {
+	store := NewStore()
+
+	tx, err := store.BeginTx(context.Background())
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+
+	// A second unit of work waits for the first, like a row lock, until its context gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := store.BeginTx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second BeginTx() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestIncrementSoldCount(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	store.seed()
+	schedules := NewPriceScheduleRepository(store)
+
+	now := time.Now()
+	schedule := &model.PriceSchedule{VoucherID: 1, StartsAt: now, EndsAt: now.Add(time.Hour), SalePrice: 99, StockCap: ptr(2)}
+	if err := schedules.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+
+	// Units past the cap are refused and do not count
+	wantErrs := []string{"", "", "flash sale sold out", "flash sale sold out"}
+	for i, want := range wantErrs {
+		err := schedules.IncrementSoldCount(ctx, nil, schedule.ID)
+		if got := errString(err); got != want {
+			t.Errorf("sale %d: error = %q, want %q", i+1, got, want)
+		}
+	}
+
+	stored, _ := schedules.GetScheduleByID(ctx, schedule.ID)
+	if stored.SoldCount != 2 {
+		t.Errorf("sold count = %d, want 2", stored.SoldCount)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
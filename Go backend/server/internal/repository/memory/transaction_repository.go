@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type TransactionRepository struct {
+	store *Store
+}
+
+// NewTransactionRepository creates a new in-memory transaction repository
+func NewTransactionRepository(store *Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+// CreateTransaction creates a new transaction; a purchase is refunded at most once
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx repository.Tx, transaction *model.Transaction) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		if transaction.Amount < 0 {
+			return checkViolation("transactions", "transactions_amount_check")
+		}
+		if transaction.TransactionType == model.TransactionTypeRefund && transaction.OriginalTransactionID != nil {
+			for _, txn := range t.transactions {
+				if isRefundOf(txn, *transaction.OriginalTransactionID) {
+					return errors.New("purchase already refunded")
+				}
+			}
+		}
+
+		now := time.Now()
+		transaction.ID = r.store.nextID("transactions")
+		transaction.CreatedAt = now
+		transaction.UpdatedAt = now
+		t.transactions[transaction.ID] = *transaction
+		return nil
+	})
+}
+
+// GetTransactionByIDForUpdate retrieves a transaction by ID inside a transaction.
+// The transaction holds the store's writer slot, so the row cannot change until it ends.
+func (r *TransactionRepository) GetTransactionByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Transaction, error) {
+	var transaction *model.Transaction
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		if txn, ok := t.transactions[id]; ok {
+			transaction = &txn
+		}
+		return nil
+	})
+	return transaction, err
+}
+
+// HasRefund reports whether a refund returns the purchase
+func (r *TransactionRepository) HasRefund(ctx context.Context, tx repository.Tx, purchaseID int) (bool, error) {
+	var refunded bool
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		for _, txn := range t.transactions {
+			if isRefundOf(txn, purchaseID) {
+				refunded = true
+				break
+			}
+		}
+		return nil
+	})
+	return refunded, err
+}
+
+// isRefundOf reports whether txn is the refund of the purchase
+func isRefundOf(txn model.Transaction, purchaseID int) bool {
+	return txn.TransactionType == model.TransactionTypeRefund &&
+		txn.OriginalTransactionID != nil && *txn.OriginalTransactionID == purchaseID
+}
+
+// GetTransactionsByUserID retrieves all transactions for a user, newest first
+func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, userID int) ([]*model.Transaction, error) {
+	var transactions []*model.Transaction
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, txn := range t.transactions {
+			if txn.UserID == userID {
+				transactions = append(transactions, &txn)
+			}
+		}
+		return nil
+	})
+
+	slices.SortFunc(transactions, func(a, b *model.Transaction) int {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return transactions, err
+}
+
+// UpdateTransactionStatus updates the payment status and payment transaction ID
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, tx repository.Tx, transactionID int, status model.PaymentStatus, paymentTxnID *string) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		txn, ok := t.transactions[transactionID]
+		if !ok {
+			return nil
+		}
+		txn.PaymentStatus = status
+		txn.PaymentTxnID = paymentTxnID
+		txn.UpdatedAt = time.Now()
+		t.transactions[transactionID] = txn
+		return nil
+	})
+}
+
+// MarkRedeemed records when a purchased voucher was redeemed
+func (r *TransactionRepository) MarkRedeemed(ctx context.Context, tx repository.Tx, transactionID int, redeemedAt time.Time) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		txn, ok := t.transactions[transactionID]
+		if !ok {
+			return nil
+		}
+		txn.RedeemedAt = &redeemedAt
+		txn.UpdatedAt = time.Now()
+		t.transactions[transactionID] = txn
+		return nil
+	})
+}
+
+// isCountedPurchase reports whether a transaction counts towards a user's purchase limits
+func isCountedPurchase(txn model.Transaction, userID int, since time.Time) bool {
+	return txn.UserID == userID &&
+		txn.VoucherID != nil &&
+		txn.TransactionType == model.TransactionTypePurchase &&
+		txn.PaymentStatus != model.PaymentStatusFailed &&
+		!txn.CreatedAt.Before(since)
+}
+
+// CountUserPurchases counts a user's non-failed purchases of a voucher since the given time
+func (r *TransactionRepository) CountUserPurchases(ctx context.Context, tx repository.Tx, userID, voucherID int, since time.Time) (int, error) {
+	var count int
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		for _, txn := range t.transactions {
+			if isCountedPurchase(txn, userID, since) && *txn.VoucherID == voucherID {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// GetUserPurchaseCounts returns a user's non-failed purchase count per voucher since the given time
+func (r *TransactionRepository) GetUserPurchaseCounts(ctx context.Context, userID int, since time.Time) (map[int]int, error) {
+	counts := make(map[int]int)
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, txn := range t.transactions {
+			if isCountedPurchase(txn, userID, since) {
+				counts[*txn.VoucherID]++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
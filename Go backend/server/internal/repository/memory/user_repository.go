@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// GetUserByID retrieves a user by ID; the password is not returned
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	var user *model.User
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if u, ok := t.users[id]; ok {
+			u.Password = ""
+			user = &u
+		}
+		return nil
+	})
+	return user, err
+}
+
+// Login checks if email and password match
+func (r *UserRepository) Login(ctx context.Context, email, password string) (*model.User, error) {
+	var user *model.User
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, u := range t.users {
+			if u.Email == email && u.Password == password {
+				user = &u
+				return nil
+			}
+		}
+		return nil
+	})
+	return user, err
+}
+
+// SetMerchant makes a user a member of a merchant with the merchant role
+func (r *UserRepository) SetMerchant(ctx context.Context, userID, merchantID int) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		u, ok := t.users[userID]
+		if !ok {
+			return nil
+		}
+		u.Role = model.UserRoleMerchant
+		u.MerchantID = &merchantID
+		u.UpdatedAt = time.Now()
+		t.users[userID] = u
+		return nil
+	})
+}
@@ -0,0 +1,226 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type VoucherRepository struct {
+	store *Store
+}
+
+// NewVoucherRepository creates a new in-memory voucher repository
+func NewVoucherRepository(store *Store) *VoucherRepository {
+	return &VoucherRepository{store: store}
+}
+
+// voucherAt returns a stored voucher with its active flash sale, if any, applied to
+// the price and the units held by active reservations at the given time
+func (t *tables) voucherAt(v model.Voucher, now time.Time) *model.Voucher {
+	v.Price = v.RegularPrice
+	v.ActiveSale = nil
+	v.RemainingAllowance = nil
+
+	for _, s := range t.schedules {
+		if s.VoucherID == v.ID && s.IsActiveAt(now) {
+			sale := model.PriceSchedule{
+				ID:        s.ID,
+				VoucherID: s.VoucherID,
+				StartsAt:  s.StartsAt,
+				EndsAt:    s.EndsAt,
+				SalePrice: s.SalePrice,
+				StockCap:  s.StockCap,
+				SoldCount: s.SoldCount,
+			}
+			v.ActiveSale = &sale
+			v.Price = sale.SalePrice
+			break
+		}
+	}
+
+	v.Reserved = t.heldUnits(v.ID, now)
+	return &v
+}
+
+// heldUnits returns the units of a voucher held by reservations unexpired at the given time
+func (t *tables) heldUnits(voucherID int, now time.Time) int {
+	var held int
+	for _, res := range t.reservations {
+		if res.VoucherID == voucherID && res.Status == model.ReservationStatusActive && res.ExpiresAt.After(now) {
+			held += res.Quantity
+		}
+	}
+	return held
+}
+
+// sortVouchers orders vouchers newest first
+func sortVouchers(vouchers []*model.Voucher) {
+	slices.SortFunc(vouchers, func(a, b *model.Voucher) int {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+}
+
+// SearchVouchers searches for vouchers with optional filters.
+// Price filters apply to the effective (sale) price and fully reserved vouchers are excluded.
+func (r *VoucherRepository) SearchVouchers(ctx context.Context, category string, merchantID *int, minPrice, maxPrice *float64) ([]*model.Voucher, error) {
+	var vouchers []*model.Voucher
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		now := time.Now()
+		for _, stored := range t.vouchers {
+			v := t.voucherAt(stored, now)
+			if v.ValidFrom.After(now) || v.ValidTo.Before(now) || v.Quantity-v.Reserved <= 0 {
+				continue
+			}
+			if category != "" && v.Category != category {
+				continue
+			}
+			if merchantID != nil && (v.MerchantID == nil || *v.MerchantID != *merchantID) {
+				continue
+			}
+			if minPrice != nil && v.Price < *minPrice {
+				continue
+			}
+			if maxPrice != nil && v.Price > *maxPrice {
+				continue
+			}
+			vouchers = append(vouchers, v)
+		}
+		return nil
+	})
+
+	sortVouchers(vouchers)
+	return vouchers, err
+}
+
+// GetVoucherByID retrieves a voucher by ID
+func (r *VoucherRepository) GetVoucherByID(ctx context.Context, id int) (*model.Voucher, error) {
+	return r.getVoucher(ctx, nil, id)
+}
+
+// GetVoucherByIDForUpdate retrieves a voucher by ID inside a transaction.
+// The transaction holds the store's writer slot, so the voucher cannot change until it ends.
+func (r *VoucherRepository) GetVoucherByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Voucher, error) {
+	return r.getVoucher(ctx, tx, id)
+}
+
+func (r *VoucherRepository) getVoucher(ctx context.Context, tx repository.Tx, id int) (*model.Voucher, error) {
+	var voucher *model.Voucher
+	err := r.store.view(ctx, tx, func(t *tables) error {
+		if v, ok := t.vouchers[id]; ok {
+			voucher = t.voucherAt(v, time.Now())
+		}
+		return nil
+	})
+	return voucher, err
+}
+
+// ListVouchersByMerchant retrieves all vouchers of a merchant, including expired and sold out ones
+func (r *VoucherRepository) ListVouchersByMerchant(ctx context.Context, merchantID int) ([]*model.Voucher, error) {
+	var vouchers []*model.Voucher
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		now := time.Now()
+		for _, v := range t.vouchers {
+			if v.MerchantID != nil && *v.MerchantID == merchantID {
+				vouchers = append(vouchers, t.voucherAt(v, now))
+			}
+		}
+		return nil
+	})
+
+	sortVouchers(vouchers)
+	return vouchers, err
+}
+
+// CreateVoucher creates a new voucher; purchase limits start unset
+func (r *VoucherRepository) CreateVoucher(ctx context.Context, voucher *model.Voucher) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		if err := checkVoucher(voucher); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		voucher.ID = r.store.nextID("vouchers")
+		voucher.CreatedAt = now
+		voucher.UpdatedAt = now
+
+		stored := *voucher
+		stored.MaxPerUser = nil
+		stored.MaxPerUserPerDay = nil
+		t.vouchers[voucher.ID] = stored
+		return nil
+	})
+}
+
+// UpdateVoucher updates the editable details of a voucher
+func (r *VoucherRepository) UpdateVoucher(ctx context.Context, voucher *model.Voucher) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		stored, ok := t.vouchers[voucher.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkVoucher(voucher); err != nil {
+			return err
+		}
+
+		voucher.UpdatedAt = time.Now()
+		stored.Name = voucher.Name
+		stored.Description = voucher.Description
+		stored.Category = voucher.Category
+		stored.RegularPrice = voucher.RegularPrice
+		stored.Quantity = voucher.Quantity
+		stored.ValidFrom = voucher.ValidFrom
+		stored.ValidTo = voucher.ValidTo
+		stored.UpdatedAt = voucher.UpdatedAt
+		t.vouchers[voucher.ID] = stored
+		return nil
+	})
+}
+
+// UpdatePurchaseLimits sets the per-user purchase limits of a voucher (nil means unlimited)
+func (r *VoucherRepository) UpdatePurchaseLimits(ctx context.Context, voucherID int, maxPerUser, maxPerUserPerDay *int) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		stored, ok := t.vouchers[voucherID]
+		if !ok {
+			return nil
+		}
+		stored.MaxPerUser = maxPerUser
+		stored.MaxPerUserPerDay = maxPerUserPerDay
+		stored.UpdatedAt = time.Now()
+		t.vouchers[voucherID] = stored
+		return nil
+	})
+}
+
+// UpdateVoucherQuantity updates the quantity of a voucher (used in transactions)
+func (r *VoucherRepository) UpdateVoucherQuantity(ctx context.Context, tx repository.Tx, voucherID int, quantityChange int) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		stored, ok := t.vouchers[voucherID]
+		if !ok {
+			return nil
+		}
+		if stored.Quantity+quantityChange < 0 {
+			return checkViolation("vouchers", "vouchers_quantity_check")
+		}
+		stored.Quantity += quantityChange
+		stored.UpdatedAt = time.Now()
+		t.vouchers[voucherID] = stored
+		return nil
+	})
+}
+
+// checkVoucher enforces the voucher table's check constraints
+func checkVoucher(v *model.Voucher) error {
+	switch {
+	case v.RegularPrice < 0:
+		return checkViolation("vouchers", "vouchers_price_check")
+	case v.Quantity < 0:
+		return checkViolation("vouchers", "vouchers_quantity_check")
+	case !v.ValidTo.After(v.ValidFrom):
+		return checkViolation("vouchers", "chk_valid_dates")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+type WalletRepository struct {
+	store *Store
+}
+
+// NewWalletRepository creates a new in-memory wallet repository
+func NewWalletRepository(store *Store) *WalletRepository {
+	return &WalletRepository{store: store}
+}
+
+// UpdateBalance updates wallet balance; balances cannot go negative
+func (r *WalletRepository) UpdateBalance(ctx context.Context, tx repository.Tx, userID int, amountChange float64) error {
+	return r.store.update(ctx, tx, func(t *tables) error {
+		wallet, ok := t.wallets[userID]
+		if !ok {
+			return nil
+		}
+		if wallet.Balance+amountChange < 0 {
+			return checkViolation("wallets", "wallets_balance_check")
+		}
+		wallet.Balance += amountChange
+		wallet.UpdatedAt = time.Now()
+		t.wallets[userID] = wallet
+		return nil
+	})
+}
+
+// GetBalance retrieves current balance for a user, 0 if the user has no wallet
+func (r *WalletRepository) GetBalance(ctx context.Context, userID int) (float64, error) {
+	var balance float64
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		balance = t.wallets[userID].Balance
+		return nil
+	})
+	return balance, err
+}
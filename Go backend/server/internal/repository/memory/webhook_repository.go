@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+type WebhookRepository struct {
+	store *Store
+}
+
+// NewWebhookRepository creates a new in-memory webhook repository
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+// cloneEndpoint copies the endpoint's event types so the store and callers never share them
+func cloneEndpoint(endpoint model.WebhookEndpoint) model.WebhookEndpoint {
+	endpoint.EventTypes = slices.Clone(endpoint.EventTypes)
+	return endpoint
+}
+
+// cloneDelivery copies the delivery's payload so the store and callers never share it
+func cloneDelivery(delivery model.WebhookDelivery) model.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	return delivery
+}
+
+// CreateEndpoint creates a new active endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		now := time.Now()
+		endpoint.ID = r.store.nextID("webhook_endpoints")
+		endpoint.Status = model.WebhookEndpointActive
+		endpoint.ConsecutiveFailures = 0
+		endpoint.CreatedAt = now
+		endpoint.UpdatedAt = now
+		if endpoint.EventTypes == nil {
+			endpoint.EventTypes = []string{}
+		}
+		t.endpoints[endpoint.ID] = cloneEndpoint(*endpoint)
+		return nil
+	})
+}
+
+// GetEndpointByID retrieves an endpoint by ID
+func (r *WebhookRepository) GetEndpointByID(ctx context.Context, id int) (*model.WebhookEndpoint, error) {
+	var endpoint *model.WebhookEndpoint
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if e, ok := t.endpoints[id]; ok {
+			e = cloneEndpoint(e)
+			endpoint = &e
+		}
+		return nil
+	})
+	return endpoint, err
+}
+
+// ListEndpointsByMerchant retrieves a merchant's endpoints, oldest first
+func (r *WebhookRepository) ListEndpointsByMerchant(ctx context.Context, merchantID int) ([]*model.WebhookEndpoint, error) {
+	var endpoints []*model.WebhookEndpoint
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, e := range t.endpoints {
+			if e.MerchantID == merchantID {
+				e = cloneEndpoint(e)
+				endpoints = append(endpoints, &e)
+			}
+		}
+		return nil
+	})
+
+	slices.SortFunc(endpoints, func(a, b *model.WebhookEndpoint) int {
+		return oldestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return endpoints, err
+}
+
+// EnableEndpoint makes an endpoint active again and clears its failure count
+func (r *WebhookRepository) EnableEndpoint(ctx context.Context, id int) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		endpoint, ok := t.endpoints[id]
+		if !ok {
+			return nil
+		}
+		endpoint.Status = model.WebhookEndpointActive
+		endpoint.ConsecutiveFailures = 0
+		endpoint.DisabledReason = nil
+		endpoint.DisabledAt = nil
+		endpoint.UpdatedAt = time.Now()
+		t.endpoints[id] = endpoint
+		return nil
+	})
+}
+
+// DeleteEndpoint removes an endpoint and its deliveries
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int) (bool, error) {
+	var deleted bool
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		if _, ok := t.endpoints[id]; !ok {
+			return nil
+		}
+		delete(t.endpoints, id)
+		for deliveryID, delivery := range t.deliveries {
+			if delivery.EndpointID == id {
+				delete(t.deliveries, deliveryID)
+			}
+		}
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
+
+// RecordEndpointResult counts the endpoint's consecutive failures, disabling it at disableAfter
+func (r *WebhookRepository) RecordEndpointResult(ctx context.Context, id int, success bool, disableAfter int, reason string) (bool, error) {
+	var disabled bool
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		endpoint, ok := t.endpoints[id]
+		if !ok {
+			return nil
+		}
+
+		now := time.Now()
+		if success {
+			endpoint.ConsecutiveFailures = 0
+		} else {
+			endpoint.ConsecutiveFailures++
+			if endpoint.Status == model.WebhookEndpointActive && endpoint.ConsecutiveFailures >= disableAfter {
+				endpoint.Status = model.WebhookEndpointDisabled
+				endpoint.DisabledReason = &reason
+				endpoint.DisabledAt = &now
+				disabled = true
+			}
+		}
+		endpoint.UpdatedAt = now
+		t.endpoints[id] = endpoint
+		return nil
+	})
+	return disabled, err
+}
+
+// CreateDelivery adds a pending delivery unless the endpoint already has one for the event
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	var created bool
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		for _, d := range t.deliveries {
+			if d.EndpointID == delivery.EndpointID && d.EventID == delivery.EventID {
+				return nil
+			}
+		}
+
+		now := time.Now()
+		delivery.ID = r.store.nextID("webhook_deliveries")
+		delivery.Status = model.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = now
+		delivery.CreatedAt = now
+		delivery.UpdatedAt = now
+		t.deliveries[delivery.ID] = cloneDelivery(*delivery)
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// GetDeliveryByID retrieves a delivery by ID
+func (r *WebhookRepository) GetDeliveryByID(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	var delivery *model.WebhookDelivery
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		if d, ok := t.deliveries[id]; ok {
+			d = cloneDelivery(d)
+			delivery = &d
+		}
+		return nil
+	})
+	return delivery, err
+}
+
+// ListDeliveries retrieves an endpoint's newest deliveries, optionally filtered by status
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID int, status *model.WebhookDeliveryStatus, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := r.store.view(ctx, nil, func(t *tables) error {
+		for _, d := range t.deliveries {
+			if d.EndpointID == endpointID && (status == nil || d.Status == *status) {
+				d = cloneDelivery(d)
+				deliveries = append(deliveries, &d)
+			}
+		}
+		return nil
+	})
+
+	slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, err
+}
+
+// ClaimDueDeliveries locks pending deliveries of active endpoints that are due and not held by
+// another dispatcher until lockedUntil, oldest first
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := r.store.update(ctx, nil, func(t *tables) error {
+		for _, d := range t.deliveries {
+			if d.Status != model.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+				continue
+			}
+			if d.LockedUntil != nil && d.LockedUntil.After(now) {
+				continue
+			}
+			if endpoint, ok := t.endpoints[d.EndpointID]; !ok || endpoint.Status != model.WebhookEndpointActive {
+				continue
+			}
+			deliveries = append(deliveries, &d)
+		}
+
+		slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int {
+			return oldestFirst(a.NextAttemptAt, a.ID, b.NextAttemptAt, b.ID)
+		})
+		if len(deliveries) > limit {
+			deliveries = deliveries[:limit]
+		}
+
+		for i, d := range deliveries {
+			d.LockedUntil = &lockedUntil
+			t.deliveries[d.ID] = cloneDelivery(*d)
+			claimed := cloneDelivery(*d)
+			deliveries[i] = &claimed
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+// UpdateDelivery stores the delivery's status, attempts, last result and timestamps
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.store.update(ctx, nil, func(t *tables) error {
+		stored, ok := t.deliveries[delivery.ID]
+		if !ok {
+			return nil
+		}
+		if delivery.Attempts < 0 {
+			return checkViolation("webhook_deliveries", "webhook_deliveries_attempts_check")
+		}
+
+		delivery.UpdatedAt = time.Now()
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.LastStatusCode = delivery.LastStatusCode
+		stored.LastError = delivery.LastError
+		stored.NextAttemptAt = delivery.NextAttemptAt
+		stored.LastAttemptAt = delivery.LastAttemptAt
+		stored.DeliveredAt = delivery.DeliveredAt
+		stored.LockedUntil = nil
+		stored.UpdatedAt = delivery.UpdatedAt
+		t.deliveries[delivery.ID] = stored
+		return nil
+	})
+}
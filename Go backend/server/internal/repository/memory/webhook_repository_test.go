@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+func TestClaimDueDeliveriesLease(t *testing.T) {
+	ctx := context.Background()
+	repo := NewWebhookRepository(NewStore())
+
+	endpoint := &model.WebhookEndpoint{MerchantID: 3, URL: "https://merchant.example.com/hooks", Secret: "whsec_test"}
+	if err := repo.CreateEndpoint(ctx, endpoint); err != nil {
+		t.Fatalf("CreateEndpoint() error = %v", err)
+	}
+	delivery := &model.WebhookDelivery{EndpointID: endpoint.ID, EventID: 41, EventType: model.WebhookEventVoucherSold, Payload: []byte(`{}`)}
+	if _, err := repo.CreateDelivery(ctx, delivery); err != nil {
+		t.Fatalf("CreateDelivery() error = %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		release bool // Record the attempt after claiming, as the dispatcher does
+		want    int
+	}{
+		{name: "first dispatcher claims", at: now, want: 1},
+		{name: "second dispatcher skips the held delivery", at: now.Add(30 * time.Second), want: 0},
+		{name: "expired lease is claimed again", at: now.Add(time.Minute), release: true, want: 1},
+		{name: "recorded attempt releases the lease", at: now.Add(time.Minute + time.Second), want: 1},
+	}
+
+	// The cases claim in turn, each holding the delivery for a minute
+	for _, tt := range tests {
+		claimed, err := repo.ClaimDueDeliveries(ctx, tt.at, tt.at.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("%s: ClaimDueDeliveries() error = %v", tt.name, err)
+		}
+		if len(claimed) != tt.want {
+			t.Errorf("%s: claimed %d deliveries, want %d", tt.name, len(claimed), tt.want)
+		}
+		if tt.release && len(claimed) > 0 {
+			if err := repo.UpdateDelivery(ctx, claimed[0]); err != nil {
+				t.Fatalf("%s: UpdateDelivery() error = %v", tt.name, err)
+			}
+		}
+	}
+}
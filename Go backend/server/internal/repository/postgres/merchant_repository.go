@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type MerchantRepository struct {
+	db *sql.DB
+}
+
+// NewMerchantRepository creates a new merchant repository
+func NewMerchantRepository(db *sql.DB) *MerchantRepository {
+	return &MerchantRepository{db: db}
+}
+
+// CreateMerchant creates a new merchant
+func (r *MerchantRepository) CreateMerchant(ctx context.Context, merchant *model.Merchant) error {
+	ctx, span := tracing.StartQuery(ctx, "MerchantRepository.CreateMerchant")
+	defer span.End()
+
+	query := `
+		INSERT INTO merchants (name, email, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	now := time.Now()
+	merchant.CreatedAt = now
+	merchant.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, query, merchant.Name, merchant.Email, merchant.Description, now, now).Scan(&merchant.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errors.New("merchant already exists")
+	}
+
+	return err
+}
+
+// GetMerchantByID retrieves a merchant by ID
+func (r *MerchantRepository) GetMerchantByID(ctx context.Context, id int) (*model.Merchant, error) {
+	ctx, span := tracing.StartQuery(ctx, "MerchantRepository.GetMerchantByID")
+	defer span.End()
+
+	query := `
+		SELECT id, name, email, description, created_at, updated_at
+		FROM merchants
+		WHERE id = $1
+	`
+
+	merchant := &model.Merchant{}
+	var description sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&merchant.ID,
+		&merchant.Name,
+		&merchant.Email,
+		&description,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Merchant not found
+		}
+		return nil, err
+	}
+
+	merchant.Description = description.String
+	return merchant, nil
+}
+
+// GetSalesByMerchantID retrieves successful purchases of a merchant's vouchers, newest first
+func (r *MerchantRepository) GetSalesByMerchantID(ctx context.Context, merchantID int) ([]*model.MerchantSale, error) {
+	ctx, span := tracing.StartQuery(ctx, "MerchantRepository.GetSalesByMerchantID")
+	defer span.End()
+
+	query := `
+		SELECT t.id, v.id, v.name, t.user_id, t.amount, t.discount_amount, t.created_at
+		FROM transactions t
+		JOIN vouchers v ON v.id = t.voucher_id
+		WHERE v.merchant_id = $1 AND t.transaction_type = $2 AND t.payment_status = $3
+		ORDER BY t.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID, model.TransactionTypePurchase, model.PaymentStatusSuccess)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*model.MerchantSale
+	for rows.Next() {
+		sale := &model.MerchantSale{}
+		err := rows.Scan(
+			&sale.TransactionID,
+			&sale.VoucherID,
+			&sale.VoucherName,
+			&sale.UserID,
+			&sale.Amount,
+			&sale.DiscountAmount,
+			&sale.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, rows.Err()
+}
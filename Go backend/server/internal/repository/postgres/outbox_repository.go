@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// AddEvent records a pending event (used in transactions)
+func (r *OutboxRepository) AddEvent(ctx context.Context, tx repository.Tx, event *model.OutboxEvent) error {
+	ctx, span := tracing.StartQuery(ctx, "OutboxRepository.AddEvent")
+	defer span.End()
+
+	query := `
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id
+	`
+
+	now := time.Now()
+	event.Status = model.OutboxStatusPending
+	event.Attempts = 0
+	event.DeliveredTo = []string{}
+	event.NextAttemptAt = now
+	event.CreatedAt = now
+
+	return conn(r.db, tx).QueryRowContext(
+		ctx,
+		query,
+		event.AggregateType,
+		event.AggregateID,
+		event.EventType,
+		[]byte(event.Payload),
+		event.Status,
+		now,
+	).Scan(&event.ID)
+}
+
+// ClaimDueEvents locks the oldest pending event of each aggregate until lockedUntil, if it is due
+// and no relay holds it. SKIP LOCKED lets concurrent relays claim different events; a later event
+// of an aggregate stays unclaimed while an earlier one is pending, even if another relay holds it.
+func (r *OutboxRepository) ClaimDueEvents(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.OutboxEvent, error) {
+	ctx, span := tracing.StartQuery(ctx, "OutboxRepository.ClaimDueEvents")
+	defer span.End()
+
+	query := `
+		WITH due AS (
+			SELECT o.id
+			FROM outbox o
+			WHERE o.status = $1
+				AND o.next_attempt_at <= $2
+				AND (o.locked_until IS NULL OR o.locked_until <= $2)
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox earlier
+					WHERE earlier.status = $1
+						AND earlier.aggregate_type = o.aggregate_type
+						AND earlier.aggregate_id = o.aggregate_id
+						AND earlier.id < o.id
+				)
+			ORDER BY o.id
+			LIMIT $4
+			FOR UPDATE OF o SKIP LOCKED
+		)
+		UPDATE outbox
+		SET locked_until = $3
+		FROM due
+		WHERE outbox.id = due.id
+		RETURNING outbox.id, outbox.aggregate_type, outbox.aggregate_id, outbox.event_type, outbox.payload,
+			outbox.status, outbox.attempts, outbox.delivered_to, outbox.last_error, outbox.next_attempt_at,
+			outbox.processed_at, outbox.locked_until, outbox.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, model.OutboxStatusPending, now, lockedUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.OutboxEvent
+	for rows.Next() {
+		event := &model.OutboxEvent{}
+		var payload []byte
+		var lastError sql.NullString
+		var processedAt, claimedUntil sql.NullTime
+
+		err := rows.Scan(
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.EventType,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			pq.Array(&event.DeliveredTo),
+			&lastError,
+			&event.NextAttemptAt,
+			&processedAt,
+			&claimedUntil,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Payload = payload
+		if lastError.Valid {
+			event.LastError = &lastError.String
+		}
+		if processedAt.Valid {
+			event.ProcessedAt = &processedAt.Time
+		}
+		if claimedUntil.Valid {
+			event.LockedUntil = &claimedUntil.Time
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the CTE's order
+	slices.SortFunc(events, func(a, b *model.OutboxEvent) int { return a.ID - b.ID })
+	return events, nil
+}
+
+// SaveDelivery stores the delivery state of an event
+func (r *OutboxRepository) SaveDelivery(ctx context.Context, event *model.OutboxEvent) error {
+	ctx, span := tracing.StartQuery(ctx, "OutboxRepository.SaveDelivery")
+	defer span.End()
+
+	query := `
+		UPDATE outbox
+		SET status = $1, attempts = $2, delivered_to = $3, last_error = $4, next_attempt_at = $5, processed_at = $6,
+			locked_until = NULL
+		WHERE id = $7
+	`
+
+	deliveredTo := event.DeliveredTo
+	if deliveredTo == nil {
+		deliveredTo = []string{} // The column is NOT NULL; pq sends a nil slice as NULL
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		event.Status,
+		event.Attempts,
+		pq.Array(deliveredTo),
+		event.LastError,
+		event.NextAttemptAt,
+		event.ProcessedAt,
+		event.ID,
+	)
+	return err
+}
+
+// DeleteDeliveredBefore removes events delivered before the given time
+func (r *OutboxRepository) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "OutboxRepository.DeleteDeliveredBefore")
+	defer span.End()
+
+	query := `DELETE FROM outbox WHERE status = $1 AND processed_at < $2`
+
+	result, err := r.db.ExecContext(ctx, query, model.OutboxStatusDelivered, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,66 @@
+// Package postgres implements the repositories on PostgreSQL
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+)
+
+// New creates the PostgreSQL repositories sharing one connection pool
+func New(db *sql.DB) *repository.Repositories {
+	return &repository.Repositories{
+		Tx:             NewTxManager(db),
+		Users:          NewUserRepository(db),
+		Vouchers:       NewVoucherRepository(db),
+		Wallets:        NewWalletRepository(db),
+		Transactions:   NewTransactionRepository(db),
+		Promotions:     NewPromotionRepository(db),
+		PriceSchedules: NewPriceScheduleRepository(db),
+		Reservations:   NewReservationRepository(db),
+		Merchants:      NewMerchantRepository(db),
+		Settlements:    NewSettlementRepository(db),
+		Outbox:         NewOutboxRepository(db),
+		Webhooks:       NewWebhookRepository(db),
+	}
+}
+
+// TxManager starts database transactions
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// BeginTx starts a database transaction; it is rolled back if ctx is cancelled before Commit
+func (m *TxManager) BeginTx(ctx context.Context) (repository.Tx, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{Tx: tx}, nil
+}
+
+// sqlTx adapts *sql.Tx to repository.Tx
+type sqlTx struct {
+	*sql.Tx
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction to run a query in, or the pool when tx is nil
+func conn(db *sql.DB, tx repository.Tx) querier {
+	if t, ok := tx.(*sqlTx); ok && t != nil {
+		return t.Tx
+	}
+	return db
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type PriceScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewPriceScheduleRepository creates a new price schedule repository
+func NewPriceScheduleRepository(db *sql.DB) *PriceScheduleRepository {
+	return &PriceScheduleRepository{db: db}
+}
+
+// CreateSchedule creates a new price schedule
+func (r *PriceScheduleRepository) CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error {
+	ctx, span := tracing.StartQuery(ctx, "PriceScheduleRepository.CreateSchedule")
+	defer span.End()
+
+	query := `
+		INSERT INTO voucher_price_schedules (voucher_id, starts_at, ends_at, sale_price, stock_cap, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	now := time.Now()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		schedule.VoucherID,
+		schedule.StartsAt,
+		schedule.EndsAt,
+		schedule.SalePrice,
+		schedule.StockCap,
+		schedule.CreatedBy,
+		now,
+		now,
+	).Scan(&schedule.ID)
+}
+
+// GetScheduleByID retrieves a price schedule by ID
+func (r *PriceScheduleRepository) GetScheduleByID(ctx context.Context, id int) (*model.PriceSchedule, error) {
+	ctx, span := tracing.StartQuery(ctx, "PriceScheduleRepository.GetScheduleByID")
+	defer span.End()
+
+	query := `
+		SELECT id, voucher_id, starts_at, ends_at, sale_price, stock_cap, sold_count, is_cancelled, created_by, created_at, updated_at
+		FROM voucher_price_schedules
+		WHERE id = $1
+	`
+
+	schedule := &model.PriceSchedule{}
+	var stockCap, createdBy sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&schedule.ID,
+		&schedule.VoucherID,
+		&schedule.StartsAt,
+		&schedule.EndsAt,
+		&schedule.SalePrice,
+		&stockCap,
+		&schedule.SoldCount,
+		&schedule.IsCancelled,
+		&createdBy,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Schedule not found
+		}
+		return nil, err
+	}
+
+	if stockCap.Valid {
+		limit := int(stockCap.Int64)
+		schedule.StockCap = &limit
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		schedule.CreatedBy = &id
+	}
+
+	return schedule, nil
+}
+
+// HasOverlappingSchedule checks if a voucher already has a non-cancelled schedule overlapping the window
+func (r *PriceScheduleRepository) HasOverlappingSchedule(ctx context.Context, voucherID int, startsAt, endsAt time.Time) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "PriceScheduleRepository.HasOverlappingSchedule")
+	defer span.End()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM voucher_price_schedules
+			WHERE voucher_id = $1 AND NOT is_cancelled AND starts_at < $3 AND ends_at > $2
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, voucherID, startsAt, endsAt).Scan(&exists)
+	return exists, err
+}
+
+// CancelSchedule marks a price schedule as cancelled
+func (r *PriceScheduleRepository) CancelSchedule(ctx context.Context, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "PriceScheduleRepository.CancelSchedule")
+	defer span.End()
+
+	query := `
+		UPDATE voucher_price_schedules
+		SET is_cancelled = TRUE, updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// IncrementSoldCount records a unit sold at the sale price (used in transactions)
+func (r *PriceScheduleRepository) IncrementSoldCount(ctx context.Context, tx repository.Tx, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "PriceScheduleRepository.IncrementSoldCount")
+	defer span.End()
+
+	query := `
+		UPDATE voucher_price_schedules
+		SET sold_count = sold_count + 1, updated_at = $1
+		WHERE id = $2 AND (stock_cap IS NULL OR sold_count < stock_cap)
+	`
+
+	result, err := conn(r.db, tx).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("flash sale sold out")
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `id, code, description, discount_type, discount_value, max_discount, min_spend, category,
+		usage_limit, per_user_limit, times_used, is_active, valid_from, valid_to, created_by, created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPromotion scans a promotion row selected with promotionColumns
+func scanPromotion(row rowScanner) (*model.Promotion, error) {
+	promotion := &model.Promotion{}
+	var description sql.NullString
+	var maxDiscount sql.NullFloat64
+	var category sql.NullString
+	var usageLimit, perUserLimit, createdBy sql.NullInt64
+
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.Code,
+		&description,
+		&promotion.DiscountType,
+		&promotion.DiscountValue,
+		&maxDiscount,
+		&promotion.MinSpend,
+		&category,
+		&usageLimit,
+		&perUserLimit,
+		&promotion.TimesUsed,
+		&promotion.IsActive,
+		&promotion.ValidFrom,
+		&promotion.ValidTo,
+		&createdBy,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.Description = description.String
+	if maxDiscount.Valid {
+		promotion.MaxDiscount = &maxDiscount.Float64
+	}
+	if category.Valid {
+		promotion.Category = &category.String
+	}
+	if usageLimit.Valid {
+		limit := int(usageLimit.Int64)
+		promotion.UsageLimit = &limit
+	}
+	if perUserLimit.Valid {
+		limit := int(perUserLimit.Int64)
+		promotion.PerUserLimit = &limit
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		promotion.CreatedBy = &id
+	}
+
+	return promotion, nil
+}
+
+// CreatePromotion creates a new promotion
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, promotion *model.Promotion) error {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.CreatePromotion")
+	defer span.End()
+
+	query := `
+		INSERT INTO promotions (code, description, discount_type, discount_value, max_discount, min_spend, category,
+			usage_limit, per_user_limit, is_active, valid_from, valid_to, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`
+
+	now := time.Now()
+	promotion.CreatedAt = now
+	promotion.UpdatedAt = now
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		promotion.Code,
+		promotion.Description,
+		promotion.DiscountType,
+		promotion.DiscountValue,
+		promotion.MaxDiscount,
+		promotion.MinSpend,
+		promotion.Category,
+		promotion.UsageLimit,
+		promotion.PerUserLimit,
+		promotion.IsActive,
+		promotion.ValidFrom,
+		promotion.ValidTo,
+		promotion.CreatedBy,
+		now,
+		now,
+	).Scan(&promotion.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errors.New("promo code already exists")
+	}
+
+	return err
+}
+
+// GetPromotionByID retrieves a promotion by ID
+func (r *PromotionRepository) GetPromotionByID(ctx context.Context, id int) (*model.Promotion, error) {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.GetPromotionByID")
+	defer span.End()
+
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+
+	promotion, err := scanPromotion(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Promotion not found
+		}
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// GetPromotionByCodeForUpdate retrieves a promotion by code and locks the row
+// until the surrounding transaction ends, so usage limits are enforced atomically
+func (r *PromotionRepository) GetPromotionByCodeForUpdate(ctx context.Context, tx repository.Tx, code string) (*model.Promotion, error) {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.GetPromotionByCodeForUpdate")
+	defer span.End()
+
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1 FOR UPDATE`
+
+	promotion, err := scanPromotion(conn(r.db, tx).QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Promotion not found
+		}
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// ListPromotions retrieves all promotions, newest first
+func (r *PromotionRepository) ListPromotions(ctx context.Context) ([]*model.Promotion, error) {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.ListPromotions")
+	defer span.End()
+
+	query := `SELECT ` + promotionColumns + ` FROM promotions ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*model.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, rows.Err()
+}
+
+// SetPromotionActive enables or disables a promotion
+func (r *PromotionRepository) SetPromotionActive(ctx context.Context, id int, active bool) error {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.SetPromotionActive")
+	defer span.End()
+
+	query := `
+		UPDATE promotions
+		SET is_active = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, active, time.Now(), id)
+	return err
+}
+
+// CountUserRedemptions counts how many times a user has redeemed a promotion
+func (r *PromotionRepository) CountUserRedemptions(ctx context.Context, tx repository.Tx, promotionID, userID int) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.CountUserRedemptions")
+	defer span.End()
+
+	query := `
+		SELECT COUNT(*)
+		FROM promotion_redemptions
+		WHERE promotion_id = $1 AND user_id = $2
+	`
+
+	var count int
+	err := conn(r.db, tx).QueryRowContext(ctx, query, promotionID, userID).Scan(&count)
+	return count, err
+}
+
+// CreateRedemption records a redemption and increments the promotion usage counter
+func (r *PromotionRepository) CreateRedemption(ctx context.Context, tx repository.Tx, redemption *model.PromotionRedemption) error {
+	ctx, span := tracing.StartQuery(ctx, "PromotionRepository.CreateRedemption")
+	defer span.End()
+
+	query := `
+		INSERT INTO promotion_redemptions (promotion_id, user_id, transaction_id, discount_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	now := time.Now()
+	redemption.CreatedAt = now
+
+	err := conn(r.db, tx).QueryRowContext(
+		ctx,
+		query,
+		redemption.PromotionID,
+		redemption.UserID,
+		redemption.TransactionID,
+		redemption.DiscountAmount,
+		now,
+	).Scan(&redemption.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(r.db, tx).ExecContext(ctx, `
+		UPDATE promotions
+		SET times_used = times_used + 1, updated_at = $1
+		WHERE id = $2
+	`, now, redemption.PromotionID)
+
+	return err
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type ReservationRepository struct {
+	db *sql.DB
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *sql.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// CreateReservation creates a new active reservation (used in transactions)
+func (r *ReservationRepository) CreateReservation(ctx context.Context, tx repository.Tx, reservation *model.Reservation) error {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.CreateReservation")
+	defer span.End()
+
+	query := `
+		INSERT INTO voucher_reservations (voucher_id, user_id, quantity, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	now := time.Now()
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	return conn(r.db, tx).QueryRowContext(
+		ctx,
+		query,
+		reservation.VoucherID,
+		reservation.UserID,
+		reservation.Quantity,
+		reservation.Status,
+		reservation.ExpiresAt,
+		now,
+		now,
+	).Scan(&reservation.ID)
+}
+
+// GetReservationByID retrieves a reservation by ID
+func (r *ReservationRepository) GetReservationByID(ctx context.Context, id int) (*model.Reservation, error) {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.GetReservationByID")
+	defer span.End()
+
+	query := `
+		SELECT id, voucher_id, user_id, quantity, status, expires_at, created_at, updated_at
+		FROM voucher_reservations
+		WHERE id = $1
+	`
+
+	reservation := &model.Reservation{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&reservation.ID,
+		&reservation.VoucherID,
+		&reservation.UserID,
+		&reservation.Quantity,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Reservation not found
+		}
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// GetReservationByIDForUpdate retrieves a reservation by ID and locks it until the
+// surrounding transaction ends
+func (r *ReservationRepository) GetReservationByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Reservation, error) {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.GetReservationByIDForUpdate")
+	defer span.End()
+
+	query := `
+		SELECT id, voucher_id, user_id, quantity, status, expires_at, created_at, updated_at
+		FROM voucher_reservations
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	reservation := &model.Reservation{}
+	err := conn(r.db, tx).QueryRowContext(ctx, query, id).Scan(
+		&reservation.ID,
+		&reservation.VoucherID,
+		&reservation.UserID,
+		&reservation.Quantity,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Reservation not found
+		}
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// GetActiveReservationForUpdate retrieves the user's oldest unexpired hold on a voucher
+// and locks it until the surrounding transaction ends
+func (r *ReservationRepository) GetActiveReservationForUpdate(ctx context.Context, tx repository.Tx, userID, voucherID int) (*model.Reservation, error) {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.GetActiveReservationForUpdate")
+	defer span.End()
+
+	query := `
+		SELECT id, voucher_id, user_id, quantity, status, expires_at, created_at, updated_at
+		FROM voucher_reservations
+		WHERE user_id = $1 AND voucher_id = $2 AND status = $3 AND expires_at > $4
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE
+	`
+
+	reservation := &model.Reservation{}
+	err := conn(r.db, tx).QueryRowContext(ctx, query, userID, voucherID, model.ReservationStatusActive, time.Now()).Scan(
+		&reservation.ID,
+		&reservation.VoucherID,
+		&reservation.UserID,
+		&reservation.Quantity,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No active hold
+		}
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// SumActiveHolds returns the units of a voucher held by unexpired reservations
+func (r *ReservationRepository) SumActiveHolds(ctx context.Context, tx repository.Tx, voucherID int) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.SumActiveHolds")
+	defer span.End()
+
+	query := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM voucher_reservations
+		WHERE voucher_id = $1 AND status = $2 AND expires_at > $3
+	`
+
+	var held int
+	err := conn(r.db, tx).QueryRowContext(ctx, query, voucherID, model.ReservationStatusActive, time.Now()).Scan(&held)
+	return held, err
+}
+
+// UpdateReservation updates the held quantity and status of a reservation (used in transactions)
+func (r *ReservationRepository) UpdateReservation(ctx context.Context, tx repository.Tx, id, quantity int, status model.ReservationStatus) error {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.UpdateReservation")
+	defer span.End()
+
+	query := `
+		UPDATE voucher_reservations
+		SET quantity = $1, status = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query, quantity, status, time.Now(), id)
+	return err
+}
+
+// ExpireReservations marks active holds past their expiry as expired and returns how many were released
+func (r *ReservationRepository) ExpireReservations(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracing.StartQuery(ctx, "ReservationRepository.ExpireReservations")
+	defer span.End()
+
+	query := `
+		UPDATE voucher_reservations
+		SET status = $1, updated_at = $2
+		WHERE status = $3 AND expires_at <= $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, model.ReservationStatusExpired, now, model.ReservationStatusActive)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
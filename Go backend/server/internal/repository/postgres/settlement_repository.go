@@ -0,0 +1,358 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type SettlementRepository struct {
+	db *sql.DB
+}
+
+// NewSettlementRepository creates a new settlement repository
+func NewSettlementRepository(db *sql.DB) *SettlementRepository {
+	return &SettlementRepository{db: db}
+}
+
+const settlementBatchColumns = `
+	id, merchant_id, period_start, period_end, commission_rate, gross_amount, refund_amount,
+	commission_amount, net_amount, status, created_by, closed_at, created_at, updated_at
+`
+
+// scanSettlementBatch scans a settlement batch row selected with settlementBatchColumns
+func scanSettlementBatch(row rowScanner) (*model.SettlementBatch, error) {
+	batch := &model.SettlementBatch{}
+	var createdBy sql.NullInt64
+	var closedAt sql.NullTime
+	err := row.Scan(
+		&batch.ID,
+		&batch.MerchantID,
+		&batch.PeriodStart,
+		&batch.PeriodEnd,
+		&batch.CommissionRate,
+		&batch.GrossAmount,
+		&batch.RefundAmount,
+		&batch.CommissionAmount,
+		&batch.NetAmount,
+		&batch.Status,
+		&createdBy,
+		&closedAt,
+		&batch.CreatedAt,
+		&batch.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdBy.Valid {
+		id := int(createdBy.Int64)
+		batch.CreatedBy = &id
+	}
+	if closedAt.Valid {
+		batch.ClosedAt = &closedAt.Time
+	}
+
+	return batch, nil
+}
+
+// GetLatestPeriodEnd returns the end of the merchant's most recent batch, or nil if none exist (used in transactions)
+func (r *SettlementRepository) GetLatestPeriodEnd(ctx context.Context, tx repository.Tx, merchantID int) (*time.Time, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.GetLatestPeriodEnd")
+	defer span.End()
+
+	query := `
+		SELECT MAX(period_end)
+		FROM settlement_batches
+		WHERE merchant_id = $1
+	`
+
+	var periodEnd sql.NullTime
+	if err := conn(r.db, tx).QueryRowContext(ctx, query, merchantID).Scan(&periodEnd); err != nil {
+		return nil, err
+	}
+
+	if !periodEnd.Valid {
+		return nil, nil
+	}
+
+	return &periodEnd.Time, nil
+}
+
+// GetUnsettledTransactions retrieves successful purchases and refunds of the merchant's vouchers
+// created before periodEnd that are not in any batch yet (used in transactions).
+// Amounts are returned unsigned; the caller applies commission and signs refunds.
+func (r *SettlementRepository) GetUnsettledTransactions(ctx context.Context, tx repository.Tx, merchantID int, periodEnd time.Time) ([]*model.SettlementLineItem, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.GetUnsettledTransactions")
+	defer span.End()
+
+	query := `
+		SELECT t.id, v.id, v.name, t.transaction_type, t.amount, t.created_at
+		FROM transactions t
+		JOIN vouchers v ON v.id = t.voucher_id
+		LEFT JOIN settlement_line_items li ON li.transaction_id = t.id
+		WHERE v.merchant_id = $1
+			AND t.transaction_type IN ($2, $3)
+			AND t.payment_status = $4
+			AND t.created_at < $5
+			AND li.id IS NULL
+		ORDER BY t.created_at, t.id
+	`
+
+	rows, err := conn(r.db, tx).QueryContext(
+		ctx,
+		query,
+		merchantID,
+		model.TransactionTypePurchase,
+		model.TransactionTypeRefund,
+		model.PaymentStatusSuccess,
+		periodEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*model.SettlementLineItem
+	for rows.Next() {
+		item := &model.SettlementLineItem{}
+		err := rows.Scan(
+			&item.TransactionID,
+			&item.VoucherID,
+			&item.VoucherName,
+			&item.TransactionType,
+			&item.Amount,
+			&item.TransactionAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// CreateBatch creates an open settlement batch with its line items (used in transactions)
+func (r *SettlementRepository) CreateBatch(ctx context.Context, tx repository.Tx, batch *model.SettlementBatch) error {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.CreateBatch")
+	defer span.End()
+
+	batchQuery := `
+		INSERT INTO settlement_batches (
+			merchant_id, period_start, period_end, commission_rate, gross_amount, refund_amount,
+			commission_amount, net_amount, status, created_by, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	now := time.Now()
+	batch.CreatedAt = now
+	batch.UpdatedAt = now
+
+	err := conn(r.db, tx).QueryRowContext(
+		ctx,
+		batchQuery,
+		batch.MerchantID,
+		batch.PeriodStart,
+		batch.PeriodEnd,
+		batch.CommissionRate,
+		batch.GrossAmount,
+		batch.RefundAmount,
+		batch.CommissionAmount,
+		batch.NetAmount,
+		batch.Status,
+		batch.CreatedBy,
+		now,
+		now,
+	).Scan(&batch.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errors.New("settlement batch already open")
+	}
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO settlement_line_items (
+			batch_id, transaction_id, voucher_id, voucher_name, transaction_type,
+			amount, commission, net_amount, transaction_at, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	for _, item := range batch.Items {
+		item.BatchID = batch.ID
+		err := conn(r.db, tx).QueryRowContext(
+			ctx,
+			itemQuery,
+			item.BatchID,
+			item.TransactionID,
+			item.VoucherID,
+			item.VoucherName,
+			item.TransactionType,
+			item.Amount,
+			item.Commission,
+			item.NetAmount,
+			item.TransactionAt,
+			now,
+		).Scan(&item.ID)
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errors.New("transaction already settled")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetBatchByID retrieves a settlement batch by ID without its line items
+func (r *SettlementRepository) GetBatchByID(ctx context.Context, id int) (*model.SettlementBatch, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.GetBatchByID")
+	defer span.End()
+
+	query := `SELECT ` + settlementBatchColumns + ` FROM settlement_batches WHERE id = $1`
+
+	batch, err := scanSettlementBatch(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Batch not found
+		}
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// GetLineItems retrieves the line items of a batch in transaction order
+func (r *SettlementRepository) GetLineItems(ctx context.Context, batchID int) ([]*model.SettlementLineItem, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.GetLineItems")
+	defer span.End()
+
+	query := `
+		SELECT id, batch_id, transaction_id, voucher_id, voucher_name, transaction_type,
+			amount, commission, net_amount, transaction_at
+		FROM settlement_line_items
+		WHERE batch_id = $1
+		ORDER BY transaction_at, transaction_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*model.SettlementLineItem
+	for rows.Next() {
+		item := &model.SettlementLineItem{}
+		err := rows.Scan(
+			&item.ID,
+			&item.BatchID,
+			&item.TransactionID,
+			&item.VoucherID,
+			&item.VoucherName,
+			&item.TransactionType,
+			&item.Amount,
+			&item.Commission,
+			&item.NetAmount,
+			&item.TransactionAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ListBatches retrieves settlement batches, newest period first.
+// A nil merchantID lists batches of all merchants.
+func (r *SettlementRepository) ListBatches(ctx context.Context, merchantID *int) ([]*model.SettlementBatch, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.ListBatches")
+	defer span.End()
+
+	query := `
+		SELECT ` + settlementBatchColumns + `
+		FROM settlement_batches
+		WHERE ($1::int IS NULL OR merchant_id = $1)
+		ORDER BY period_end DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []*model.SettlementBatch
+	for rows.Next() {
+		batch, err := scanSettlementBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// CloseBatch marks an open batch as closed; returns false if the batch was not open
+func (r *SettlementRepository) CloseBatch(ctx context.Context, id int, closedAt time.Time) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.CloseBatch")
+	defer span.End()
+
+	query := `
+		UPDATE settlement_batches
+		SET status = $1, closed_at = $2, updated_at = $2
+		WHERE id = $3 AND status = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, model.SettlementStatusClosed, closedAt, id, model.SettlementStatusOpen)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteOpenBatch deletes an open batch and its line items; returns false if the batch was not open
+func (r *SettlementRepository) DeleteOpenBatch(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "SettlementRepository.DeleteOpenBatch")
+	defer span.End()
+
+	query := `
+		DELETE FROM settlement_batches
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, model.SettlementStatusOpen)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type TransactionRepository struct {
+	db *sql.DB
+}
+
+// NewTransactionRepository creates a new transaction repository
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// transactionColumns are the columns scanTransaction reads, in order
+const transactionColumns = `
+	id, user_id, voucher_id, amount, transaction_type, payment_status, payment_txn_id, promotion_id,
+	discount_amount, original_transaction_id, redeemed_at, created_at, updated_at
+`
+
+// scanTransaction scans a transaction row selected with transactionColumns
+func scanTransaction(row rowScanner) (*model.Transaction, error) {
+	transaction := &model.Transaction{}
+	var voucherID, promotionID, originalTransactionID sql.NullInt64
+	var paymentTxnID sql.NullString
+	var redeemedAt sql.NullTime
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.UserID,
+		&voucherID,
+		&transaction.Amount,
+		&transaction.TransactionType,
+		&transaction.PaymentStatus,
+		&paymentTxnID,
+		&promotionID,
+		&transaction.DiscountAmount,
+		&originalTransactionID,
+		&redeemedAt,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if voucherID.Valid {
+		vID := int(voucherID.Int64)
+		transaction.VoucherID = &vID
+	}
+
+	if paymentTxnID.Valid {
+		transaction.PaymentTxnID = &paymentTxnID.String
+	}
+
+	if promotionID.Valid {
+		pID := int(promotionID.Int64)
+		transaction.PromotionID = &pID
+	}
+
+	if originalTransactionID.Valid {
+		oID := int(originalTransactionID.Int64)
+		transaction.OriginalTransactionID = &oID
+	}
+
+	if redeemedAt.Valid {
+		transaction.RedeemedAt = &redeemedAt.Time
+	}
+
+	return transaction, nil
+}
+
+// CreateTransaction creates a new transaction; a purchase is refunded at most once
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, tx repository.Tx, transaction *model.Transaction) error {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.CreateTransaction")
+	defer span.End()
+
+	query := `
+		INSERT INTO transactions (user_id, voucher_id, amount, transaction_type, payment_status, payment_txn_id, promotion_id, discount_amount, original_transaction_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	now := time.Now()
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+
+	err := conn(r.db, tx).QueryRowContext(
+		ctx,
+		query,
+		transaction.UserID,
+		transaction.VoucherID,
+		transaction.Amount,
+		transaction.TransactionType,
+		transaction.PaymentStatus,
+		transaction.PaymentTxnID,
+		transaction.PromotionID,
+		transaction.DiscountAmount,
+		transaction.OriginalTransactionID,
+		now,
+		now,
+	).Scan(&transaction.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errors.New("purchase already refunded")
+	}
+
+	return err
+}
+
+// GetTransactionByIDForUpdate retrieves a transaction by ID and locks it until the surrounding
+// transaction ends
+func (r *TransactionRepository) GetTransactionByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Transaction, error) {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.GetTransactionByIDForUpdate")
+	defer span.End()
+
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1 FOR UPDATE`
+
+	transaction, err := scanTransaction(conn(r.db, tx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Transaction not found
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// HasRefund reports whether a purchase has been refunded
+func (r *TransactionRepository) HasRefund(ctx context.Context, tx repository.Tx, purchaseID int) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.HasRefund")
+	defer span.End()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM transactions WHERE original_transaction_id = $1 AND transaction_type = $2
+		)
+	`
+
+	var refunded bool
+	err := conn(r.db, tx).QueryRowContext(ctx, query, purchaseID, model.TransactionTypeRefund).Scan(&refunded)
+	return refunded, err
+}
+
+// GetTransactionsByUserID retrieves all transactions for a user
+func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, userID int) ([]*model.Transaction, error) {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.GetTransactionsByUserID")
+	defer span.End()
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*model.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// UpdateTransactionStatus updates the payment status and payment transaction ID
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, tx repository.Tx, transactionID int, status model.PaymentStatus, paymentTxnID *string) error {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.UpdateTransactionStatus")
+	defer span.End()
+
+	query := `
+		UPDATE transactions
+		SET payment_status = $1, payment_txn_id = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query, status, paymentTxnID, time.Now(), transactionID)
+	return err
+}
+
+// MarkRedeemed records when a purchased voucher was redeemed
+func (r *TransactionRepository) MarkRedeemed(ctx context.Context, tx repository.Tx, transactionID int, redeemedAt time.Time) error {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.MarkRedeemed")
+	defer span.End()
+
+	query := `
+		UPDATE transactions
+		SET redeemed_at = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query, redeemedAt, time.Now(), transactionID)
+	return err
+}
+
+// CountUserPurchases counts a user's non-failed purchases of a voucher since the given time
+func (r *TransactionRepository) CountUserPurchases(ctx context.Context, tx repository.Tx, userID, voucherID int, since time.Time) (int, error) {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.CountUserPurchases")
+	defer span.End()
+
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE user_id = $1 AND voucher_id = $2 AND transaction_type = $3 AND payment_status <> $4 AND created_at >= $5
+	`
+
+	var count int
+	err := conn(r.db, tx).QueryRowContext(ctx, query, userID, voucherID, model.TransactionTypePurchase, model.PaymentStatusFailed, since).Scan(&count)
+	return count, err
+}
+
+// GetUserPurchaseCounts returns a user's non-failed purchase count per voucher since the given time
+func (r *TransactionRepository) GetUserPurchaseCounts(ctx context.Context, userID int, since time.Time) (map[int]int, error) {
+	ctx, span := tracing.StartQuery(ctx, "TransactionRepository.GetUserPurchaseCounts")
+	defer span.End()
+
+	query := `
+		SELECT voucher_id, COUNT(*)
+		FROM transactions
+		WHERE user_id = $1 AND voucher_id IS NOT NULL AND transaction_type = $2 AND payment_status <> $3 AND created_at >= $4
+		GROUP BY voucher_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, model.TransactionTypePurchase, model.PaymentStatusFailed, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var voucherID, count int
+		if err := rows.Scan(&voucherID, &count); err != nil {
+			return nil, err
+		}
+		counts[voucherID] = count
+	}
+
+	return counts, rows.Err()
+}
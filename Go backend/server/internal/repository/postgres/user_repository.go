@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// GetUserByID retrieves a user by ID
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.GetUserByID")
+	defer span.End()
+
+	query := `
+		SELECT id, name, email, role, merchant_id, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+	
+	user := &model.User{}
+	var merchantID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&merchantID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	if merchantID.Valid {
+		mID := int(merchantID.Int64)
+		user.MerchantID = &mID
+	}
+	
+	return user, nil
+}
+
+// Login checks if email and password match
+func (r *UserRepository) Login(ctx context.Context, email, password string) (*model.User, error) {
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Login")
+	defer span.End()
+
+	query := `
+		SELECT id, name, email, password, role, merchant_id, created_at, updated_at
+		FROM users
+		WHERE email = $1 AND password = $2
+	`
+	
+	user := &model.User{}
+	var merchantID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, email, password).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&merchantID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Invalid credentials
+		}
+		return nil, err
+	}
+
+	if merchantID.Valid {
+		mID := int(merchantID.Int64)
+		user.MerchantID = &mID
+	}
+	
+	return user, nil
+}
+
+// SetMerchant makes a user a member of a merchant with the merchant role
+func (r *UserRepository) SetMerchant(ctx context.Context, userID, merchantID int) error {
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.SetMerchant")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET role = $1, merchant_id = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.ExecContext(ctx, query, model.UserRoleMerchant, merchantID, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type VoucherRepository struct {
+	db *sql.DB
+}
+
+// NewVoucherRepository creates a new voucher repository
+func NewVoucherRepository(db *sql.DB) *VoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+// voucherSelect selects vouchers joined with their active flash sale, if any, and
+// the units held by active reservations. $1 must be the current time. Overlapping
+// schedules are rejected on creation, so at most one schedule row joins per voucher.
+const voucherSelect = `
+		SELECT v.id, v.name, v.description, v.category, v.merchant_id, v.price, v.quantity, v.valid_from, v.valid_to,
+			v.max_per_user, v.max_per_user_per_day, v.created_at, v.updated_at,
+			s.id, s.starts_at, s.ends_at, s.sale_price, s.stock_cap, s.sold_count, h.held
+		FROM vouchers v
+		LEFT JOIN voucher_price_schedules s
+			ON s.voucher_id = v.id AND NOT s.is_cancelled AND s.starts_at <= $1 AND s.ends_at > $1
+			AND (s.stock_cap IS NULL OR s.sold_count < s.stock_cap)
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(SUM(r.quantity), 0) AS held
+			FROM voucher_reservations r
+			WHERE r.voucher_id = v.id AND r.status = 'active' AND r.expires_at > $1
+		) h ON TRUE
+	`
+
+// scanVoucher scans a voucher row selected with voucherSelect and applies the active sale price
+func scanVoucher(row rowScanner) (*model.Voucher, error) {
+	voucher := &model.Voucher{}
+	var description sql.NullString
+	var merchantID, maxPerUser, maxPerUserPerDay sql.NullInt64
+	var scheduleID, stockCap, soldCount sql.NullInt64
+	var startsAt, endsAt sql.NullTime
+	var salePrice sql.NullFloat64
+
+	err := row.Scan(
+		&voucher.ID,
+		&voucher.Name,
+		&description,
+		&voucher.Category,
+		&merchantID,
+		&voucher.RegularPrice,
+		&voucher.Quantity,
+		&voucher.ValidFrom,
+		&voucher.ValidTo,
+		&maxPerUser,
+		&maxPerUserPerDay,
+		&voucher.CreatedAt,
+		&voucher.UpdatedAt,
+		&scheduleID,
+		&startsAt,
+		&endsAt,
+		&salePrice,
+		&stockCap,
+		&soldCount,
+		&voucher.Reserved,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher.Description = description.String
+	if merchantID.Valid {
+		mID := int(merchantID.Int64)
+		voucher.MerchantID = &mID
+	}
+	if maxPerUser.Valid {
+		limit := int(maxPerUser.Int64)
+		voucher.MaxPerUser = &limit
+	}
+	if maxPerUserPerDay.Valid {
+		limit := int(maxPerUserPerDay.Int64)
+		voucher.MaxPerUserPerDay = &limit
+	}
+
+	voucher.Price = voucher.RegularPrice
+	if scheduleID.Valid {
+		sale := &model.PriceSchedule{
+			ID:        int(scheduleID.Int64),
+			VoucherID: voucher.ID,
+			StartsAt:  startsAt.Time,
+			EndsAt:    endsAt.Time,
+			SalePrice: salePrice.Float64,
+			SoldCount: int(soldCount.Int64),
+		}
+		if stockCap.Valid {
+			limit := int(stockCap.Int64)
+			sale.StockCap = &limit
+		}
+		voucher.ActiveSale = sale
+		voucher.Price = sale.SalePrice
+	}
+
+	return voucher, nil
+}
+
+// SearchVouchers searches for vouchers with optional filters.
+// Price filters apply to the effective (sale) price and fully reserved vouchers are excluded.
+func (r *VoucherRepository) SearchVouchers(ctx context.Context, category string, merchantID *int, minPrice, maxPrice *float64) ([]*model.Voucher, error) {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.SearchVouchers")
+	defer span.End()
+
+	query := voucherSelect + `
+		WHERE v.valid_from <= $1 AND v.valid_to >= $1 AND v.quantity - h.held > 0
+	`
+	args := []interface{}{time.Now()}
+	argPos := 2
+
+	if category != "" {
+		query += fmt.Sprintf(" AND v.category = $%d", argPos)
+		args = append(args, category)
+		argPos++
+	}
+
+	if merchantID != nil {
+		query += fmt.Sprintf(" AND v.merchant_id = $%d", argPos)
+		args = append(args, *merchantID)
+		argPos++
+	}
+
+	if minPrice != nil {
+		query += fmt.Sprintf(" AND COALESCE(s.sale_price, v.price) >= $%d", argPos)
+		args = append(args, *minPrice)
+		argPos++
+	}
+
+	if maxPrice != nil {
+		query += fmt.Sprintf(" AND COALESCE(s.sale_price, v.price) <= $%d", argPos)
+		args = append(args, *maxPrice)
+		argPos++
+	}
+
+	query += " ORDER BY v.created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vouchers []*model.Voucher
+	for rows.Next() {
+		voucher, err := scanVoucher(rows)
+		if err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	return vouchers, rows.Err()
+}
+
+// GetVoucherByID retrieves a voucher by ID
+func (r *VoucherRepository) GetVoucherByID(ctx context.Context, id int) (*model.Voucher, error) {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.GetVoucherByID")
+	defer span.End()
+
+	query := voucherSelect + `
+		WHERE v.id = $2
+	`
+
+	voucher, err := scanVoucher(r.db.QueryRowContext(ctx, query, time.Now(), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Voucher not found
+		}
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// GetVoucherByIDForUpdate retrieves a voucher by ID and locks the voucher row until
+// the surrounding transaction ends, serializing concurrent purchases of the voucher
+// (including sales of its flash sale stock)
+func (r *VoucherRepository) GetVoucherByIDForUpdate(ctx context.Context, tx repository.Tx, id int) (*model.Voucher, error) {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.GetVoucherByIDForUpdate")
+	defer span.End()
+
+	query := voucherSelect + `
+		WHERE v.id = $2
+		FOR UPDATE OF v
+	`
+
+	voucher, err := scanVoucher(conn(r.db, tx).QueryRowContext(ctx, query, time.Now(), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Voucher not found
+		}
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// ListVouchersByMerchant retrieves all vouchers of a merchant, including expired and sold out ones
+func (r *VoucherRepository) ListVouchersByMerchant(ctx context.Context, merchantID int) ([]*model.Voucher, error) {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.ListVouchersByMerchant")
+	defer span.End()
+
+	query := voucherSelect + `
+		WHERE v.merchant_id = $2
+		ORDER BY v.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, time.Now(), merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vouchers []*model.Voucher
+	for rows.Next() {
+		voucher, err := scanVoucher(rows)
+		if err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	return vouchers, rows.Err()
+}
+
+// CreateVoucher creates a new voucher
+func (r *VoucherRepository) CreateVoucher(ctx context.Context, voucher *model.Voucher) error {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.CreateVoucher")
+	defer span.End()
+
+	query := `
+		INSERT INTO vouchers (name, description, category, merchant_id, price, quantity, valid_from, valid_to, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	now := time.Now()
+	voucher.CreatedAt = now
+	voucher.UpdatedAt = now
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		voucher.Name,
+		voucher.Description,
+		voucher.Category,
+		voucher.MerchantID,
+		voucher.RegularPrice,
+		voucher.Quantity,
+		voucher.ValidFrom,
+		voucher.ValidTo,
+		now,
+		now,
+	).Scan(&voucher.ID)
+}
+
+// UpdateVoucher updates the editable details of a voucher
+func (r *VoucherRepository) UpdateVoucher(ctx context.Context, voucher *model.Voucher) error {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.UpdateVoucher")
+	defer span.End()
+
+	query := `
+		UPDATE vouchers
+		SET name = $1, description = $2, category = $3, price = $4, quantity = $5, valid_from = $6, valid_to = $7, updated_at = $8
+		WHERE id = $9
+	`
+
+	voucher.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		voucher.Name,
+		voucher.Description,
+		voucher.Category,
+		voucher.RegularPrice,
+		voucher.Quantity,
+		voucher.ValidFrom,
+		voucher.ValidTo,
+		voucher.UpdatedAt,
+		voucher.ID,
+	)
+
+	return err
+}
+
+// UpdatePurchaseLimits sets the per-user purchase limits of a voucher (nil means unlimited)
+func (r *VoucherRepository) UpdatePurchaseLimits(ctx context.Context, voucherID int, maxPerUser, maxPerUserPerDay *int) error {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.UpdatePurchaseLimits")
+	defer span.End()
+
+	query := `
+		UPDATE vouchers
+		SET max_per_user = $1, max_per_user_per_day = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.ExecContext(ctx, query, maxPerUser, maxPerUserPerDay, time.Now(), voucherID)
+	return err
+}
+
+// UpdateVoucherQuantity updates the quantity of a voucher (used in transactions)
+func (r *VoucherRepository) UpdateVoucherQuantity(ctx context.Context, tx repository.Tx, voucherID int, quantityChange int) error {
+	ctx, span := tracing.StartQuery(ctx, "VoucherRepository.UpdateVoucherQuantity")
+	defer span.End()
+
+	query := `
+		UPDATE vouchers
+		SET quantity = quantity + $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query, quantityChange, time.Now(), voucherID)
+	return err
+}
+
@@ -1,8 +1,12 @@
-package repository
+package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type WalletRepository struct {
@@ -15,25 +19,25 @@ func NewWalletRepository(db *sql.DB) *WalletRepository {
 }
 
 // UpdateBalance updates wallet balance (supports transactions for ACID)
-func (r *WalletRepository) UpdateBalance(tx *sql.Tx, userID int, amountChange float64) error {
+func (r *WalletRepository) UpdateBalance(ctx context.Context, tx repository.Tx, userID int, amountChange float64) error {
+	ctx, span := tracing.StartQuery(ctx, "WalletRepository.UpdateBalance")
+	defer span.End()
+
 	query := `
 		UPDATE wallets
 		SET balance = balance + $1, updated_at = $2
 		WHERE user_id = $3
 	`
 
-	var err error
-	if tx != nil {
-		_, err = tx.Exec(query, amountChange, time.Now(), userID)
-	} else {
-		_, err = r.db.Exec(query, amountChange, time.Now(), userID)
-	}
-
+	_, err := conn(r.db, tx).ExecContext(ctx, query, amountChange, time.Now(), userID)
 	return err
 }
 
 // GetBalance retrieves current balance for a user
-func (r *WalletRepository) GetBalance(userID int) (float64, error) {
+func (r *WalletRepository) GetBalance(ctx context.Context, userID int) (float64, error) {
+	ctx, span := tracing.StartQuery(ctx, "WalletRepository.GetBalance")
+	defer span.End()
+
 	query := `
 		SELECT balance
 		FROM wallets
@@ -41,7 +45,7 @@ func (r *WalletRepository) GetBalance(userID int) (float64, error) {
 	`
 
 	var balance float64
-	err := r.db.QueryRow(query, userID).Scan(&balance)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&balance)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil // Wallet doesn't exist, return 0
@@ -0,0 +1,420 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookEndpointColumns = `
+	id, merchant_id, url, secret, event_types, status, consecutive_failures,
+	disabled_reason, disabled_at, created_at, updated_at
+`
+
+// scanWebhookEndpoint scans an endpoint row selected with webhookEndpointColumns
+func scanWebhookEndpoint(row rowScanner) (*model.WebhookEndpoint, error) {
+	endpoint := &model.WebhookEndpoint{}
+	var disabledReason sql.NullString
+	var disabledAt sql.NullTime
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.MerchantID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		pq.Array(&endpoint.EventTypes),
+		&endpoint.Status,
+		&endpoint.ConsecutiveFailures,
+		&disabledReason,
+		&disabledAt,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabledReason.Valid {
+		endpoint.DisabledReason = &disabledReason.String
+	}
+	if disabledAt.Valid {
+		endpoint.DisabledAt = &disabledAt.Time
+	}
+
+	return endpoint, nil
+}
+
+const webhookDeliveryColumns = `
+	id, endpoint_id, event_id, event_type, payload, status, attempts, last_status_code,
+	last_error, next_attempt_at, last_attempt_at, delivered_at, locked_until, created_at, updated_at
+`
+
+// scanWebhookDelivery scans a delivery row selected with webhookDeliveryColumns
+func scanWebhookDelivery(row rowScanner) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	var payload []byte
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	var lastAttemptAt, deliveredAt, lockedUntil sql.NullTime
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.EndpointID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&lastStatusCode,
+		&lastError,
+		&delivery.NextAttemptAt,
+		&lastAttemptAt,
+		&deliveredAt,
+		&lockedUntil,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	if lastStatusCode.Valid {
+		code := int(lastStatusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	if lastError.Valid {
+		delivery.LastError = &lastError.String
+	}
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	if lockedUntil.Valid {
+		delivery.LockedUntil = &lockedUntil.Time
+	}
+
+	return delivery, nil
+}
+
+// CreateEndpoint creates a new active endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.CreateEndpoint")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhook_endpoints (merchant_id, url, secret, event_types, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id
+	`
+
+	now := time.Now()
+	endpoint.Status = model.WebhookEndpointActive
+	endpoint.ConsecutiveFailures = 0
+	endpoint.CreatedAt = now
+	endpoint.UpdatedAt = now
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		endpoint.MerchantID,
+		endpoint.URL,
+		endpoint.Secret,
+		pq.Array(endpoint.EventTypes),
+		endpoint.Status,
+		now,
+	).Scan(&endpoint.ID)
+}
+
+// GetEndpointByID retrieves an endpoint by ID
+func (r *WebhookRepository) GetEndpointByID(ctx context.Context, id int) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.GetEndpointByID")
+	defer span.End()
+
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return endpoint, err
+}
+
+// ListEndpointsByMerchant retrieves a merchant's endpoints, oldest first
+func (r *WebhookRepository) ListEndpointsByMerchant(ctx context.Context, merchantID int) ([]*model.WebhookEndpoint, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.ListEndpointsByMerchant")
+	defer span.End()
+
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE merchant_id = $1 ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*model.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// EnableEndpoint makes an endpoint active again and clears its failure count
+func (r *WebhookRepository) EnableEndpoint(ctx context.Context, id int) error {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.EnableEndpoint")
+	defer span.End()
+
+	query := `
+		UPDATE webhook_endpoints
+		SET status = $1, consecutive_failures = 0, disabled_reason = NULL, disabled_at = NULL, updated_at = $2
+		WHERE id = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, model.WebhookEndpointActive, time.Now(), id)
+	return err
+}
+
+// DeleteEndpoint removes an endpoint; its deliveries are deleted by the foreign key
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.DeleteEndpoint")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RecordEndpointResult counts the endpoint's consecutive failures, disabling it at disableAfter.
+// The count is updated in one statement so concurrent results are not lost.
+func (r *WebhookRepository) RecordEndpointResult(ctx context.Context, id int, success bool, disableAfter int, reason string) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.RecordEndpointResult")
+	defer span.End()
+
+	now := time.Now()
+	if success {
+		query := `UPDATE webhook_endpoints SET consecutive_failures = 0, updated_at = $1 WHERE id = $2 AND consecutive_failures > 0`
+		_, err := r.db.ExecContext(ctx, query, now, id)
+		return false, err
+	}
+
+	// Only the failure that disables the endpoint stamps disabled_at with its own time
+	query := `
+		UPDATE webhook_endpoints
+		SET consecutive_failures = consecutive_failures + 1,
+			status = CASE WHEN status = $1 AND consecutive_failures + 1 >= $2 THEN $3 ELSE status END,
+			disabled_reason = CASE WHEN status = $1 AND consecutive_failures + 1 >= $2 THEN $4 ELSE disabled_reason END,
+			disabled_at = CASE WHEN status = $1 AND consecutive_failures + 1 >= $2 THEN $5 ELSE disabled_at END,
+			updated_at = $5
+		WHERE id = $6
+		RETURNING COALESCE(status = $3 AND disabled_at = $5, FALSE)
+	`
+
+	var disabled bool
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		model.WebhookEndpointActive,
+		disableAfter,
+		model.WebhookEndpointDisabled,
+		reason,
+		now,
+		id,
+	).Scan(&disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return disabled, err
+}
+
+// CreateDelivery adds a pending delivery unless the endpoint already has one for the event
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.CreateDelivery")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $6)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		RETURNING id
+	`
+
+	now := time.Now()
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		delivery.EndpointID,
+		delivery.EventID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		model.WebhookDeliveryPending,
+		now,
+	).Scan(&delivery.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil // Already delivered or pending
+	}
+	if err != nil {
+		return false, err
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	return true, nil
+}
+
+// GetDeliveryByID retrieves a delivery by ID
+func (r *WebhookRepository) GetDeliveryByID(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.GetDeliveryByID")
+	defer span.End()
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// ListDeliveries retrieves an endpoint's newest deliveries, optionally filtered by status
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID int, status *model.WebhookDeliveryStatus, limit int) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.ListDeliveries")
+	defer span.End()
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1 AND ($2::VARCHAR IS NULL OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, endpointID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// ClaimDueDeliveries locks pending deliveries of active endpoints that are due and not held by
+// another dispatcher until lockedUntil, oldest first. SKIP LOCKED lets concurrent dispatchers
+// claim different deliveries.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.ClaimDueDeliveries")
+	defer span.End()
+
+	query := `
+		WITH due AS (
+			SELECT d.id AS due_id -- Named apart from id, so RETURNING can use the plain column list
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = $1 AND d.next_attempt_at <= $2 AND e.status = $3
+				AND (d.locked_until IS NULL OR d.locked_until <= $2)
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT $5
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries
+		SET locked_until = $4
+		FROM due
+		WHERE webhook_deliveries.id = due.due_id
+		RETURNING ` + webhookDeliveryColumns
+
+	rows, err := r.db.QueryContext(ctx, query, model.WebhookDeliveryPending, now, model.WebhookEndpointActive, lockedUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the CTE's order
+	slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	return deliveries, nil
+}
+
+// scanWebhookDeliveries scans every delivery row selected with webhookDeliveryColumns
+func scanWebhookDeliveries(rows *sql.Rows) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery stores the delivery's status, attempts, last result and timestamps
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	ctx, span := tracing.StartQuery(ctx, "WebhookRepository.UpdateDelivery")
+	defer span.End()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5,
+			last_attempt_at = $6, delivered_at = $7, updated_at = $8, locked_until = NULL
+		WHERE id = $9
+	`
+
+	delivery.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.DeliveredAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	)
+	return err
+}
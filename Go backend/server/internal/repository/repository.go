@@ -0,0 +1,172 @@
+// Package repository defines the storage interfaces the services depend on.
+// The postgres package implements them on top of a database; the memory
+// package keeps everything in process for demos and end-to-end tests.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+// Tx is a unit of work spanning several repository calls.
+// Repository methods that take a Tx run inside it; a nil Tx runs the call on its own.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// TxManager starts units of work
+type TxManager interface {
+	BeginTx(ctx context.Context) (Tx, error)
+}
+
+// Repositories bundles one storage backend's repositories
+type Repositories struct {
+	Tx             TxManager
+	Users          UserRepository
+	Vouchers       VoucherRepository
+	Wallets        WalletRepository
+	Transactions   TransactionRepository
+	Promotions     PromotionRepository
+	PriceSchedules PriceScheduleRepository
+	Reservations   ReservationRepository
+	Merchants      MerchantRepository
+	Settlements    SettlementRepository
+	Outbox         OutboxRepository
+	Webhooks       WebhookRepository
+}
+
+// UserRepository stores users. Lookups return nil without an error when the user does not exist.
+type UserRepository interface {
+	GetUserByID(ctx context.Context, id int) (*model.User, error)
+	Login(ctx context.Context, email, password string) (*model.User, error)
+	SetMerchant(ctx context.Context, userID, merchantID int) error
+}
+
+// VoucherRepository stores vouchers. Vouchers are returned with their active flash sale
+// applied to Price and the units held by active reservations in Reserved.
+type VoucherRepository interface {
+	SearchVouchers(ctx context.Context, category string, merchantID *int, minPrice, maxPrice *float64) ([]*model.Voucher, error)
+	GetVoucherByID(ctx context.Context, id int) (*model.Voucher, error)
+	// GetVoucherByIDForUpdate locks the voucher until tx ends
+	GetVoucherByIDForUpdate(ctx context.Context, tx Tx, id int) (*model.Voucher, error)
+	ListVouchersByMerchant(ctx context.Context, merchantID int) ([]*model.Voucher, error)
+	CreateVoucher(ctx context.Context, voucher *model.Voucher) error
+	UpdateVoucher(ctx context.Context, voucher *model.Voucher) error
+	UpdatePurchaseLimits(ctx context.Context, voucherID int, maxPerUser, maxPerUserPerDay *int) error
+	UpdateVoucherQuantity(ctx context.Context, tx Tx, voucherID int, quantityChange int) error
+}
+
+// WalletRepository stores wallet balances
+type WalletRepository interface {
+	UpdateBalance(ctx context.Context, tx Tx, userID int, amountChange float64) error
+	GetBalance(ctx context.Context, userID int) (float64, error)
+}
+
+// TransactionRepository stores purchases, refunds and top-ups
+type TransactionRepository interface {
+	CreateTransaction(ctx context.Context, tx Tx, transaction *model.Transaction) error
+	// GetTransactionByIDForUpdate locks the transaction until tx ends
+	GetTransactionByIDForUpdate(ctx context.Context, tx Tx, id int) (*model.Transaction, error)
+	// HasRefund reports whether a refund returns the purchase
+	HasRefund(ctx context.Context, tx Tx, purchaseID int) (bool, error)
+	GetTransactionsByUserID(ctx context.Context, userID int) ([]*model.Transaction, error)
+	UpdateTransactionStatus(ctx context.Context, tx Tx, transactionID int, status model.PaymentStatus, paymentTxnID *string) error
+	MarkRedeemed(ctx context.Context, tx Tx, transactionID int, redeemedAt time.Time) error
+	CountUserPurchases(ctx context.Context, tx Tx, userID, voucherID int, since time.Time) (int, error)
+	GetUserPurchaseCounts(ctx context.Context, userID int, since time.Time) (map[int]int, error)
+}
+
+// PromotionRepository stores promo codes and their redemptions
+type PromotionRepository interface {
+	CreatePromotion(ctx context.Context, promotion *model.Promotion) error
+	GetPromotionByID(ctx context.Context, id int) (*model.Promotion, error)
+	// GetPromotionByCodeForUpdate locks the promotion until tx ends
+	GetPromotionByCodeForUpdate(ctx context.Context, tx Tx, code string) (*model.Promotion, error)
+	ListPromotions(ctx context.Context) ([]*model.Promotion, error)
+	SetPromotionActive(ctx context.Context, id int, active bool) error
+	CountUserRedemptions(ctx context.Context, tx Tx, promotionID, userID int) (int, error)
+	CreateRedemption(ctx context.Context, tx Tx, redemption *model.PromotionRedemption) error
+}
+
+// PriceScheduleRepository stores flash sale schedules
+type PriceScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error
+	GetScheduleByID(ctx context.Context, id int) (*model.PriceSchedule, error)
+	HasOverlappingSchedule(ctx context.Context, voucherID int, startsAt, endsAt time.Time) (bool, error)
+	CancelSchedule(ctx context.Context, id int) error
+	IncrementSoldCount(ctx context.Context, tx Tx, id int) error
+}
+
+// ReservationRepository stores stock holds
+type ReservationRepository interface {
+	CreateReservation(ctx context.Context, tx Tx, reservation *model.Reservation) error
+	GetReservationByID(ctx context.Context, id int) (*model.Reservation, error)
+	GetReservationByIDForUpdate(ctx context.Context, tx Tx, id int) (*model.Reservation, error)
+	// GetActiveReservationForUpdate locks the user's oldest unexpired hold until tx ends
+	GetActiveReservationForUpdate(ctx context.Context, tx Tx, userID, voucherID int) (*model.Reservation, error)
+	SumActiveHolds(ctx context.Context, tx Tx, voucherID int) (int, error)
+	UpdateReservation(ctx context.Context, tx Tx, id, quantity int, status model.ReservationStatus) error
+	ExpireReservations(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MerchantRepository stores merchants
+type MerchantRepository interface {
+	CreateMerchant(ctx context.Context, merchant *model.Merchant) error
+	GetMerchantByID(ctx context.Context, id int) (*model.Merchant, error)
+	GetSalesByMerchantID(ctx context.Context, merchantID int) ([]*model.MerchantSale, error)
+}
+
+// SettlementRepository stores settlement batches; closed batches cannot change
+type SettlementRepository interface {
+	GetLatestPeriodEnd(ctx context.Context, tx Tx, merchantID int) (*time.Time, error)
+	GetUnsettledTransactions(ctx context.Context, tx Tx, merchantID int, periodEnd time.Time) ([]*model.SettlementLineItem, error)
+	CreateBatch(ctx context.Context, tx Tx, batch *model.SettlementBatch) error
+	GetBatchByID(ctx context.Context, id int) (*model.SettlementBatch, error)
+	GetLineItems(ctx context.Context, batchID int) ([]*model.SettlementLineItem, error)
+	ListBatches(ctx context.Context, merchantID *int) ([]*model.SettlementBatch, error)
+	CloseBatch(ctx context.Context, id int, closedAt time.Time) (bool, error)
+	DeleteOpenBatch(ctx context.Context, id int) (bool, error)
+}
+
+// OutboxRepository stores domain events until the outbox relay has delivered them
+type OutboxRepository interface {
+	// AddEvent records an event; pass the tx of the change it describes so both commit together
+	AddEvent(ctx context.Context, tx Tx, event *model.OutboxEvent) error
+	// ClaimDueEvents locks up to limit pending events due at now until lockedUntil and returns them,
+	// oldest first. Events another relay holds are skipped. Only the oldest pending event of each
+	// aggregate is claimed, so an aggregate's events are delivered in order.
+	ClaimDueEvents(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.OutboxEvent, error)
+	// SaveDelivery stores the event's status, attempts, delivered sinks, last error and timestamps,
+	// and releases the claim
+	SaveDelivery(ctx context.Context, event *model.OutboxEvent) error
+	DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebhookRepository stores merchant webhook endpoints and their delivery log
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) error
+	GetEndpointByID(ctx context.Context, id int) (*model.WebhookEndpoint, error)
+	ListEndpointsByMerchant(ctx context.Context, merchantID int) ([]*model.WebhookEndpoint, error)
+	// EnableEndpoint makes an endpoint active again and clears its failure count
+	EnableEndpoint(ctx context.Context, id int) error
+	// DeleteEndpoint removes an endpoint with its deliveries; false if it did not exist
+	DeleteEndpoint(ctx context.Context, id int) (bool, error)
+	// RecordEndpointResult clears the endpoint's consecutive failures after a success, or counts
+	// a failure and disables the endpoint with reason once disableAfter failures are reached.
+	// It reports whether this call disabled the endpoint.
+	RecordEndpointResult(ctx context.Context, id int, success bool, disableAfter int, reason string) (bool, error)
+	// CreateDelivery adds a pending delivery; false if the endpoint already has one for the event
+	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (bool, error)
+	GetDeliveryByID(ctx context.Context, id int) (*model.WebhookDelivery, error)
+	// ListDeliveries returns an endpoint's newest deliveries, optionally only those with status
+	ListDeliveries(ctx context.Context, endpointID int, status *model.WebhookDeliveryStatus, limit int) ([]*model.WebhookDelivery, error)
+	// ClaimDueDeliveries locks up to limit pending deliveries of active endpoints due at now until
+	// lockedUntil and returns them, oldest first. Deliveries another dispatcher holds are skipped.
+	ClaimDueDeliveries(ctx context.Context, now, lockedUntil time.Time, limit int) ([]*model.WebhookDelivery, error)
+	// UpdateDelivery stores the delivery's status, attempts, last result and timestamps, and
+	// releases the claim
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
+)
+
+// LoginGuard slows down password guessing by locking out an email address after repeated
+// failed logins, and a client IP after failures across many addresses
+type LoginGuard struct {
+	byEmail *ratelimit.Lockout
+	byIP    *ratelimit.Lockout
+}
+
+// NewLoginGuard creates a login guard keeping its state in store
+func NewLoginGuard(store ratelimit.Store, emailPolicy, ipPolicy ratelimit.LockoutPolicy) *LoginGuard {
+	return &LoginGuard{
+		byEmail: ratelimit.NewLockout(store, "login:email:", emailPolicy),
+		byIP:    ratelimit.NewLockout(store, "login:ip:", ipPolicy),
+	}
+}
+
+// Check returns how long the caller must wait before trying to log in, 0 if they may try now.
+// Store errors are logged and let the attempt through.
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) time.Duration {
+	var wait time.Duration
+	for _, check := range []struct {
+		lockout *ratelimit.Lockout
+		key     string
+	}{
+		{g.byEmail, normalizeEmail(email)},
+		{g.byIP, ip},
+	} {
+		if check.key == "" {
+			continue
+		}
+		locked, err := check.lockout.Check(ctx, check.key)
+		if err != nil {
+			slog.Warn("Login lockout check failed", "error", err)
+			continue
+		}
+		wait = max(wait, locked)
+	}
+	return wait
+}
+
+// Failed records a failed login and returns how long the caller is now locked out, 0 if not yet
+func (g *LoginGuard) Failed(ctx context.Context, email, ip string) time.Duration {
+	wait, err := g.byEmail.Fail(ctx, normalizeEmail(email))
+	if err != nil {
+		slog.Warn("Failed to record failed login", "error", err)
+	}
+
+	if ip != "" {
+		ipWait, err := g.byIP.Fail(ctx, ip)
+		if err != nil {
+			slog.Warn("Failed to record failed login", "error", err)
+		}
+		wait = max(wait, ipWait)
+	}
+
+	if wait > 0 {
+		slog.Warn("Login locked out after repeated failures", "ip", ip, "lockout", wait)
+	}
+	return wait
+}
+
+// Succeeded clears the failures of an email address. Failures of the IP are kept, so knowing
+// one valid password does not let a client keep guessing others.
+func (g *LoginGuard) Succeeded(ctx context.Context, email string) {
+	if err := g.byEmail.Reset(ctx, normalizeEmail(email)); err != nil {
+		slog.Warn("Failed to reset login failures", "error", err)
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
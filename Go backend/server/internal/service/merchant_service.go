@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type MerchantService struct {
+	merchantRepo   repository.MerchantRepository
+	voucherRepo    repository.VoucherRepository
+	userService    *UserService
+	voucherService *VoucherService
+}
+
+// NewMerchantService creates a new merchant service
+func NewMerchantService(
+	merchantRepo repository.MerchantRepository,
+	voucherRepo repository.VoucherRepository,
+	userService *UserService,
+	voucherService *VoucherService,
+) *MerchantService {
+	return &MerchantService{
+		merchantRepo:   merchantRepo,
+		voucherRepo:    voucherRepo,
+		userService:    userService,
+		voucherService: voucherService,
+	}
+}
+
+// CreateMerchant onboards a new merchant (admin only)
+func (s *MerchantService) CreateMerchant(ctx context.Context, adminID int, merchant *model.Merchant) (*model.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.CreateMerchant")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	merchant.Name = strings.TrimSpace(merchant.Name)
+	merchant.Email = strings.TrimSpace(merchant.Email)
+	if merchant.Name == "" || merchant.Email == "" {
+		return nil, errors.New("invalid merchant: name and email are required")
+	}
+
+	if err := s.merchantRepo.CreateMerchant(ctx, merchant); err != nil {
+		if strings.Contains(err.Error(), "merchant already exists") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create merchant: %w", err)
+	}
+
+	return merchant, nil
+}
+
+// AddMerchantUser gives a user access to a merchant's scoped RPCs (admin only)
+func (s *MerchantService) AddMerchantUser(ctx context.Context, adminID, merchantID, userID int) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.AddMerchantUser")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetMerchantByID(ctx, merchantID); err != nil {
+		return nil, err
+	}
+
+	return s.userService.AssignMerchant(ctx, userID, merchantID)
+}
+
+// GetMerchantByID retrieves a merchant by ID
+func (s *MerchantService) GetMerchantByID(ctx context.Context, merchantID int) (*model.Merchant, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.GetMerchantByID")
+	defer span.End()
+
+	if merchantID <= 0 {
+		return nil, errors.New("invalid merchant ID")
+	}
+
+	merchant, err := s.merchantRepo.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if merchant == nil {
+		return nil, errors.New("merchant not found")
+	}
+
+	return merchant, nil
+}
+
+// ListVouchers retrieves all vouchers of the merchant user's merchant
+func (s *MerchantService) ListVouchers(ctx context.Context, userID int) ([]*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.ListVouchers")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	vouchers, err := s.voucherRepo.ListVouchersByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchant vouchers: %w", err)
+	}
+
+	return vouchers, nil
+}
+
+// CreateVoucher creates a voucher owned by the merchant user's merchant
+func (s *MerchantService) CreateVoucher(ctx context.Context, userID int, voucher *model.Voucher) (*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.CreateVoucher")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateVoucherDetails(voucher); err != nil {
+		return nil, err
+	}
+
+	voucher.MerchantID = &merchantID
+	voucher.Price = voucher.RegularPrice
+	if err := s.voucherRepo.CreateVoucher(ctx, voucher); err != nil {
+		return nil, fmt.Errorf("failed to create voucher: %w", err)
+	}
+
+	return voucher, nil
+}
+
+// UpdateVoucher updates a voucher owned by the merchant user's merchant
+func (s *MerchantService) UpdateVoucher(ctx context.Context, userID int, update *model.Voucher) (*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.UpdateVoucher")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher, err := s.voucherService.GetVoucherByID(ctx, update.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Vouchers of other merchants are reported as not found
+	if voucher.MerchantID == nil || *voucher.MerchantID != merchantID {
+		return nil, errors.New("voucher not found")
+	}
+
+	if err := validateVoucherDetails(update); err != nil {
+		return nil, err
+	}
+
+	voucher.Name = update.Name
+	voucher.Description = update.Description
+	voucher.Category = update.Category
+	voucher.RegularPrice = update.RegularPrice
+	voucher.Quantity = update.Quantity
+	voucher.ValidFrom = update.ValidFrom
+	voucher.ValidTo = update.ValidTo
+	if voucher.ActiveSale == nil {
+		voucher.Price = voucher.RegularPrice
+	}
+
+	if err := s.voucherRepo.UpdateVoucher(ctx, voucher); err != nil {
+		return nil, fmt.Errorf("failed to update voucher: %w", err)
+	}
+
+	return voucher, nil
+}
+
+// ListSales retrieves successful sales of the merchant user's merchant and their total revenue
+func (s *MerchantService) ListSales(ctx context.Context, userID int) ([]*model.MerchantSale, float64, error) {
+	ctx, span := tracing.Start(ctx, "MerchantService.ListSales")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sales, err := s.merchantRepo.GetSalesByMerchantID(ctx, merchantID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get merchant sales: %w", err)
+	}
+
+	var total float64
+	for _, sale := range sales {
+		total += sale.Amount
+	}
+
+	return sales, total, nil
+}
+
+// validateVoucherDetails checks the merchant-editable fields of a voucher
+func validateVoucherDetails(voucher *model.Voucher) error {
+	if strings.TrimSpace(voucher.Name) == "" || strings.TrimSpace(voucher.Category) == "" {
+		return errors.New("invalid voucher: name and category are required")
+	}
+
+	if voucher.RegularPrice < 0 {
+		return errors.New("invalid voucher: price cannot be negative")
+	}
+
+	if voucher.Quantity < 0 {
+		return errors.New("invalid voucher: quantity cannot be negative")
+	}
+
+	if !voucher.ValidTo.After(voucher.ValidFrom) {
+		return errors.New("invalid voucher: valid_to must be after valid_from")
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
+)
+
+// userRows returns the users as rows of the repository's user columns
+func userRows(users ...*model.User) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "role", "merchant_id", "created_at", "updated_at"})
+	for _, u := range users {
+		rows.AddRow(u.ID, u.Name, u.Email, string(u.Role), nullable(u.MerchantID), u.CreatedAt, u.UpdatedAt)
+	}
+	return rows
+}
+
+func TestMerchantUpdateVoucher(t *testing.T) {
+	const userID, voucherID = 7, 1
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		user            *model.User
+		voucherMerchant *int // Owner of the stored voucher, nil for a platform voucher
+		wantErr         string
+	}{
+		{
+			name:            "own voucher",
+			user:            &model.User{ID: userID, Role: model.UserRoleMerchant, MerchantID: ptr(3)},
+			voucherMerchant: ptr(3),
+		},
+		{
+			name:            "another merchant's voucher",
+			user:            &model.User{ID: userID, Role: model.UserRoleMerchant, MerchantID: ptr(3)},
+			voucherMerchant: ptr(4),
+			wantErr:         "voucher not found",
+		},
+		{
+			name:    "platform voucher",
+			user:    &model.User{ID: userID, Role: model.UserRoleMerchant, MerchantID: ptr(3)},
+			wantErr: "voucher not found",
+		},
+		{
+			name:            "customer",
+			user:            &model.User{ID: userID, Role: model.UserRoleCustomer},
+			voucherMerchant: ptr(3),
+			wantErr:         "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() error = %v", err)
+			}
+			defer db.Close()
+
+			voucherRepo := postgres.NewVoucherRepository(db)
+			userService := NewUserService(postgres.NewUserRepository(db))
+			service := NewMerchantService(
+				postgres.NewMerchantRepository(db),
+				voucherRepo,
+				userService,
+				NewVoucherService(voucherRepo, postgres.NewTransactionRepository(db), userService),
+			)
+
+			stored := &model.Voucher{
+				ID:           voucherID,
+				Name:         "Coffee",
+				Category:     "food",
+				MerchantID:   tt.voucherMerchant,
+				RegularPrice: 150,
+				Quantity:     10,
+				ValidFrom:    now.Add(-time.Hour),
+				ValidTo:      now.Add(time.Hour),
+			}
+			update := &model.Voucher{
+				ID:           voucherID,
+				Name:         "Large coffee",
+				Category:     "food",
+				RegularPrice: 180,
+				Quantity:     20,
+				ValidFrom:    stored.ValidFrom,
+				ValidTo:      stored.ValidTo.Add(time.Hour),
+			}
+
+			mock.ExpectQuery(regexp.QuoteMeta("FROM users")).WithArgs(userID).WillReturnRows(userRows(tt.user))
+			if tt.user.Role == model.UserRoleMerchant {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM vouchers")).WithArgs(sqlmock.AnyArg(), voucherID).WillReturnRows(voucherRows(stored))
+			}
+			if tt.wantErr == "" {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE vouchers")).
+					WithArgs(update.Name, "", update.Category, update.RegularPrice, update.Quantity, update.ValidFrom, update.ValidTo, sqlmock.AnyArg(), voucherID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			updated, err := service.UpdateVoucher(context.Background(), userID, update)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("UpdateVoucher() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("UpdateVoucher() error = %v", err)
+			} else if updated.Name != update.Name || updated.Price != update.RegularPrice || *updated.MerchantID != *tt.voucherMerchant {
+				t.Errorf("UpdateVoucher() = %+v", updated)
+			}
+
+			// Rejected updates must not reach the vouchers table
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PaymentResult represents the result of a payment processing
@@ -28,14 +34,49 @@ func NewMockUPI(successRate float64) *MockUPI {
 	}
 }
 
+// Check reports whether the payment gateway can take payments. The mock runs in process and is
+// always reachable; a real gateway would call its status endpoint here.
+func (m *MockUPI) Check(ctx context.Context) error {
+	return ctx.Err()
+}
+
 // ProcessPayment simulates payment processing with network delay
-func (m *MockUPI) ProcessPayment(amount float64, userID, transactionID int) (*PaymentResult, error) {
+func (m *MockUPI) ProcessPayment(ctx context.Context, amount float64, userID, transactionID int) (*PaymentResult, error) {
+	ctx, span := tracing.Start(ctx, "MockUPI.ProcessPayment",
+		attribute.Float64("payment.amount", amount),
+		attribute.Int("transaction.id", transactionID),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result, err := m.processPayment(ctx, amount, userID, transactionID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	metrics.ObservePayment(result.Success, time.Since(start))
+	span.SetAttributes(attribute.Bool("payment.success", result.Success))
+	if !result.Success {
+		tracing.RecordError(span, errors.New(result.Message))
+	}
+	return result, nil
+}
+
+// processPayment simulates the gateway call itself; the delay is abandoned if ctx is cancelled
+func (m *MockUPI) processPayment(ctx context.Context, amount float64, userID, transactionID int) (*PaymentResult, error) {
 	// Create random generator
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	
 	// Simulate network delay (100-500ms)
 	delay := time.Duration(100+r.Intn(400)) * time.Millisecond
-	time.Sleep(delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	// Validate amount
 	if amount <= 0 {
@@ -1,72 +1,92 @@
 package service
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/events"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type PaymentService struct {
-	db                *sql.DB
-	userService       *UserService
-	voucherService    *VoucherService
-	walletService     *WalletService
-	voucherRepo       *repository.VoucherRepository
-	transactionRepo   *repository.TransactionRepository
-	mockUPI           *MockUPI
+	txManager            repository.TxManager
+	userService          *UserService
+	voucherService       *VoucherService
+	walletService        *WalletService
+	promotionService     *PromotionService
+	priceScheduleService *PriceScheduleService
+	reservationService   *ReservationService
+	voucherRepo          repository.VoucherRepository
+	transactionRepo      repository.TransactionRepository
+	outboxRepo           repository.OutboxRepository
+	mockUPI              *MockUPI
+	events               *events.Bus
 }
 
 // NewPaymentService creates a new payment service
 func NewPaymentService(
-	db *sql.DB,
+	txManager repository.TxManager,
 	userService *UserService,
 	voucherService *VoucherService,
 	walletService *WalletService,
-	voucherRepo *repository.VoucherRepository,
-	transactionRepo *repository.TransactionRepository,
+	promotionService *PromotionService,
+	priceScheduleService *PriceScheduleService,
+	reservationService *ReservationService,
+	voucherRepo repository.VoucherRepository,
+	transactionRepo repository.TransactionRepository,
+	outboxRepo repository.OutboxRepository,
 	mockUPI *MockUPI,
+	eventBus *events.Bus,
 ) *PaymentService {
 	return &PaymentService{
-		db:              db,
-		userService:     userService,
-		voucherService:  voucherService,
-		walletService:   walletService,
-		voucherRepo:     voucherRepo,
-		transactionRepo: transactionRepo,
-		mockUPI:         mockUPI,
+		txManager:            txManager,
+		userService:          userService,
+		voucherService:       voucherService,
+		walletService:        walletService,
+		promotionService:     promotionService,
+		priceScheduleService: priceScheduleService,
+		reservationService:   reservationService,
+		voucherRepo:          voucherRepo,
+		transactionRepo:      transactionRepo,
+		outboxRepo:           outboxRepo,
+		mockUPI:              mockUPI,
+		events:               eventBus,
 	}
 }
 
-// BuyVoucher orchestrates the complete voucher purchase flow with ACID transactions
-func (s *PaymentService) BuyVoucher(userID, voucherID int) (*model.Transaction, error) {
+// BuyVoucher orchestrates the complete voucher purchase flow with ACID transactions.
+// An optional promo code is applied to the voucher price before payment.
+func (s *PaymentService) BuyVoucher(ctx context.Context, userID, voucherID int, promoCode string) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.BuyVoucher")
+	defer span.End()
+
 	// Step 1: Validate user exists
-	if err := s.userService.ValidateUserExists(userID); err != nil {
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
 		return nil, err
 	}
 
 	// Step 2: Validate voucher is available
-	if err := s.voucherService.ValidateVoucherAvailable(voucherID); err != nil {
+	if err := s.voucherService.ValidateVoucherAvailable(ctx, voucherID); err != nil {
 		return nil, err
 	}
 
 	// Step 3: Get voucher details (need price)
-	voucher, err := s.voucherService.GetVoucherByID(voucherID)
+	voucher, err := s.voucherService.GetVoucherByID(ctx, voucherID)
 	if err != nil {
 		return nil, err
 	}
 
-	amount := voucher.Price
-
-	// Step 4: Validate sufficient balance
-	if err := s.walletService.ValidateSufficientBalance(userID, amount); err != nil {
-		return nil, err
-	}
-
-	// Step 5: Start database transaction
-	tx, err := s.db.Begin()
+	// Step 4: Start database transaction
+	tx, err := s.txManager.BeginTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
@@ -74,59 +94,423 @@ func (s *PaymentService) BuyVoucher(userID, voucherID int) (*model.Transaction,
 	// Defer rollback in case of error
 	defer tx.Rollback()
 
-	// Step 6: Deduct from wallet
-	if err := s.walletService.DeductBalance(tx, userID, amount); err != nil {
+	// Step 5: Lock voucher and enforce stock and per-user purchase limits.
+	// The locked row carries the current effective (flash sale) price.
+	voucher, err = s.voucherService.LockVoucherForPurchase(ctx, tx, userID, voucherID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consume the user's stock hold, or make sure the unit isn't held by someone else
+	if err := s.reservationService.ClaimStock(ctx, tx, userID, voucher); err != nil {
 		return nil, err
 	}
 
-	// Step 7: Create transaction record (pending status)
+	// Flash sale units are capped; the locked voucher row serializes this count
+	if voucher.ActiveSale != nil {
+		if err := s.priceScheduleService.RecordSale(ctx, tx, voucher.ActiveSale.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 6: Apply promo code (locks the promotion row so usage limits hold under concurrency)
+	var promotion *model.Promotion
+	var discount float64
+	if promoCode != "" {
+		promotion, discount, err = s.promotionService.ApplyPromotion(ctx, tx, userID, promoCode, voucher)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	amount := math.Round((voucher.Price-discount)*100) / 100
+
+	// Step 7: Deduct from wallet (fully discounted vouchers skip wallet and payment)
+	if amount > 0 {
+		if err := s.walletService.DeductBalance(ctx, tx, userID, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 8: Create transaction record (pending status)
 	transaction := &model.Transaction{
-		UserID:           userID,
-		VoucherID:        &voucherID,
-		Amount:           amount,
-		TransactionType:  model.TransactionTypePurchase,
-		PaymentStatus:    model.PaymentStatusPending,
-		PaymentTxnID:     nil,
+		UserID:          userID,
+		VoucherID:       &voucherID,
+		Amount:          amount,
+		TransactionType: model.TransactionTypePurchase,
+		PaymentStatus:   model.PaymentStatusPending,
+		PaymentTxnID:    nil,
+		DiscountAmount:  discount,
+	}
+	if promotion != nil {
+		transaction.PromotionID = &promotion.ID
 	}
 
-	if err := s.transactionRepo.CreateTransaction(tx, transaction); err != nil {
+	if err := s.transactionRepo.CreateTransaction(ctx, tx, transaction); err != nil {
 		return nil, fmt.Errorf("failed to create transaction record: %w", err)
 	}
+	created := *transaction // As first recorded, for the created event
+
+	// Step 9: Record promo code redemption
+	if promotion != nil {
+		if err := s.promotionService.RecordRedemption(ctx, tx, promotion.ID, userID, transaction.ID, discount); err != nil {
+			return nil, err
+		}
+	}
 
-	// Step 8: Update voucher quantity (decrease by 1)
-	if err := s.voucherRepo.UpdateVoucherQuantity(tx, voucherID, -1); err != nil {
+	// Step 10: Update voucher quantity (decrease by 1)
+	if err := s.voucherRepo.UpdateVoucherQuantity(ctx, tx, voucherID, -1); err != nil {
 		return nil, fmt.Errorf("failed to update voucher quantity: %w", err)
 	}
 
-	// Step 9: Process payment via Mock UPI
-	paymentResult, err := s.mockUPI.ProcessPayment(amount, userID, transaction.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to process payment: %w", err)
+	// Step 11: Process payment via Mock UPI
+	paymentResult := &PaymentResult{Success: true}
+	if amount > 0 {
+		paymentResult, err = s.mockUPI.ProcessPayment(ctx, amount, userID, transaction.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process payment: %w", err)
+		}
 	}
 
-	// Step 10: Update transaction status based on payment result
+	// Step 12: Update transaction status based on payment result
 	var paymentTxnID *string
 	if paymentResult.Success {
-		paymentTxnID = &paymentResult.PaymentTxnID
-		if err := s.transactionRepo.UpdateTransactionStatus(tx, transaction.ID, model.PaymentStatusSuccess, paymentTxnID); err != nil {
+		if paymentResult.PaymentTxnID != "" {
+			paymentTxnID = &paymentResult.PaymentTxnID
+		}
+		if err := s.transactionRepo.UpdateTransactionStatus(ctx, tx, transaction.ID, model.PaymentStatusSuccess, paymentTxnID); err != nil {
 			return nil, fmt.Errorf("failed to update transaction status: %w", err)
 		}
 		transaction.PaymentStatus = model.PaymentStatusSuccess
 		transaction.PaymentTxnID = paymentTxnID
 	} else {
 		// Payment failed - rollback will happen automatically
-		if err := s.transactionRepo.UpdateTransactionStatus(tx, transaction.ID, model.PaymentStatusFailed, nil); err != nil {
+		if err := s.transactionRepo.UpdateTransactionStatus(ctx, tx, transaction.ID, model.PaymentStatusFailed, nil); err != nil {
 			return nil, fmt.Errorf("failed to update transaction status: %w", err)
 		}
 		transaction.PaymentStatus = model.PaymentStatusFailed
 		return nil, errors.New("payment processing failed")
 	}
 
-	// Step 11: Commit transaction (all operations succeeded)
+	// Step 13: Record the purchase in the outbox, so it is published exactly when it commits
+	if err := s.recordTransactionEvent(ctx, tx, transaction, voucher); err != nil {
+		return nil, err
+	}
+
+	// Step 14: Commit transaction (all operations succeeded)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	metrics.RecordSale(voucher.Category, amount, discount)
+
+	// Step 15: Tell clients watching the user's transactions, now that the purchase is committed
+	s.events.Publish(events.TransactionEvent{Type: events.TransactionCreated, Transaction: created})
+	s.events.Publish(events.TransactionEvent{Type: events.TransactionStatusChanged, Transaction: *transaction})
+
+	return transaction, nil
+}
+
+// RefundPurchase returns a successful purchase: the amount paid goes back to the wallet and
+// the voucher goes back into stock. A purchase is refunded at most once.
+func (s *PaymentService) RefundPurchase(ctx context.Context, userID, purchaseID int) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.RefundPurchase")
+	defer span.End()
+
+	if purchaseID <= 0 {
+		return nil, errors.New("invalid transaction ID")
+	}
+
+	// Step 1: Validate user exists
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Start database transaction
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// Defer rollback in case of error
+	defer tx.Rollback()
+
+	// Step 3: Lock the purchase, so concurrent refunds of it wait for this one
+	purchase, err := s.transactionRepo.GetTransactionByIDForUpdate(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transaction: %w", err)
+	}
+	// Other users' transactions are reported as missing, so IDs can't be probed
+	if purchase == nil || purchase.UserID != userID {
+		return nil, errors.New("transaction not found")
+	}
+	if purchase.TransactionType != model.TransactionTypePurchase || purchase.PaymentStatus != model.PaymentStatusSuccess {
+		return nil, errors.New("only successful purchases can be refunded")
+	}
+
+	if purchase.RedeemedAt != nil {
+		return nil, errors.New("redeemed vouchers can't be refunded")
+	}
+
+	refunded, err := s.transactionRepo.HasRefund(ctx, tx, purchase.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for refunds: %w", err)
+	}
+	if refunded {
+		return nil, errors.New("purchase already refunded")
+	}
+
+	// Step 4: Lock the voucher the purchase bought, if it still exists
+	var voucher *model.Voucher
+	if purchase.VoucherID != nil {
+		voucher, err = s.voucherRepo.GetVoucherByIDForUpdate(ctx, tx, *purchase.VoucherID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock voucher: %w", err)
+		}
+	}
+
+	// Step 5: Return the amount paid (fully discounted purchases paid nothing)
+	if purchase.Amount > 0 {
+		if err := s.walletService.AddBalance(ctx, tx, userID, purchase.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 6: Create the refund record
+	refund := &model.Transaction{
+		UserID:                userID,
+		VoucherID:             purchase.VoucherID,
+		Amount:                purchase.Amount,
+		TransactionType:       model.TransactionTypeRefund,
+		PaymentStatus:         model.PaymentStatusSuccess,
+		OriginalTransactionID: &purchase.ID,
+	}
+	if err := s.transactionRepo.CreateTransaction(ctx, tx, refund); err != nil {
+		if strings.Contains(err.Error(), "already refunded") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+
+	// Step 7: Return the voucher to stock (increase by 1)
+	if voucher != nil {
+		if err := s.voucherRepo.UpdateVoucherQuantity(ctx, tx, voucher.ID, 1); err != nil {
+			return nil, fmt.Errorf("failed to update voucher quantity: %w", err)
+		}
+	}
+
+	// Step 8: Record the refund in the outbox, so it is published exactly when it commits
+	if err := s.recordTransactionEvent(ctx, tx, refund, voucher); err != nil {
+		return nil, err
+	}
+
+	// Step 9: Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	metrics.RecordRefund(refund.Amount)
+
+	s.events.Publish(events.TransactionEvent{Type: events.TransactionCreated, Transaction: *refund})
+
+	return refund, nil
+}
+
+// RedeemVoucher marks a purchased voucher used at the merchant. A purchase is redeemed at most
+// once, only while the voucher is valid, and not after it was refunded.
+func (s *PaymentService) RedeemVoucher(ctx context.Context, userID, purchaseID int) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.RedeemVoucher")
+	defer span.End()
+
+	if purchaseID <= 0 {
+		return nil, errors.New("invalid transaction ID")
+	}
+
+	// Step 1: Validate user exists
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Start database transaction
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// Defer rollback in case of error
+	defer tx.Rollback()
+
+	// Step 3: Lock the purchase, so it can't be refunded or redeemed again meanwhile
+	purchase, err := s.transactionRepo.GetTransactionByIDForUpdate(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transaction: %w", err)
+	}
+	// Other users' transactions are reported as missing, so IDs can't be probed
+	if purchase == nil || purchase.UserID != userID {
+		return nil, errors.New("transaction not found")
+	}
+	if purchase.TransactionType != model.TransactionTypePurchase || purchase.PaymentStatus != model.PaymentStatusSuccess ||
+		purchase.VoucherID == nil {
+		return nil, errors.New("only successful voucher purchases can be redeemed")
+	}
+	if purchase.RedeemedAt != nil {
+		return nil, errors.New("voucher already redeemed")
+	}
+
+	refunded, err := s.transactionRepo.HasRefund(ctx, tx, purchase.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for refunds: %w", err)
+	}
+	if refunded {
+		return nil, errors.New("refunded vouchers can't be redeemed")
+	}
+
+	// Step 4: The voucher must still be valid
+	voucher, err := s.voucherRepo.GetVoucherByIDForUpdate(ctx, tx, *purchase.VoucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock voucher: %w", err)
+	}
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+	now := time.Now()
+	if now.Before(voucher.ValidFrom) || now.After(voucher.ValidTo) {
+		return nil, errors.New("voucher expired")
+	}
+
+	// Step 5: Mark the purchase redeemed
+	if err := s.transactionRepo.MarkRedeemed(ctx, tx, purchase.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to mark voucher redeemed: %w", err)
+	}
+	purchase.RedeemedAt = &now
+	purchase.UpdatedAt = now
+
+	// Step 6: Record the redemption in the outbox, so it is published exactly when it commits
+	if err := s.recordEvent(ctx, tx, model.EventVoucherRedeemed, purchase, voucher); err != nil {
+		return nil, err
+	}
+
+	// Step 7: Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return purchase, nil
+}
+
+// maxTopUpAmount caps a single wallet top-up
+const maxTopUpAmount = 100000
+
+// TopUpWallet adds money to the user's wallet, paid through Mock UPI
+func (s *PaymentService) TopUpWallet(ctx context.Context, userID int, amount float64) (*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.TopUpWallet")
+	defer span.End()
+
+	// Amounts are in currency units with at most two decimals
+	if amount <= 0 || amount > maxTopUpAmount || math.Round(amount*100)/100 != amount {
+		return nil, errors.New("invalid amount")
+	}
+
+	// Step 1: Validate user exists
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Start database transaction
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// Defer rollback in case of error
+	defer tx.Rollback()
+
+	// Step 3: Create transaction record (pending status)
+	transaction := &model.Transaction{
+		UserID:          userID,
+		Amount:          amount,
+		TransactionType: model.TransactionTypeTopUp,
+		PaymentStatus:   model.PaymentStatusPending,
+	}
+	if err := s.transactionRepo.CreateTransaction(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+	created := *transaction // As first recorded, for the created event
+
+	// Step 4: Collect the payment via Mock UPI
+	paymentResult, err := s.mockUPI.ProcessPayment(ctx, amount, userID, transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process payment: %w", err)
+	}
+	if !paymentResult.Success {
+		// Payment failed - rollback will happen automatically
+		return nil, errors.New("payment processing failed")
+	}
+
+	// Step 5: Mark the top-up paid and credit the wallet
+	var paymentTxnID *string
+	if paymentResult.PaymentTxnID != "" {
+		paymentTxnID = &paymentResult.PaymentTxnID
+	}
+	if err := s.transactionRepo.UpdateTransactionStatus(ctx, tx, transaction.ID, model.PaymentStatusSuccess, paymentTxnID); err != nil {
+		return nil, fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	transaction.PaymentStatus = model.PaymentStatusSuccess
+	transaction.PaymentTxnID = paymentTxnID
+
+	if err := s.walletService.AddBalance(ctx, tx, userID, amount); err != nil {
+		return nil, err
+	}
+
+	// Step 6: Record the top-up in the outbox, so it is published exactly when it commits
+	if err := s.recordTransactionEvent(ctx, tx, transaction, nil); err != nil {
+		return nil, err
+	}
+
+	// Step 7: Commit transaction
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.events.Publish(events.TransactionEvent{Type: events.TransactionCreated, Transaction: created})
+	s.events.Publish(events.TransactionEvent{Type: events.TransactionStatusChanged, Transaction: *transaction})
+
 	return transaction, nil
 }
 
+// transactionEventTypes names the outbox event recorded for each type of committed transaction
+var transactionEventTypes = map[model.TransactionType]string{
+	model.TransactionTypePurchase: model.EventVoucherPurchased,
+	model.TransactionTypeRefund:   model.EventVoucherRefunded,
+	model.TransactionTypeTopUp:    model.EventWalletToppedUp,
+}
+
+// recordTransactionEvent adds the outbox event of a transaction inside tx; voucher is nil for top-ups
+func (s *PaymentService) recordTransactionEvent(ctx context.Context, tx repository.Tx, transaction *model.Transaction, voucher *model.Voucher) error {
+	return s.recordEvent(ctx, tx, transactionEventTypes[transaction.TransactionType], transaction, voucher)
+}
+
+// recordEvent adds an outbox event of the given type about a transaction inside tx
+func (s *PaymentService) recordEvent(ctx context.Context, tx repository.Tx, eventType string, transaction *model.Transaction, voucher *model.Voucher) error {
+	payload := model.TransactionEventPayload{Transaction: *transaction}
+	if voucher != nil {
+		payload.VoucherName = voucher.Name
+		payload.Category = voucher.Category
+		payload.MerchantID = voucher.MerchantID
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction event: %w", err)
+	}
+
+	event := &model.OutboxEvent{
+		AggregateType: model.AggregateUser,
+		AggregateID:   strconv.Itoa(transaction.UserID),
+		EventType:     eventType,
+		Payload:       data,
+	}
+	if err := s.outboxRepo.AddEvent(ctx, tx, event); err != nil {
+		return fmt.Errorf("failed to record transaction event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/events"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/outbox"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/memory"
+)
+
+// newPaymentTestService wires a payment service to the seeded in-memory store: customer 2 has
+// a wallet of 1000 and voucher 1 costs 150. Mock UPI always succeeds.
+func newPaymentTestService(t *testing.T) (*PaymentService, *repository.Repositories) {
+	t.Helper()
+
+	repos := memory.New()
+	userService := NewUserService(repos.Users)
+	voucherService := NewVoucherService(repos.Vouchers, repos.Transactions, userService)
+	service := NewPaymentService(
+		repos.Tx,
+		userService,
+		voucherService,
+		NewWalletService(repos.Wallets),
+		NewPromotionService(repos.Promotions, userService),
+		NewPriceScheduleService(repos.PriceSchedules, userService, voucherService),
+		NewReservationService(repos.Tx, repos.Reservations, repos.Vouchers, userService, time.Minute, time.Hour),
+		repos.Vouchers,
+		repos.Transactions,
+		repos.Outbox,
+		NewMockUPI(1),
+		events.NewBus(10),
+	)
+	return service, repos
+}
+
+// recordedEvents drains the outbox, returning every recorded event in order
+func recordedEvents(t *testing.T, repo repository.OutboxRepository) []*model.OutboxEvent {
+	t.Helper()
+	ctx := context.Background()
+
+	var recorded []*model.OutboxEvent
+	for {
+		now := time.Now()
+		claimed, err := repo.ClaimDueEvents(ctx, now, now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("ClaimDueEvents() error = %v", err)
+		}
+		if len(claimed) == 0 {
+			return recorded
+		}
+		for _, event := range claimed {
+			recorded = append(recorded, event)
+			event.Status = model.OutboxStatusDelivered
+			event.ProcessedAt = &now
+			if err := repo.SaveDelivery(ctx, event); err != nil {
+				t.Fatalf("SaveDelivery() error = %v", err)
+			}
+		}
+	}
+}
+
+func TestRefundPurchase(t *testing.T) {
+	ctx := context.Background()
+	service, repos := newPaymentTestService(t)
+
+	purchase, err := service.BuyVoucher(ctx, 2, 1, "")
+	if err != nil {
+		t.Fatalf("BuyVoucher() error = %v", err)
+	}
+
+	// Refunds are for the buyer's own successful purchases
+	if _, err := service.RefundPurchase(ctx, 1, purchase.ID); err == nil || err.Error() != "transaction not found" {
+		t.Errorf("refund by another user: error = %v, want transaction not found", err)
+	}
+	if _, err := service.RefundPurchase(ctx, 2, purchase.ID+100); err == nil || err.Error() != "transaction not found" {
+		t.Errorf("refund of a missing transaction: error = %v, want transaction not found", err)
+	}
+
+	refund, err := service.RefundPurchase(ctx, 2, purchase.ID)
+	if err != nil {
+		t.Fatalf("RefundPurchase() error = %v", err)
+	}
+	if refund.TransactionType != model.TransactionTypeRefund || refund.PaymentStatus != model.PaymentStatusSuccess {
+		t.Errorf("refund = %s/%s, want refund/success", refund.TransactionType, refund.PaymentStatus)
+	}
+	if refund.Amount != purchase.Amount || refund.OriginalTransactionID == nil || *refund.OriginalTransactionID != purchase.ID {
+		t.Errorf("refund amount %v of %v, want %v of purchase %d", refund.Amount, refund.OriginalTransactionID, purchase.Amount, purchase.ID)
+	}
+
+	// The wallet and stock are back where they started
+	balance, err := repos.Wallets.GetBalance(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 1000 {
+		t.Errorf("balance after refund = %v, want 1000", balance)
+	}
+	voucher, err := repos.Vouchers.GetVoucherByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetVoucherByID() error = %v", err)
+	}
+	if voucher.Quantity != 100 {
+		t.Errorf("quantity after refund = %d, want 100", voucher.Quantity)
+	}
+
+	if _, err := service.RefundPurchase(ctx, 2, purchase.ID); err == nil || err.Error() != "purchase already refunded" {
+		t.Errorf("second refund: error = %v, want purchase already refunded", err)
+	}
+	if _, err := service.RefundPurchase(ctx, 2, refund.ID); err == nil || err.Error() != "only successful purchases can be refunded" {
+		t.Errorf("refund of a refund: error = %v, want only successful purchases can be refunded", err)
+	}
+
+	// The refund's event was recorded with it, after the purchase's
+	recorded := recordedEvents(t, repos.Outbox)
+	var types []string
+	for _, event := range recorded {
+		types = append(types, event.EventType)
+	}
+	if want := []string{model.EventVoucherPurchased, model.EventVoucherRefunded}; !slices.Equal(types, want) {
+		t.Fatalf("recorded events = %v, want %v", types, want)
+	}
+	var payload model.TransactionEventPayload
+	if err := json.Unmarshal(recorded[1].Payload, &payload); err != nil {
+		t.Fatalf("refund payload: %v", err)
+	}
+	if payload.Transaction.ID != refund.ID || payload.VoucherName != voucher.Name {
+		t.Errorf("refund payload = transaction %d, voucher %q, want %d, %q", payload.Transaction.ID, payload.VoucherName, refund.ID, voucher.Name)
+	}
+}
+
+func TestTopUpWallet(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		userID  int
+		amount  float64
+		wantErr string
+	}{
+		{name: "top-up", userID: 2, amount: 250.5},
+		{name: "largest top-up", userID: 2, amount: maxTopUpAmount},
+		{name: "zero", userID: 2, amount: 0, wantErr: "invalid amount"},
+		{name: "negative", userID: 2, amount: -10, wantErr: "invalid amount"},
+		{name: "over the cap", userID: 2, amount: maxTopUpAmount + 1, wantErr: "invalid amount"},
+		{name: "fractions of a cent", userID: 2, amount: 10.005, wantErr: "invalid amount"},
+		{name: "unknown user", userID: 99, amount: 10, wantErr: "user not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repos := newPaymentTestService(t)
+
+			transaction, err := service.TopUpWallet(ctx, tt.userID, tt.amount)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("TopUpWallet() error = %v, want %q", err, tt.wantErr)
+				}
+				if recorded := recordedEvents(t, repos.Outbox); len(recorded) != 0 {
+					t.Errorf("failed top-up recorded %d events", len(recorded))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TopUpWallet() error = %v", err)
+			}
+
+			if transaction.TransactionType != model.TransactionTypeTopUp || transaction.PaymentStatus != model.PaymentStatusSuccess {
+				t.Errorf("top-up = %s/%s, want topup/success", transaction.TransactionType, transaction.PaymentStatus)
+			}
+			if transaction.PaymentTxnID == nil {
+				t.Error("top-up has no payment reference")
+			}
+
+			balance, err := repos.Wallets.GetBalance(ctx, tt.userID)
+			if err != nil {
+				t.Fatalf("GetBalance() error = %v", err)
+			}
+			if want := 1000 + tt.amount; balance != want {
+				t.Errorf("balance = %v, want %v", balance, want)
+			}
+
+			recorded := recordedEvents(t, repos.Outbox)
+			if len(recorded) != 1 || recorded[0].EventType != model.EventWalletToppedUp {
+				t.Fatalf("recorded %d events, want one %s", len(recorded), model.EventWalletToppedUp)
+			}
+			var payload model.TransactionEventPayload
+			if err := json.Unmarshal(recorded[0].Payload, &payload); err != nil {
+				t.Fatalf("top-up payload: %v", err)
+			}
+			if payload.Transaction.ID != transaction.ID || payload.Transaction.Amount != tt.amount {
+				t.Errorf("top-up payload = transaction %d of %v, want %d of %v", payload.Transaction.ID, payload.Transaction.Amount, transaction.ID, tt.amount)
+			}
+		})
+	}
+}
+
+func TestRedeemVoucher(t *testing.T) {
+	const merchantID = 3
+	ctx := context.Background()
+	service, repos := newPaymentTestService(t)
+
+	now := time.Now()
+	voucher := &model.Voucher{
+		Name:         "Museum Pass",
+		Category:     "entertainment",
+		RegularPrice: 200,
+		Quantity:     5,
+		ValidFrom:    now.Add(-time.Hour),
+		ValidTo:      now.Add(24 * time.Hour),
+		MerchantID:   ptr(merchantID),
+	}
+	if err := repos.Vouchers.CreateVoucher(ctx, voucher); err != nil {
+		t.Fatalf("CreateVoucher() error = %v", err)
+	}
+	redeemed, err := service.BuyVoucher(ctx, 2, voucher.ID, "")
+	if err != nil {
+		t.Fatalf("BuyVoucher() error = %v", err)
+	}
+	returned, err := service.BuyVoucher(ctx, 2, voucher.ID, "")
+	if err != nil {
+		t.Fatalf("BuyVoucher() error = %v", err)
+	}
+
+	if _, err := service.RedeemVoucher(ctx, 1, redeemed.ID); err == nil || err.Error() != "transaction not found" {
+		t.Errorf("redemption by another user: error = %v, want transaction not found", err)
+	}
+
+	purchase, err := service.RedeemVoucher(ctx, 2, redeemed.ID)
+	if err != nil {
+		t.Fatalf("RedeemVoucher() error = %v", err)
+	}
+	if purchase.ID != redeemed.ID || purchase.RedeemedAt == nil {
+		t.Errorf("redeemed purchase %d at %v, want purchase %d with a redemption time", purchase.ID, purchase.RedeemedAt, redeemed.ID)
+	}
+
+	// A voucher is used once, and a used voucher stays sold
+	if _, err := service.RedeemVoucher(ctx, 2, redeemed.ID); err == nil || err.Error() != "voucher already redeemed" {
+		t.Errorf("second redemption: error = %v, want voucher already redeemed", err)
+	}
+	if _, err := service.RefundPurchase(ctx, 2, redeemed.ID); err == nil || err.Error() != "redeemed vouchers can't be refunded" {
+		t.Errorf("refund of a redeemed purchase: error = %v, want redeemed vouchers can't be refunded", err)
+	}
+
+	// A refunded voucher can't be used
+	refund, err := service.RefundPurchase(ctx, 2, returned.ID)
+	if err != nil {
+		t.Fatalf("RefundPurchase() error = %v", err)
+	}
+	if _, err := service.RedeemVoucher(ctx, 2, returned.ID); err == nil || err.Error() != "refunded vouchers can't be redeemed" {
+		t.Errorf("redemption of a refunded purchase: error = %v, want refunded vouchers can't be redeemed", err)
+	}
+	if _, err := service.RedeemVoucher(ctx, 2, refund.ID); err == nil || err.Error() != "only successful voucher purchases can be redeemed" {
+		t.Errorf("redemption of a refund: error = %v, want only successful voucher purchases can be redeemed", err)
+	}
+
+	recorded := recordedEvents(t, repos.Outbox)
+	var types []string
+	for _, event := range recorded {
+		types = append(types, event.EventType)
+	}
+	want := []string{model.EventVoucherPurchased, model.EventVoucherPurchased, model.EventVoucherRedeemed, model.EventVoucherRefunded}
+	if !slices.Equal(types, want) {
+		t.Fatalf("recorded events = %v, want %v", types, want)
+	}
+
+	// The merchant's endpoint subscribed to redemptions is sent the redemption only
+	if err := repos.Users.SetMerchant(ctx, 1, merchantID); err != nil {
+		t.Fatalf("SetMerchant() error = %v", err)
+	}
+	webhooks := NewWebhookService(repos.Webhooks, NewUserService(repos.Users), WebhookSettings{})
+	endpoint, err := webhooks.CreateEndpoint(ctx, 1, "https://merchant.example/hooks", []string{model.WebhookEventVoucherRedeemed})
+	if err != nil {
+		t.Fatalf("CreateEndpoint() error = %v", err)
+	}
+	for _, event := range recorded {
+		msg := outbox.Message{ID: event.ID, Type: event.EventType, OccurredAt: event.CreatedAt, Payload: event.Payload}
+		if err := webhooks.HandleEvent(ctx, msg); err != nil {
+			t.Fatalf("HandleEvent(%s) error = %v", event.EventType, err)
+		}
+	}
+
+	deliveries, err := repos.Webhooks.ListDeliveries(ctx, endpoint.ID, nil, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries() = %d deliveries, %v; want 1", len(deliveries), err)
+	}
+	var payload model.WebhookPayload
+	if err := json.Unmarshal(deliveries[0].Payload, &payload); err != nil {
+		t.Fatalf("webhook payload: %v", err)
+	}
+	if payload.Type != model.WebhookEventVoucherRedeemed || payload.Data.TransactionID != redeemed.ID || payload.Data.RedeemedAt == nil {
+		t.Errorf("webhook payload = %s of transaction %d at %v, want %s of %d with a redemption time",
+			payload.Type, payload.Data.TransactionID, payload.Data.RedeemedAt, model.WebhookEventVoucherRedeemed, redeemed.ID)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type PriceScheduleService struct {
+	scheduleRepo   repository.PriceScheduleRepository
+	userService    *UserService
+	voucherService *VoucherService
+}
+
+// NewPriceScheduleService creates a new price schedule service
+func NewPriceScheduleService(scheduleRepo repository.PriceScheduleRepository, userService *UserService, voucherService *VoucherService) *PriceScheduleService {
+	return &PriceScheduleService{
+		scheduleRepo:   scheduleRepo,
+		userService:    userService,
+		voucherService: voucherService,
+	}
+}
+
+// CreateSchedule schedules a flash sale price for a voucher (admin only)
+func (s *PriceScheduleService) CreateSchedule(ctx context.Context, adminID int, schedule *model.PriceSchedule) (*model.PriceSchedule, error) {
+	ctx, span := tracing.Start(ctx, "PriceScheduleService.CreateSchedule")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	voucher, err := s.voucherService.GetVoucherByID(ctx, schedule.VoucherID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !schedule.EndsAt.After(schedule.StartsAt) {
+		return nil, errors.New("invalid price schedule: ends_at must be after starts_at")
+	}
+
+	if !schedule.EndsAt.After(time.Now()) {
+		return nil, errors.New("invalid price schedule: ends_at must be in the future")
+	}
+
+	if schedule.SalePrice < 0 || schedule.SalePrice >= voucher.RegularPrice {
+		return nil, errors.New("invalid price schedule: sale price must be below the regular price")
+	}
+
+	if schedule.StockCap != nil && *schedule.StockCap <= 0 {
+		return nil, errors.New("invalid price schedule: stock cap must be positive")
+	}
+
+	overlapping, err := s.scheduleRepo.HasOverlappingSchedule(ctx, schedule.VoucherID, schedule.StartsAt, schedule.EndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check price schedules: %w", err)
+	}
+
+	if overlapping {
+		return nil, errors.New("price schedule overlaps an existing schedule")
+	}
+
+	schedule.CreatedBy = &adminID
+	if err := s.scheduleRepo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to create price schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// CancelSchedule cancels a flash sale so the regular price applies again (admin only)
+func (s *PriceScheduleService) CancelSchedule(ctx context.Context, adminID, scheduleID int) (*model.PriceSchedule, error) {
+	ctx, span := tracing.Start(ctx, "PriceScheduleService.CancelSchedule")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	if scheduleID <= 0 {
+		return nil, errors.New("invalid price schedule ID")
+	}
+
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price schedule: %w", err)
+	}
+
+	if schedule == nil {
+		return nil, errors.New("price schedule not found")
+	}
+
+	if schedule.IsCancelled {
+		return nil, errors.New("price schedule already cancelled")
+	}
+
+	if !schedule.EndsAt.After(time.Now()) {
+		return nil, errors.New("price schedule already ended")
+	}
+
+	if err := s.scheduleRepo.CancelSchedule(ctx, scheduleID); err != nil {
+		return nil, fmt.Errorf("failed to cancel price schedule: %w", err)
+	}
+
+	schedule.IsCancelled = true
+	return schedule, nil
+}
+
+// RecordSale counts a unit sold at the sale price against the schedule's stock cap (used in transactions)
+func (s *PriceScheduleService) RecordSale(ctx context.Context, tx repository.Tx, scheduleID int) error {
+	ctx, span := tracing.Start(ctx, "PriceScheduleService.RecordSale")
+	defer span.End()
+
+	if err := s.scheduleRepo.IncrementSoldCount(ctx, tx, scheduleID); err != nil {
+		if err.Error() == "flash sale sold out" {
+			return err
+		}
+		return fmt.Errorf("failed to record flash sale: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
+)
+
+func TestRecordSale(t *testing.T) {
+	const scheduleID = 3
+
+	tests := []struct {
+		name    string
+		result  func(e *sqlmock.ExpectedExec)
+		wantErr string
+	}{
+		{
+			name:   "unit under the cap is counted",
+			result: func(e *sqlmock.ExpectedExec) { e.WillReturnResult(sqlmock.NewResult(0, 1)) },
+		},
+		{
+			name:    "cap reached",
+			result:  func(e *sqlmock.ExpectedExec) { e.WillReturnResult(sqlmock.NewResult(0, 0)) },
+			wantErr: "flash sale sold out",
+		},
+		{
+			name:    "database error",
+			result:  func(e *sqlmock.ExpectedExec) { e.WillReturnError(errors.New("connection reset")) },
+			wantErr: "failed to record flash sale: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() error = %v", err)
+			}
+			defer db.Close()
+			service := NewPriceScheduleService(postgres.NewPriceScheduleRepository(db), nil, nil)
+
+			mock.ExpectBegin()
+			// The cap is enforced by the update itself, so concurrent sales cannot overshoot it
+			tt.result(mock.ExpectExec(regexp.QuoteMeta("SET sold_count = sold_count + 1")+".*"+
+				regexp.QuoteMeta("WHERE id = $2 AND (stock_cap IS NULL OR sold_count < stock_cap)")).
+				WithArgs(sqlmock.AnyArg(), scheduleID))
+
+			tx, err := postgres.NewTxManager(db).BeginTx(context.Background())
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+
+			err = service.RecordSale(context.Background(), tx, scheduleID)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("RecordSale() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("RecordSale() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
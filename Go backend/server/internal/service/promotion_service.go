@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type PromotionService struct {
+	promotionRepo repository.PromotionRepository
+	userService   *UserService
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(promotionRepo repository.PromotionRepository, userService *UserService) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		userService:   userService,
+	}
+}
+
+// NormalizeCode trims and upper-cases a promo code so lookups are case-insensitive
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreatePromotion creates a new promo code (admin only)
+func (s *PromotionService) CreatePromotion(ctx context.Context, adminID int, promotion *model.Promotion) (*model.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "PromotionService.CreatePromotion")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	promotion.Code = NormalizeCode(promotion.Code)
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	promotion.IsActive = true
+	promotion.CreatedBy = &adminID
+
+	if err := s.promotionRepo.CreatePromotion(ctx, promotion); err != nil {
+		if strings.Contains(err.Error(), "promo code already exists") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	return promotion, nil
+}
+
+// ListPromotions retrieves all promo codes (admin only)
+func (s *PromotionService) ListPromotions(ctx context.Context, adminID int) ([]*model.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "PromotionService.ListPromotions")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	promotions, err := s.promotionRepo.ListPromotions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// DeactivatePromotion disables a promo code so it can no longer be redeemed (admin only)
+func (s *PromotionService) DeactivatePromotion(ctx context.Context, adminID, promotionID int) (*model.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "PromotionService.DeactivatePromotion")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	if promotionID <= 0 {
+		return nil, errors.New("invalid promotion ID")
+	}
+
+	promotion, err := s.promotionRepo.GetPromotionByID(ctx, promotionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	if promotion == nil {
+		return nil, errors.New("promotion not found")
+	}
+
+	if err := s.promotionRepo.SetPromotionActive(ctx, promotionID, false); err != nil {
+		return nil, fmt.Errorf("failed to deactivate promotion: %w", err)
+	}
+
+	promotion.IsActive = false
+	return promotion, nil
+}
+
+// ApplyPromotion locks the promo code row, checks every redemption rule for
+// this user and voucher, and returns the promotion with the discount to apply.
+// Must be called inside the purchase transaction.
+func (s *PromotionService) ApplyPromotion(ctx context.Context, tx repository.Tx, userID int, code string, voucher *model.Voucher) (*model.Promotion, float64, error) {
+	ctx, span := tracing.Start(ctx, "PromotionService.ApplyPromotion")
+	defer span.End()
+
+	code = NormalizeCode(code)
+	if code == "" {
+		return nil, 0, errors.New("invalid promo code")
+	}
+
+	promotion, err := s.promotionRepo.GetPromotionByCodeForUpdate(ctx, tx, code)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	if promotion == nil {
+		return nil, 0, errors.New("promo code not found")
+	}
+
+	if !promotion.IsActive {
+		return nil, 0, errors.New("promo code inactive")
+	}
+
+	now := time.Now()
+	if now.Before(promotion.ValidFrom) || now.After(promotion.ValidTo) {
+		return nil, 0, errors.New("promo code expired")
+	}
+
+	if promotion.UsageLimit != nil && promotion.TimesUsed >= *promotion.UsageLimit {
+		return nil, 0, errors.New("promo code usage limit reached")
+	}
+
+	if promotion.PerUserLimit != nil {
+		used, err := s.promotionRepo.CountUserRedemptions(ctx, tx, promotion.ID, userID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count promo code redemptions: %w", err)
+		}
+		if used >= *promotion.PerUserLimit {
+			return nil, 0, errors.New("promo code usage limit reached for user")
+		}
+	}
+
+	if voucher.Price < promotion.MinSpend {
+		return nil, 0, errors.New("promo code minimum spend not met")
+	}
+
+	if promotion.Category != nil && !strings.EqualFold(*promotion.Category, voucher.Category) {
+		return nil, 0, errors.New("promo code not applicable to voucher category")
+	}
+
+	return promotion, CalculateDiscount(promotion, voucher.Price), nil
+}
+
+// RecordRedemption stores the redemption of an applied promotion (used in transactions)
+func (s *PromotionService) RecordRedemption(ctx context.Context, tx repository.Tx, promotionID, userID, transactionID int, discount float64) error {
+	ctx, span := tracing.Start(ctx, "PromotionService.RecordRedemption")
+	defer span.End()
+
+	redemption := &model.PromotionRedemption{
+		PromotionID:    promotionID,
+		UserID:         userID,
+		TransactionID:  transactionID,
+		DiscountAmount: discount,
+	}
+
+	if err := s.promotionRepo.CreateRedemption(ctx, tx, redemption); err != nil {
+		return fmt.Errorf("failed to record promo code redemption: %w", err)
+	}
+
+	return nil
+}
+
+// CalculateDiscount returns the discount a promotion gives on a price,
+// rounded to paise and never more than the price itself
+func CalculateDiscount(promotion *model.Promotion, price float64) float64 {
+	var discount float64
+	switch promotion.DiscountType {
+	case model.DiscountTypePercentage:
+		discount = price * promotion.DiscountValue / 100
+		if promotion.MaxDiscount != nil && discount > *promotion.MaxDiscount {
+			discount = *promotion.MaxDiscount
+		}
+	case model.DiscountTypeFlat:
+		discount = promotion.DiscountValue
+	}
+
+	discount = math.Round(discount*100) / 100
+	if discount > price {
+		discount = price
+	}
+
+	return discount
+}
+
+// validatePromotion checks the fields of a promotion before it is created
+func validatePromotion(promotion *model.Promotion) error {
+	if promotion.Code == "" {
+		return errors.New("invalid promotion: code is required")
+	}
+
+	switch promotion.DiscountType {
+	case model.DiscountTypePercentage:
+		if promotion.DiscountValue <= 0 || promotion.DiscountValue > 100 {
+			return errors.New("invalid promotion: percentage discount must be between 0 and 100")
+		}
+	case model.DiscountTypeFlat:
+		if promotion.DiscountValue <= 0 {
+			return errors.New("invalid promotion: flat discount must be positive")
+		}
+	default:
+		return errors.New("invalid promotion: discount type must be percentage or flat")
+	}
+
+	if promotion.MaxDiscount != nil && *promotion.MaxDiscount <= 0 {
+		return errors.New("invalid promotion: max discount must be positive")
+	}
+
+	if promotion.MinSpend < 0 {
+		return errors.New("invalid promotion: min spend cannot be negative")
+	}
+
+	if promotion.UsageLimit != nil && *promotion.UsageLimit <= 0 {
+		return errors.New("invalid promotion: usage limit must be positive")
+	}
+
+	if promotion.PerUserLimit != nil && *promotion.PerUserLimit <= 0 {
+		return errors.New("invalid promotion: per user limit must be positive")
+	}
+
+	if !promotion.ValidTo.After(promotion.ValidFrom) {
+		return errors.New("invalid promotion: valid_to must be after valid_from")
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCalculateDiscount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion model.Promotion
+		price     float64
+		want      float64
+	}{
+		{
+			name:      "percentage",
+			promotion: model.Promotion{DiscountType: model.DiscountTypePercentage, DiscountValue: 10},
+			price:     150,
+			want:      15,
+		},
+		{
+			name:      "percentage rounded to paise",
+			promotion: model.Promotion{DiscountType: model.DiscountTypePercentage, DiscountValue: 15},
+			price:     99.99,
+			want:      15,
+		},
+		{
+			name:      "percentage capped by max discount",
+			promotion: model.Promotion{DiscountType: model.DiscountTypePercentage, DiscountValue: 50, MaxDiscount: ptr(40.0)},
+			price:     200,
+			want:      40,
+		},
+		{
+			name:      "percentage under max discount",
+			promotion: model.Promotion{DiscountType: model.DiscountTypePercentage, DiscountValue: 10, MaxDiscount: ptr(40.0)},
+			price:     200,
+			want:      20,
+		},
+		{
+			name:      "full percentage",
+			promotion: model.Promotion{DiscountType: model.DiscountTypePercentage, DiscountValue: 100},
+			price:     75.5,
+			want:      75.5,
+		},
+		{
+			name:      "flat",
+			promotion: model.Promotion{DiscountType: model.DiscountTypeFlat, DiscountValue: 25},
+			price:     150,
+			want:      25,
+		},
+		{
+			name:      "flat never exceeds price",
+			promotion: model.Promotion{DiscountType: model.DiscountTypeFlat, DiscountValue: 500},
+			price:     150,
+			want:      150,
+		},
+		{
+			name:      "flat ignores max discount",
+			promotion: model.Promotion{DiscountType: model.DiscountTypeFlat, DiscountValue: 50, MaxDiscount: ptr(10.0)},
+			price:     150,
+			want:      50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateDiscount(&tt.promotion, tt.price); got != tt.want {
+				t.Errorf("CalculateDiscount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nullable returns the column value of a nullable field
+func nullable(v any) driver.Value {
+	switch v := v.(type) {
+	case *float64:
+		if v != nil {
+			return *v
+		}
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *int:
+		if v != nil {
+			return int64(*v)
+		}
+	}
+	return nil
+}
+
+// promotionRows returns the promotions as rows of the repository's promotion columns
+func promotionRows(promotions ...*model.Promotion) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "code", "description", "discount_type", "discount_value", "max_discount", "min_spend", "category",
+		"usage_limit", "per_user_limit", "times_used", "is_active", "valid_from", "valid_to", "created_by", "created_at", "updated_at",
+	})
+	for _, p := range promotions {
+		rows.AddRow(
+			p.ID, p.Code, p.Description, string(p.DiscountType), p.DiscountValue, nullable(p.MaxDiscount), p.MinSpend, nullable(p.Category),
+			nullable(p.UsageLimit), nullable(p.PerUserLimit), p.TimesUsed, p.IsActive, p.ValidFrom, p.ValidTo, nullable(p.CreatedBy), p.CreatedAt, p.UpdatedAt,
+		)
+	}
+	return rows
+}
+
+func TestApplyPromotion(t *testing.T) {
+	const userID = 7
+	now := time.Now()
+	voucher := &model.Voucher{ID: 1, Category: "food", Price: 150}
+
+	tests := []struct {
+		name         string
+		code         string
+		promotion    func(p *model.Promotion)
+		missing      bool // No promotion has the code
+		userRedeemed int  // Earlier redemptions of the promotion by the user
+		wantDiscount float64
+		wantErr      string
+	}{
+		{
+			name:         "applies",
+			code:         "SAVE20",
+			wantDiscount: 30,
+		},
+		{
+			name:         "code is case and space insensitive",
+			code:         "  save20 ",
+			wantDiscount: 30,
+		},
+		{
+			name:         "category matches case-insensitively",
+			code:         "SAVE20",
+			promotion:    func(p *model.Promotion) { p.Category = ptr("FOOD") },
+			wantDiscount: 30,
+		},
+		{
+			name:    "empty code",
+			code:    "   ",
+			wantErr: "invalid promo code",
+		},
+		{
+			name:    "unknown code",
+			code:    "NOPE",
+			missing: true,
+			wantErr: "promo code not found",
+		},
+		{
+			name:      "inactive",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.IsActive = false },
+			wantErr:   "promo code inactive",
+		},
+		{
+			name:      "not started",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.ValidFrom = now.Add(time.Minute) },
+			wantErr:   "promo code expired",
+		},
+		{
+			name:      "expired",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.ValidTo = now.Add(-time.Minute) },
+			wantErr:   "promo code expired",
+		},
+		{
+			name:      "usage limit reached",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.UsageLimit, p.TimesUsed = ptr(2), 2 },
+			wantErr:   "promo code usage limit reached",
+		},
+		{
+			name:         "usage limit not reached",
+			code:         "SAVE20",
+			promotion:    func(p *model.Promotion) { p.UsageLimit, p.TimesUsed = ptr(2), 1 },
+			wantDiscount: 30,
+		},
+		{
+			name:         "per user limit reached",
+			code:         "SAVE20",
+			promotion:    func(p *model.Promotion) { p.PerUserLimit = ptr(1) },
+			userRedeemed: 1,
+			wantErr:      "promo code usage limit reached for user",
+		},
+		{
+			name:         "per user limit not reached",
+			code:         "SAVE20",
+			promotion:    func(p *model.Promotion) { p.PerUserLimit = ptr(2) },
+			userRedeemed: 1,
+			wantDiscount: 30,
+		},
+		{
+			name:      "minimum spend not met",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.MinSpend = 150.01 },
+			wantErr:   "promo code minimum spend not met",
+		},
+		{
+			name:         "minimum spend met exactly",
+			code:         "SAVE20",
+			promotion:    func(p *model.Promotion) { p.MinSpend = 150 },
+			wantDiscount: 30,
+		},
+		{
+			name:      "other category",
+			code:      "SAVE20",
+			promotion: func(p *model.Promotion) { p.Category = ptr("travel") },
+			wantErr:   "promo code not applicable to voucher category",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() error = %v", err)
+			}
+			defer db.Close()
+			service := NewPromotionService(postgres.NewPromotionRepository(db), nil)
+
+			promotion := &model.Promotion{
+				ID:            4,
+				Code:          "SAVE20",
+				DiscountType:  model.DiscountTypePercentage,
+				DiscountValue: 20,
+				IsActive:      true,
+				ValidFrom:     now.Add(-time.Hour),
+				ValidTo:       now.Add(time.Hour),
+			}
+			if tt.promotion != nil {
+				tt.promotion(promotion)
+			}
+
+			mock.ExpectBegin()
+			if tt.code != "   " {
+				rows := promotionRows(promotion)
+				if tt.missing {
+					rows = promotionRows()
+				}
+				mock.ExpectQuery(regexp.QuoteMeta("FROM promotions WHERE code = $1 FOR UPDATE")).
+					WithArgs(NormalizeCode(tt.code)).
+					WillReturnRows(rows)
+			}
+			if promotion.PerUserLimit != nil {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM promotion_redemptions")).
+					WithArgs(promotion.ID, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.userRedeemed))
+			}
+
+			tx, err := postgres.NewTxManager(db).BeginTx(context.Background())
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+
+			applied, discount, err := service.ApplyPromotion(context.Background(), tx, userID, tt.code, voucher)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ApplyPromotion() error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("ApplyPromotion() error = %v", err)
+				}
+				if applied.ID != promotion.ID {
+					t.Errorf("ApplyPromotion() promotion = %d, want %d", applied.ID, promotion.ID)
+				}
+				if discount != tt.wantDiscount {
+					t.Errorf("ApplyPromotion() discount = %v, want %v", discount, tt.wantDiscount)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
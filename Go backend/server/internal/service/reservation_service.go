@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+// maxReservationQuantity caps how many units a single hold may take
+const maxReservationQuantity = 10
+
+type ReservationService struct {
+	txManager       repository.TxManager
+	reservationRepo repository.ReservationRepository
+	voucherRepo     repository.VoucherRepository
+	userService     *UserService
+	defaultHold     time.Duration
+	maxHold         time.Duration
+}
+
+// NewReservationService creates a new reservation service.
+// defaultHold applies when a request does not ask for a duration; maxHold caps requested durations.
+func NewReservationService(
+	txManager repository.TxManager,
+	reservationRepo repository.ReservationRepository,
+	voucherRepo repository.VoucherRepository,
+	userService *UserService,
+	defaultHold, maxHold time.Duration,
+) *ReservationService {
+	return &ReservationService{
+		txManager:       txManager,
+		reservationRepo: reservationRepo,
+		voucherRepo:     voucherRepo,
+		userService:     userService,
+		defaultHold:     defaultHold,
+		maxHold:         maxHold,
+	}
+}
+
+// Reserve holds units of a voucher for a user for holdMinutes (0 uses the default)
+func (s *ReservationService) Reserve(ctx context.Context, userID, voucherID, quantity, holdMinutes int) (*model.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "ReservationService.Reserve")
+	defer span.End()
+
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if voucherID <= 0 {
+		return nil, errors.New("invalid voucher ID")
+	}
+
+	if quantity <= 0 || quantity > maxReservationQuantity {
+		return nil, fmt.Errorf("invalid reservation quantity: must be between 1 and %d", maxReservationQuantity)
+	}
+
+	hold := s.defaultHold
+	if holdMinutes != 0 {
+		hold = time.Duration(holdMinutes) * time.Minute
+	}
+	if hold <= 0 || hold > s.maxHold {
+		return nil, fmt.Errorf("invalid hold duration: must be between 1 and %d minutes", int(s.maxHold.Minutes()))
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the voucher so holds and purchases see a consistent stock level
+	voucher, err := s.voucherRepo.GetVoucherByIDForUpdate(ctx, tx, voucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock voucher: %w", err)
+	}
+
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	now := time.Now()
+	if now.Before(voucher.ValidFrom) || now.After(voucher.ValidTo) {
+		return nil, errors.New("voucher expired")
+	}
+
+	held, err := s.reservationRepo.SumActiveHolds(ctx, tx, voucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserved stock: %w", err)
+	}
+
+	if voucher.Quantity-held < quantity {
+		return nil, errors.New("insufficient stock to reserve")
+	}
+
+	reservation := &model.Reservation{
+		VoucherID: voucherID,
+		UserID:    userID,
+		Quantity:  quantity,
+		Status:    model.ReservationStatusActive,
+		ExpiresAt: now.Add(hold),
+	}
+
+	if err := s.reservationRepo.CreateReservation(ctx, tx, reservation); err != nil {
+		return nil, fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// Release returns the remaining units of a user's hold to stock
+func (s *ReservationService) Release(ctx context.Context, userID, reservationID int) (*model.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "ReservationService.Release")
+	defer span.End()
+
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	if reservationID <= 0 {
+		return nil, errors.New("invalid reservation ID")
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the hold so a concurrent purchase cannot consume it while it is released
+	reservation, err := s.reservationRepo.GetReservationByIDForUpdate(ctx, tx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock reservation: %w", err)
+	}
+
+	// Other users' holds are reported as not found rather than leaking their existence
+	if reservation == nil || reservation.UserID != userID {
+		return nil, errors.New("reservation not found")
+	}
+
+	if reservation.Status != model.ReservationStatusActive || !reservation.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("reservation not active")
+	}
+
+	if err := s.reservationRepo.UpdateReservation(ctx, tx, reservationID, reservation.Quantity, model.ReservationStatusReleased); err != nil {
+		return nil, fmt.Errorf("failed to release reservation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	reservation.Status = model.ReservationStatusReleased
+	return reservation, nil
+}
+
+// ClaimStock takes one unit for a purchase (used in transactions; the voucher row must be locked).
+// A unit of the user's own active hold is consumed if one exists; otherwise the unit
+// must be available after subtracting everyone's active holds.
+func (s *ReservationService) ClaimStock(ctx context.Context, tx repository.Tx, userID int, voucher *model.Voucher) error {
+	ctx, span := tracing.Start(ctx, "ReservationService.ClaimStock")
+	defer span.End()
+
+	hold, err := s.reservationRepo.GetActiveReservationForUpdate(ctx, tx, userID, voucher.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	if hold != nil {
+		remaining := hold.Quantity - 1
+		status := model.ReservationStatusActive
+		if remaining == 0 {
+			status = model.ReservationStatusConsumed
+		}
+		if err := s.reservationRepo.UpdateReservation(ctx, tx, hold.ID, remaining, status); err != nil {
+			return fmt.Errorf("failed to consume reservation: %w", err)
+		}
+		return nil
+	}
+
+	held, err := s.reservationRepo.SumActiveHolds(ctx, tx, voucher.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reserved stock: %w", err)
+	}
+
+	if voucher.Quantity-held <= 0 {
+		return errors.New("voucher out of stock")
+	}
+
+	return nil
+}
+
+// StartSweeper marks expired holds every interval until ctx is cancelled.
+// Expired holds already stop counting against stock; the sweeper keeps their status accurate.
+func (s *ReservationService) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				expired, err := s.reservationRepo.ExpireReservations(ctx, now)
+				if err != nil {
+					slog.Error("Reservation sweeper failed", "error", err)
+					continue
+				}
+				if expired > 0 {
+					slog.Info("Reservation sweeper released expired holds", "count", expired)
+				}
+			}
+		}
+	}()
+}
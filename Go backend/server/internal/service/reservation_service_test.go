@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
+)
+
+// reservationRows returns the reservations as rows of the repository's reservation columns
+func reservationRows(reservations ...*model.Reservation) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "voucher_id", "user_id", "quantity", "status", "expires_at", "created_at", "updated_at"})
+	for _, r := range reservations {
+		rows.AddRow(r.ID, r.VoucherID, r.UserID, r.Quantity, string(r.Status), r.ExpiresAt, r.CreatedAt, r.UpdatedAt)
+	}
+	return rows
+}
+
+// newReservationService returns a reservation service on a mock database holding 10 units
+// of voucher 1 in stock
+func newReservationService(t *testing.T) (*ReservationService, sqlmock.Sqlmock, *model.Voucher) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	service := NewReservationService(
+		postgres.NewTxManager(db),
+		postgres.NewReservationRepository(db),
+		postgres.NewVoucherRepository(db),
+		NewUserService(postgres.NewUserRepository(db)),
+		15*time.Minute,
+		time.Hour,
+	)
+
+	now := time.Now()
+	voucher := &model.Voucher{ID: 1, Name: "Coffee", Category: "food", RegularPrice: 150, Quantity: 10, ValidFrom: now.Add(-time.Hour), ValidTo: now.Add(time.Hour)}
+	return service, mock, voucher
+}
+
+// expectHeld expects the sum of the voucher's active holds
+func expectHeld(mock sqlmock.Sqlmock, voucherID, held int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0)")).
+		WithArgs(voucherID, model.ReservationStatusActive, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(held))
+}
+
+func TestReserve(t *testing.T) {
+	const userID = 7
+
+	tests := []struct {
+		name        string
+		quantity    int
+		holdMinutes int
+		held        int // Units of the voucher already held by active reservations
+		wantHold    time.Duration
+		wantErr     string
+	}{
+		{name: "default hold", quantity: 2, wantHold: 15 * time.Minute},
+		{name: "requested hold", quantity: 1, holdMinutes: 30, wantHold: 30 * time.Minute},
+		{name: "takes the last free units", quantity: 3, held: 7, wantHold: 15 * time.Minute},
+		{name: "held units are not free", quantity: 4, held: 7, wantErr: "insufficient stock to reserve"},
+		{name: "everything held", quantity: 1, held: 10, wantErr: "insufficient stock to reserve"},
+		{name: "hold too long", quantity: 1, holdMinutes: 61, wantErr: "invalid hold duration: must be between 1 and 60 minutes"},
+		{name: "no units", quantity: 0, wantErr: "invalid reservation quantity: must be between 1 and 10"},
+		{name: "too many units", quantity: maxReservationQuantity + 1, wantErr: "invalid reservation quantity: must be between 1 and 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock, voucher := newReservationService(t)
+
+			mock.ExpectQuery(regexp.QuoteMeta("FROM users")).WithArgs(userID).
+				WillReturnRows(userRows(&model.User{ID: userID, Name: "Asha", Email: "asha@example.com", Role: model.UserRoleCustomer}))
+
+			validRequest := tt.wantHold != 0 || tt.held > 0
+			if validRequest {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE OF v")).WithArgs(sqlmock.AnyArg(), voucher.ID).WillReturnRows(voucherRows(voucher))
+				expectHeld(mock, voucher.ID, tt.held)
+				if tt.wantErr == "" {
+					mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO voucher_reservations")).
+						WithArgs(voucher.ID, userID, tt.quantity, model.ReservationStatusActive, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+					mock.ExpectCommit()
+				} else {
+					mock.ExpectRollback()
+				}
+			}
+
+			start := time.Now()
+			reservation, err := service.Reserve(context.Background(), userID, voucher.ID, tt.quantity, tt.holdMinutes)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Reserve() error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Reserve() error = %v", err)
+				}
+				if reservation.ID != 12 || reservation.Quantity != tt.quantity || reservation.Status != model.ReservationStatusActive {
+					t.Errorf("Reserve() = %+v, want active hold 12 of %d units", reservation, tt.quantity)
+				}
+				if hold := reservation.ExpiresAt.Sub(start); hold < tt.wantHold || hold > tt.wantHold+time.Second {
+					t.Errorf("Reserve() holds for %v, want %v", hold, tt.wantHold)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestClaimStock(t *testing.T) {
+	const userID = 7
+
+	tests := []struct {
+		name         string
+		holdQuantity int // Units of the user's own active hold, 0 if none
+		held         int // Units held by everyone's active holds
+		wantQuantity int
+		wantStatus   model.ReservationStatus
+		wantErr      string
+	}{
+		{name: "consumes a unit of the own hold", holdQuantity: 3, wantQuantity: 2, wantStatus: model.ReservationStatusActive},
+		{name: "last unit consumes the hold", holdQuantity: 1, wantQuantity: 0, wantStatus: model.ReservationStatusConsumed},
+		{name: "free unit without a hold", held: 9},
+		{name: "all units held by others", held: 10, wantErr: "voucher out of stock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock, voucher := newReservationService(t)
+
+			mock.ExpectBegin()
+			hold := reservationRows()
+			if tt.holdQuantity > 0 {
+				hold = reservationRows(&model.Reservation{
+					ID:        12,
+					VoucherID: voucher.ID,
+					UserID:    userID,
+					Quantity:  tt.holdQuantity,
+					Status:    model.ReservationStatusActive,
+					ExpiresAt: time.Now().Add(time.Minute),
+				})
+			}
+			mock.ExpectQuery(regexp.QuoteMeta("FROM voucher_reservations")).
+				WithArgs(userID, voucher.ID, model.ReservationStatusActive, sqlmock.AnyArg()).
+				WillReturnRows(hold)
+
+			if tt.holdQuantity > 0 {
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE voucher_reservations")).
+					WithArgs(tt.wantQuantity, tt.wantStatus, sqlmock.AnyArg(), 12).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			} else {
+				expectHeld(mock, voucher.ID, tt.held)
+			}
+
+			tx, err := service.txManager.BeginTx(context.Background())
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+
+			err = service.ClaimStock(context.Background(), tx, userID, voucher)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ClaimStock() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("ClaimStock() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	const userID = 7
+
+	tests := []struct {
+		name        string
+		reservation *model.Reservation
+		wantErr     string
+	}{
+		{
+			name:        "returns the held units",
+			reservation: &model.Reservation{ID: 12, UserID: userID, Quantity: 2, Status: model.ReservationStatusActive, ExpiresAt: time.Now().Add(time.Minute)},
+		},
+		{
+			name:    "unknown reservation",
+			wantErr: "reservation not found",
+		},
+		{
+			name:        "another user's reservation",
+			reservation: &model.Reservation{ID: 12, UserID: userID + 1, Quantity: 2, Status: model.ReservationStatusActive, ExpiresAt: time.Now().Add(time.Minute)},
+			wantErr:     "reservation not found",
+		},
+		{
+			name:        "expired hold",
+			reservation: &model.Reservation{ID: 12, UserID: userID, Quantity: 2, Status: model.ReservationStatusActive, ExpiresAt: time.Now().Add(-time.Minute)},
+			wantErr:     "reservation not active",
+		},
+		{
+			name:        "consumed hold",
+			reservation: &model.Reservation{ID: 12, UserID: userID, Status: model.ReservationStatusConsumed, ExpiresAt: time.Now().Add(time.Minute)},
+			wantErr:     "reservation not active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock, voucher := newReservationService(t)
+
+			rows := reservationRows()
+			if tt.reservation != nil {
+				tt.reservation.VoucherID = voucher.ID
+				rows = reservationRows(tt.reservation)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).WithArgs(12).WillReturnRows(rows)
+			if tt.wantErr == "" {
+				// The units stay on the released row but no longer count as held
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE voucher_reservations")).
+					WithArgs(tt.reservation.Quantity, model.ReservationStatusReleased, sqlmock.AnyArg(), 12).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			released, err := service.Release(context.Background(), userID, 12)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Release() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Release() error = %v", err)
+			} else if released.Status != model.ReservationStatusReleased {
+				t.Errorf("Release() status = %s, want %s", released.Status, model.ReservationStatusReleased)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
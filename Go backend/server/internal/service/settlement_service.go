@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+type SettlementService struct {
+	txManager       repository.TxManager
+	settlementRepo  repository.SettlementRepository
+	merchantService *MerchantService
+	userService     *UserService
+	commissionRate  float64
+}
+
+// NewSettlementService creates a new settlement service.
+// commissionRate is the platform's share of each sale (0.10 keeps 10%); refunds reverse it.
+func NewSettlementService(
+	txManager repository.TxManager,
+	settlementRepo repository.SettlementRepository,
+	merchantService *MerchantService,
+	userService *UserService,
+	commissionRate float64,
+) *SettlementService {
+	return &SettlementService{
+		txManager:       txManager,
+		settlementRepo:  settlementRepo,
+		merchantService: merchantService,
+		userService:     userService,
+		commissionRate:  commissionRate,
+	}
+}
+
+// CreateBatch settles a merchant's unsettled purchases and refunds up to periodEnd (admin only).
+// The period starts where the merchant's previous batch ended; the new batch stays open until closed.
+func (s *SettlementService) CreateBatch(ctx context.Context, adminID, merchantID int, periodEnd time.Time) (*model.SettlementBatch, error) {
+	ctx, span := tracing.Start(ctx, "SettlementService.CreateBatch")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	merchant, err := s.merchantService.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if periodEnd.After(time.Now()) {
+		return nil, errors.New("invalid settlement period: period_end cannot be in the future")
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	periodStart := merchant.CreatedAt
+	lastEnd, err := s.settlementRepo.GetLatestPeriodEnd(ctx, tx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous settlement: %w", err)
+	}
+	if lastEnd != nil {
+		periodStart = *lastEnd
+	}
+
+	if !periodEnd.After(periodStart) {
+		return nil, errors.New("invalid settlement period: period_end must be after the previous settlement")
+	}
+
+	// Unsettled transactions from earlier periods (e.g. refunds recorded after a batch closed) are included
+	items, err := s.settlementRepo.GetUnsettledTransactions(ctx, tx, merchantID, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unsettled transactions: %w", err)
+	}
+
+	batch := &model.SettlementBatch{
+		MerchantID:     merchantID,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		CommissionRate: s.commissionRate,
+		Status:         model.SettlementStatusOpen,
+		CreatedBy:      &adminID,
+		Items:          items,
+	}
+	s.applyCommission(batch)
+
+	if err := s.settlementRepo.CreateBatch(ctx, tx, batch); err != nil {
+		if strings.Contains(err.Error(), "settlement batch already open") || strings.Contains(err.Error(), "transaction already settled") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create settlement batch: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return batch, nil
+}
+
+// CloseBatch finalizes an open batch; closed batches can no longer change (admin only)
+func (s *SettlementService) CloseBatch(ctx context.Context, adminID, batchID int) (*model.SettlementBatch, error) {
+	ctx, span := tracing.Start(ctx, "SettlementService.CloseBatch")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	batch, err := s.getBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	closedAt := time.Now()
+	closed, err := s.settlementRepo.CloseBatch(ctx, batchID, closedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close settlement batch: %w", err)
+	}
+
+	if !closed {
+		return nil, errors.New("settlement batch already closed")
+	}
+
+	batch.Status = model.SettlementStatusClosed
+	batch.ClosedAt = &closedAt
+	batch.UpdatedAt = closedAt
+	return batch, nil
+}
+
+// DiscardBatch deletes an open batch so its transactions can be settled again (admin only)
+func (s *SettlementService) DiscardBatch(ctx context.Context, adminID, batchID int) error {
+	ctx, span := tracing.Start(ctx, "SettlementService.DiscardBatch")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return err
+	}
+
+	if _, err := s.getBatch(ctx, batchID); err != nil {
+		return err
+	}
+
+	deleted, err := s.settlementRepo.DeleteOpenBatch(ctx, batchID)
+	if err != nil {
+		return fmt.Errorf("failed to discard settlement batch: %w", err)
+	}
+
+	if !deleted {
+		return errors.New("settlement batch already closed")
+	}
+
+	return nil
+}
+
+// GetBatch retrieves a batch with its line items (admins, or users of the batch's merchant)
+func (s *SettlementService) GetBatch(ctx context.Context, userID, batchID int) (*model.SettlementBatch, error) {
+	ctx, span := tracing.Start(ctx, "SettlementService.GetBatch")
+	defer span.End()
+
+	scope, err := s.userService.GetMerchantScope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := s.getBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other merchants' batches are reported as not found
+	if scope != nil && *scope != batch.MerchantID {
+		return nil, errors.New("settlement batch not found")
+	}
+
+	batch.Items, err = s.settlementRepo.GetLineItems(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement line items: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ListBatches retrieves settlement batches without line items.
+// Admins may filter by merchantID (0 lists all); merchant users only see their own.
+func (s *SettlementService) ListBatches(ctx context.Context, userID, merchantID int) ([]*model.SettlementBatch, error) {
+	ctx, span := tracing.Start(ctx, "SettlementService.ListBatches")
+	defer span.End()
+
+	scope, err := s.userService.GetMerchantScope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if merchantID < 0 {
+		return nil, errors.New("invalid merchant ID")
+	}
+
+	if scope == nil && merchantID > 0 {
+		scope = &merchantID
+	}
+
+	batches, err := s.settlementRepo.ListBatches(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+// ExportBatchCSV renders a batch's line items as CSV for finance and returns it with a file name
+func (s *SettlementService) ExportBatchCSV(ctx context.Context, userID, batchID int) ([]byte, string, error) {
+	ctx, span := tracing.Start(ctx, "SettlementService.ExportBatchCSV")
+	defer span.End()
+
+	batch, err := s.GetBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{
+		"batch_id", "merchant_id", "period_start", "period_end", "status",
+		"transaction_id", "transaction_type", "transaction_at", "voucher_id", "voucher_name",
+		"amount", "commission", "net_amount",
+	}
+	if err := w.Write(header); err != nil {
+		return nil, "", fmt.Errorf("failed to write settlement CSV: %w", err)
+	}
+
+	for _, item := range batch.Items {
+		record := []string{
+			strconv.Itoa(batch.ID),
+			strconv.Itoa(batch.MerchantID),
+			batch.PeriodStart.Format(time.RFC3339),
+			batch.PeriodEnd.Format(time.RFC3339),
+			string(batch.Status),
+			strconv.Itoa(item.TransactionID),
+			string(item.TransactionType),
+			item.TransactionAt.Format(time.RFC3339),
+			strconv.Itoa(item.VoucherID),
+			item.VoucherName,
+			formatAmount(item.Amount),
+			formatAmount(item.Commission),
+			formatAmount(item.NetAmount),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, "", fmt.Errorf("failed to write settlement CSV: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", fmt.Errorf("failed to write settlement CSV: %w", err)
+	}
+
+	filename := fmt.Sprintf("settlement-%d-merchant-%d-%s.csv", batch.ID, batch.MerchantID, batch.PeriodEnd.Format("20060102"))
+	return buf.Bytes(), filename, nil
+}
+
+// getBatch retrieves a batch by ID without line items
+func (s *SettlementService) getBatch(ctx context.Context, batchID int) (*model.SettlementBatch, error) {
+	if batchID <= 0 {
+		return nil, errors.New("invalid settlement batch ID")
+	}
+
+	batch, err := s.settlementRepo.GetBatchByID(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement batch: %w", err)
+	}
+
+	if batch == nil {
+		return nil, errors.New("settlement batch not found")
+	}
+
+	return batch, nil
+}
+
+// applyCommission signs refunds, computes each line's commission and net, and totals the batch
+func (s *SettlementService) applyCommission(batch *model.SettlementBatch) {
+	for _, item := range batch.Items {
+		if item.TransactionType == model.TransactionTypeRefund {
+			batch.RefundAmount += item.Amount
+			item.Amount = -item.Amount
+		} else {
+			batch.GrossAmount += item.Amount
+		}
+
+		item.Commission = roundAmount(item.Amount * batch.CommissionRate)
+		item.NetAmount = roundAmount(item.Amount - item.Commission)
+
+		batch.CommissionAmount += item.Commission
+		batch.NetAmount += item.NetAmount
+	}
+
+	batch.GrossAmount = roundAmount(batch.GrossAmount)
+	batch.RefundAmount = roundAmount(batch.RefundAmount)
+	batch.CommissionAmount = roundAmount(batch.CommissionAmount)
+	batch.NetAmount = roundAmount(batch.NetAmount)
+}
+
+// roundAmount rounds a currency amount to 2 decimal places
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// formatAmount formats a currency amount with 2 decimal places
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
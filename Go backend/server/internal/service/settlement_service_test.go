@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+)
+
+func TestApplyCommission(t *testing.T) {
+	purchase := func(amount float64) *model.SettlementLineItem {
+		return &model.SettlementLineItem{TransactionType: model.TransactionTypePurchase, Amount: amount}
+	}
+	refund := func(amount float64) *model.SettlementLineItem {
+		return &model.SettlementLineItem{TransactionType: model.TransactionTypeRefund, Amount: amount}
+	}
+
+	type line struct {
+		amount, commission, net float64
+	}
+
+	tests := []struct {
+		name                                           string
+		rate                                           float64
+		items                                          []*model.SettlementLineItem
+		wantLines                                      []line
+		wantGross, wantRefund, wantCommission, wantNet float64
+	}{
+		{
+			name: "empty batch",
+			rate: 0.10,
+		},
+		{
+			name:           "purchases only",
+			rate:           0.10,
+			items:          []*model.SettlementLineItem{purchase(150), purchase(99.99)},
+			wantLines:      []line{{150, 15, 135}, {99.99, 10, 89.99}},
+			wantGross:      249.99,
+			wantCommission: 25,
+			wantNet:        224.99,
+		},
+		{
+			name:           "refund reverses commission",
+			rate:           0.10,
+			items:          []*model.SettlementLineItem{purchase(200), refund(50)},
+			wantLines:      []line{{200, 20, 180}, {-50, -5, -45}},
+			wantGross:      200,
+			wantRefund:     50,
+			wantCommission: 15,
+			wantNet:        135,
+		},
+		{
+			name:           "more refunded than sold",
+			rate:           0.10,
+			items:          []*model.SettlementLineItem{purchase(40), refund(100)},
+			wantLines:      []line{{40, 4, 36}, {-100, -10, -90}},
+			wantGross:      40,
+			wantRefund:     100,
+			wantCommission: -6,
+			wantNet:        -54,
+		},
+		{
+			name:           "zero rate pays everything out",
+			rate:           0,
+			items:          []*model.SettlementLineItem{purchase(120.5)},
+			wantLines:      []line{{120.5, 0, 120.5}},
+			wantGross:      120.5,
+			wantCommission: 0,
+			wantNet:        120.5,
+		},
+		{
+			name:           "commission rounded per line",
+			rate:           0.125,
+			items:          []*model.SettlementLineItem{purchase(0.1), purchase(0.1), purchase(0.1)},
+			wantLines:      []line{{0.1, 0.01, 0.09}, {0.1, 0.01, 0.09}, {0.1, 0.01, 0.09}},
+			wantGross:      0.3,
+			wantCommission: 0.03,
+			wantNet:        0.27,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := &model.SettlementBatch{CommissionRate: tt.rate, Items: tt.items}
+			(&SettlementService{}).applyCommission(batch)
+
+			for i, want := range tt.wantLines {
+				item := batch.Items[i]
+				if item.Amount != want.amount || item.Commission != want.commission || item.NetAmount != want.net {
+					t.Errorf("item %d = (%v, %v, %v), want (%v, %v, %v)",
+						i, item.Amount, item.Commission, item.NetAmount, want.amount, want.commission, want.net)
+				}
+			}
+
+			if batch.GrossAmount != tt.wantGross {
+				t.Errorf("GrossAmount = %v, want %v", batch.GrossAmount, tt.wantGross)
+			}
+			if batch.RefundAmount != tt.wantRefund {
+				t.Errorf("RefundAmount = %v, want %v", batch.RefundAmount, tt.wantRefund)
+			}
+			if batch.CommissionAmount != tt.wantCommission {
+				t.Errorf("CommissionAmount = %v, want %v", batch.CommissionAmount, tt.wantCommission)
+			}
+			if batch.NetAmount != tt.wantNet {
+				t.Errorf("NetAmount = %v, want %v", batch.NetAmount, tt.wantNet)
+			}
+		})
+	}
+}
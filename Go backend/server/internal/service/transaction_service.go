@@ -1,33 +1,41 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/events"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type TransactionService struct {
-	transactionRepo *repository.TransactionRepository
+	transactionRepo repository.TransactionRepository
 	userService     *UserService
+	events          *events.Bus
 }
 
 // NewTransactionService creates a new transaction service
-func NewTransactionService(transactionRepo *repository.TransactionRepository, userService *UserService) *TransactionService {
+func NewTransactionService(transactionRepo repository.TransactionRepository, userService *UserService, eventBus *events.Bus) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
 		userService:     userService,
+		events:          eventBus,
 	}
 }
 
 // ListTransactions retrieves all transactions for a user
-func (s *TransactionService) ListTransactions(userID int) ([]*model.Transaction, error) {
+func (s *TransactionService) ListTransactions(ctx context.Context, userID int) ([]*model.Transaction, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.ListTransactions")
+	defer span.End()
+
 	// Validate user exists
-	if err := s.userService.ValidateUserExists(userID); err != nil {
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
 		return nil, err
 	}
 
-	transactions, err := s.transactionRepo.GetTransactionsByUserID(userID)
+	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -35,3 +43,15 @@ func (s *TransactionService) ListTransactions(userID int) ([]*model.Transaction,
 	return transactions, nil
 }
 
+// WatchTransactions subscribes to the user's transaction events; the caller closes the subscription
+func (s *TransactionService) WatchTransactions(ctx context.Context, userID int) (*events.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "TransactionService.WatchTransactions")
+	defer span.End()
+
+	// Validate user exists
+	if err := s.userService.ValidateUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.events.Subscribe(userID), nil
+}
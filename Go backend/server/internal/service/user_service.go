@@ -1,31 +1,57 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.UserRepository
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo repository.UserRepository) *UserService {
 	return &UserService{
 		userRepo: userRepo,
 	}
 }
 
 // ValidateUserExists checks if a user exists by ID
-func (s *UserService) ValidateUserExists(userID int) error {
+func (s *UserService) ValidateUserExists(ctx context.Context, userID int) error {
+	ctx, span := tracing.Start(ctx, "UserService.ValidateUserExists")
+	defer span.End()
+
+	if userID <= 0 {
+		return errors.New("invalid user ID")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// ValidateAdmin checks if a user exists and has the admin role
+func (s *UserService) ValidateAdmin(ctx context.Context, userID int) error {
+	ctx, span := tracing.Start(ctx, "UserService.ValidateAdmin")
+	defer span.End()
+
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
 
-	user, err := s.userRepo.GetUserByID(userID)
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
@@ -34,16 +60,108 @@ func (s *UserService) ValidateUserExists(userID int) error {
 		return errors.New("user not found")
 	}
 
+	if user.Role != model.UserRoleAdmin {
+		return errors.New("permission denied")
+	}
+
 	return nil
 }
 
+// GetMerchantID checks that a user is a merchant user and returns their merchant ID
+func (s *UserService) GetMerchantID(ctx context.Context, userID int) (int, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetMerchantID")
+	defer span.End()
+
+	if userID <= 0 {
+		return 0, errors.New("invalid user ID")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil {
+		return 0, errors.New("user not found")
+	}
+
+	if user.Role != model.UserRoleMerchant || user.MerchantID == nil {
+		return 0, errors.New("permission denied")
+	}
+
+	return *user.MerchantID, nil
+}
+
+// GetMerchantScope returns the merchant a user may see data for:
+// nil for admins (all merchants), or the merchant ID of a merchant user
+func (s *UserService) GetMerchantScope(ctx context.Context, userID int) (*int, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetMerchantScope")
+	defer span.End()
+
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	switch {
+	case user.Role == model.UserRoleAdmin:
+		return nil, nil
+	case user.Role == model.UserRoleMerchant && user.MerchantID != nil:
+		return user.MerchantID, nil
+	default:
+		return nil, errors.New("permission denied")
+	}
+}
+
+// AssignMerchant makes an existing user a merchant user of the given merchant
+func (s *UserService) AssignMerchant(ctx context.Context, userID, merchantID int) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.AssignMerchant")
+	defer span.End()
+
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.Role == model.UserRoleAdmin {
+		return nil, errors.New("admin users cannot be merchant users")
+	}
+
+	if err := s.userRepo.SetMerchant(ctx, userID, merchantID); err != nil {
+		return nil, fmt.Errorf("failed to assign merchant: %w", err)
+	}
+
+	user.Role = model.UserRoleMerchant
+	user.MerchantID = &merchantID
+	return user, nil
+}
+
 // Login authenticates a user with email and password
-func (s *UserService) Login(email, password string) (*model.User, error) {
+func (s *UserService) Login(ctx context.Context, email, password string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	if email == "" || password == "" {
 		return nil, errors.New("invalid email or password")
 	}
 
-	user, err := s.userRepo.Login(email, password)
+	user, err := s.userRepo.Login(ctx, email, password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to login: %w", err)
 	}
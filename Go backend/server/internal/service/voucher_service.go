@@ -1,27 +1,37 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type VoucherService struct {
-	voucherRepo *repository.VoucherRepository
+	voucherRepo     repository.VoucherRepository
+	transactionRepo repository.TransactionRepository
+	userService     *UserService
 }
 
 // NewVoucherService creates a new voucher service
-func NewVoucherService(voucherRepo *repository.VoucherRepository) *VoucherService {
+func NewVoucherService(voucherRepo repository.VoucherRepository, transactionRepo repository.TransactionRepository, userService *UserService) *VoucherService {
 	return &VoucherService{
-		voucherRepo: voucherRepo,
+		voucherRepo:     voucherRepo,
+		transactionRepo: transactionRepo,
+		userService:     userService,
 	}
 }
 
-// SearchVouchers searches for vouchers with optional filters
-func (s *VoucherService) SearchVouchers(category string, minPrice, maxPrice *float64) ([]*model.Voucher, error) {
+// SearchVouchers searches for vouchers with optional filters.
+// When userID is set, each voucher carries the user's remaining purchase allowance.
+func (s *VoucherService) SearchVouchers(ctx context.Context, userID int, category string, merchantID *int, minPrice, maxPrice *float64) ([]*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "VoucherService.SearchVouchers")
+	defer span.End()
+
 	// Validate price range if provided
 	if minPrice != nil && *minPrice < 0 {
 		return nil, errors.New("invalid price")
@@ -33,21 +43,86 @@ func (s *VoucherService) SearchVouchers(category string, minPrice, maxPrice *flo
 		return nil, errors.New("invalid price")
 	}
 
-	vouchers, err := s.voucherRepo.SearchVouchers(category, minPrice, maxPrice)
+	if merchantID != nil && *merchantID <= 0 {
+		return nil, errors.New("invalid merchant ID")
+	}
+
+	vouchers, err := s.voucherRepo.SearchVouchers(ctx, category, merchantID, minPrice, maxPrice)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search vouchers: %w", err)
 	}
 
+	if err := s.fillRemainingAllowance(ctx, userID, vouchers); err != nil {
+		return nil, err
+	}
+
 	return vouchers, nil
 }
 
+// fillRemainingAllowance sets RemainingAllowance on vouchers that have purchase limits
+func (s *VoucherService) fillRemainingAllowance(ctx context.Context, userID int, vouchers []*model.Voucher) error {
+	limited := false
+	for _, v := range vouchers {
+		if v.MaxPerUser != nil || v.MaxPerUserPerDay != nil {
+			limited = true
+			break
+		}
+	}
+
+	// Without a user the allowance is simply the tightest limit
+	lifetimeCounts := map[int]int{}
+	todayCounts := map[int]int{}
+	if limited && userID > 0 {
+		var err error
+		lifetimeCounts, err = s.transactionRepo.GetUserPurchaseCounts(ctx, userID, time.Time{})
+		if err != nil {
+			return fmt.Errorf("failed to get purchase counts: %w", err)
+		}
+		todayCounts, err = s.transactionRepo.GetUserPurchaseCounts(ctx, userID, startOfDay(time.Now()))
+		if err != nil {
+			return fmt.Errorf("failed to get purchase counts: %w", err)
+		}
+	}
+
+	for _, v := range vouchers {
+		v.RemainingAllowance = remainingAllowance(v, lifetimeCounts[v.ID], todayCounts[v.ID])
+	}
+
+	return nil
+}
+
+// remainingAllowance returns how many more units a user may buy, or nil if unlimited
+func remainingAllowance(voucher *model.Voucher, lifetimeUsed, todayUsed int) *int {
+	var remaining *int
+	if voucher.MaxPerUser != nil {
+		left := max(*voucher.MaxPerUser-lifetimeUsed, 0)
+		remaining = &left
+	}
+	if voucher.MaxPerUserPerDay != nil {
+		left := max(*voucher.MaxPerUserPerDay-todayUsed, 0)
+		if remaining == nil || left < *remaining {
+			remaining = &left
+		}
+	}
+	return remaining
+}
+
+// startOfDay returns midnight of the given time's day in its location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
 // GetVoucherByID retrieves a voucher by ID
-func (s *VoucherService) GetVoucherByID(voucherID int) (*model.Voucher, error) {
+func (s *VoucherService) GetVoucherByID(ctx context.Context, voucherID int) (*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "VoucherService.GetVoucherByID")
+	defer span.End()
+
 	if voucherID <= 0 {
 		return nil, errors.New("invalid voucher ID")
 	}
 
-	voucher, err := s.voucherRepo.GetVoucherByID(voucherID)
+	voucher, err := s.voucherRepo.GetVoucherByID(ctx, voucherID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get voucher: %w", err)
 	}
@@ -60,12 +135,15 @@ func (s *VoucherService) GetVoucherByID(voucherID int) (*model.Voucher, error) {
 }
 
 // ValidateVoucherAvailable checks if voucher exists, is in stock, and not expired
-func (s *VoucherService) ValidateVoucherAvailable(voucherID int) error {
+func (s *VoucherService) ValidateVoucherAvailable(ctx context.Context, voucherID int) error {
+	ctx, span := tracing.Start(ctx, "VoucherService.ValidateVoucherAvailable")
+	defer span.End()
+
 	if voucherID <= 0 {
 		return errors.New("invalid voucher ID")
 	}
 
-	voucher, err := s.voucherRepo.GetVoucherByID(voucherID)
+	voucher, err := s.voucherRepo.GetVoucherByID(ctx, voucherID)
 	if err != nil {
 		return fmt.Errorf("failed to get voucher: %w", err)
 	}
@@ -88,3 +166,78 @@ func (s *VoucherService) ValidateVoucherAvailable(voucherID int) error {
 	return nil
 }
 
+// LockVoucherForPurchase locks the voucher row for the rest of the purchase
+// transaction and re-checks stock, validity and the user's purchase limits,
+// so concurrent purchases cannot exceed them
+func (s *VoucherService) LockVoucherForPurchase(ctx context.Context, tx repository.Tx, userID, voucherID int) (*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "VoucherService.LockVoucherForPurchase")
+	defer span.End()
+
+	voucher, err := s.voucherRepo.GetVoucherByIDForUpdate(ctx, tx, voucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock voucher: %w", err)
+	}
+
+	if voucher == nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	now := time.Now()
+	if now.Before(voucher.ValidFrom) || now.After(voucher.ValidTo) {
+		return nil, errors.New("voucher expired")
+	}
+
+	if voucher.Quantity <= 0 {
+		return nil, errors.New("voucher out of stock")
+	}
+
+	if voucher.MaxPerUser != nil {
+		used, err := s.transactionRepo.CountUserPurchases(ctx, tx, userID, voucherID, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count purchases: %w", err)
+		}
+		if used >= *voucher.MaxPerUser {
+			return nil, errors.New("voucher purchase limit reached")
+		}
+	}
+
+	if voucher.MaxPerUserPerDay != nil {
+		used, err := s.transactionRepo.CountUserPurchases(ctx, tx, userID, voucherID, startOfDay(now))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count purchases: %w", err)
+		}
+		if used >= *voucher.MaxPerUserPerDay {
+			return nil, errors.New("voucher daily purchase limit reached")
+		}
+	}
+
+	return voucher, nil
+}
+
+// SetPurchaseLimits configures the per-user purchase limits of a voucher (admin only).
+// A nil limit means unlimited.
+func (s *VoucherService) SetPurchaseLimits(ctx context.Context, adminID, voucherID int, maxPerUser, maxPerUserPerDay *int) (*model.Voucher, error) {
+	ctx, span := tracing.Start(ctx, "VoucherService.SetPurchaseLimits")
+	defer span.End()
+
+	if err := s.userService.ValidateAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	if (maxPerUser != nil && *maxPerUser <= 0) || (maxPerUserPerDay != nil && *maxPerUserPerDay <= 0) {
+		return nil, errors.New("invalid purchase limit")
+	}
+
+	voucher, err := s.GetVoucherByID(ctx, voucherID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.voucherRepo.UpdatePurchaseLimits(ctx, voucherID, maxPerUser, maxPerUserPerDay); err != nil {
+		return nil, fmt.Errorf("failed to update purchase limits: %w", err)
+	}
+
+	voucher.MaxPerUser = maxPerUser
+	voucher.MaxPerUserPerDay = maxPerUserPerDay
+	return voucher, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
+)
+
+// voucherRows returns the vouchers as rows of the repository's voucher select, joined
+// with their active sale and held units
+func voucherRows(vouchers ...*model.Voucher) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "name", "description", "category", "merchant_id", "price", "quantity", "valid_from", "valid_to",
+		"max_per_user", "max_per_user_per_day", "created_at", "updated_at",
+		"id", "starts_at", "ends_at", "sale_price", "stock_cap", "sold_count", "held",
+	})
+	for _, v := range vouchers {
+		sale := []driver.Value{nil, nil, nil, nil, nil, nil}
+		if s := v.ActiveSale; s != nil {
+			sale = []driver.Value{s.ID, s.StartsAt, s.EndsAt, s.SalePrice, nullable(s.StockCap), s.SoldCount}
+		}
+		rows.AddRow(append([]driver.Value{
+			v.ID, v.Name, v.Description, v.Category, nullable(v.MerchantID), v.RegularPrice, v.Quantity, v.ValidFrom, v.ValidTo,
+			nullable(v.MaxPerUser), nullable(v.MaxPerUserPerDay), v.CreatedAt, v.UpdatedAt,
+		}, append(sale, v.Reserved)...)...)
+	}
+	return rows
+}
+
+func TestRemainingAllowance(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxPerUser   *int
+		maxPerDay    *int
+		lifetimeUsed int
+		todayUsed    int
+		want         *int
+	}{
+		{name: "unlimited", lifetimeUsed: 5, todayUsed: 2},
+		{name: "lifetime limit", maxPerUser: ptr(5), lifetimeUsed: 3, want: ptr(2)},
+		{name: "daily limit", maxPerDay: ptr(2), lifetimeUsed: 9, todayUsed: 1, want: ptr(1)},
+		{name: "lower of both limits", maxPerUser: ptr(5), maxPerDay: ptr(2), lifetimeUsed: 4, todayUsed: 0, want: ptr(1)},
+		{name: "never negative", maxPerUser: ptr(1), lifetimeUsed: 3, want: ptr(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher := &model.Voucher{MaxPerUser: tt.maxPerUser, MaxPerUserPerDay: tt.maxPerDay}
+			got := remainingAllowance(voucher, tt.lifetimeUsed, tt.todayUsed)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("remainingAllowance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockVoucherForPurchase(t *testing.T) {
+	const userID, voucherID = 7, 1
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		voucher       func(v *model.Voucher)
+		missing       bool
+		lifetimeCount int // Earlier purchases of the voucher by the user
+		todayCount    int // Of which today
+		wantPrice     float64
+		wantErr       string
+	}{
+		{
+			name:          "unlimited",
+			lifetimeCount: 50,
+		},
+		{
+			name:          "under lifetime limit",
+			voucher:       func(v *model.Voucher) { v.MaxPerUser = ptr(3) },
+			lifetimeCount: 2,
+		},
+		{
+			name:          "lifetime limit reached",
+			voucher:       func(v *model.Voucher) { v.MaxPerUser = ptr(3) },
+			lifetimeCount: 3,
+			wantErr:       "voucher purchase limit reached",
+		},
+		{
+			name:       "under daily limit",
+			voucher:    func(v *model.Voucher) { v.MaxPerUserPerDay = ptr(2) },
+			todayCount: 1,
+		},
+		{
+			name:       "daily limit reached",
+			voucher:    func(v *model.Voucher) { v.MaxPerUserPerDay = ptr(2) },
+			todayCount: 2,
+			wantErr:    "voucher daily purchase limit reached",
+		},
+		{
+			name:          "daily limit reached under lifetime limit",
+			voucher:       func(v *model.Voucher) { v.MaxPerUser, v.MaxPerUserPerDay = ptr(10), ptr(1) },
+			lifetimeCount: 4,
+			todayCount:    1,
+			wantErr:       "voucher daily purchase limit reached",
+		},
+		{
+			name: "flash sale price",
+			voucher: func(v *model.Voucher) {
+				v.ActiveSale = &model.PriceSchedule{ID: 3, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), SalePrice: 99, StockCap: ptr(5)}
+			},
+			wantPrice: 99,
+		},
+		{
+			name:    "not found",
+			missing: true,
+			wantErr: "voucher not found",
+		},
+		{
+			name:    "expired",
+			voucher: func(v *model.Voucher) { v.ValidTo = now.Add(-time.Minute) },
+			wantErr: "voucher expired",
+		},
+		{
+			name:    "out of stock",
+			voucher: func(v *model.Voucher) { v.Quantity = 0 },
+			wantErr: "voucher out of stock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New() error = %v", err)
+			}
+			defer db.Close()
+			service := NewVoucherService(postgres.NewVoucherRepository(db), postgres.NewTransactionRepository(db), nil)
+
+			voucher := &model.Voucher{
+				ID:           voucherID,
+				Name:         "Coffee",
+				Category:     "food",
+				RegularPrice: 150,
+				Quantity:     10,
+				ValidFrom:    now.Add(-time.Hour),
+				ValidTo:      now.Add(time.Hour),
+			}
+			if tt.voucher != nil {
+				tt.voucher(voucher)
+			}
+
+			mock.ExpectBegin()
+			rows := voucherRows(voucher)
+			if tt.missing {
+				rows = voucherRows()
+			}
+			mock.ExpectQuery(regexp.QuoteMeta("FROM vouchers")).WithArgs(sqlmock.AnyArg(), voucherID).WillReturnRows(rows)
+
+			// The lifetime count looks back to the zero time and the daily one to midnight
+			count := func(since any, n int) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+					WithArgs(userID, voucherID, model.TransactionTypePurchase, model.PaymentStatusFailed, since).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+			}
+			if voucher.MaxPerUser != nil {
+				count(time.Time{}, tt.lifetimeCount)
+			}
+			if voucher.MaxPerUserPerDay != nil && (voucher.MaxPerUser == nil || tt.lifetimeCount < *voucher.MaxPerUser) {
+				count(startOfDay(now), tt.todayCount)
+			}
+
+			tx, err := postgres.NewTxManager(db).BeginTx(context.Background())
+			if err != nil {
+				t.Fatalf("BeginTx() error = %v", err)
+			}
+
+			locked, err := service.LockVoucherForPurchase(context.Background(), tx, userID, voucherID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("LockVoucherForPurchase() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("LockVoucherForPurchase() error = %v", err)
+			} else if want := cmp.Or(tt.wantPrice, voucher.RegularPrice); locked.ID != voucherID || locked.Price != want {
+				t.Errorf("LockVoucherForPurchase() = voucher %d at %v, want voucher %d at %v", locked.ID, locked.Price, voucherID, want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
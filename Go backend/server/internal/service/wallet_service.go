@@ -1,31 +1,35 @@
 package service
 
 import (
-	"database/sql"
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
 )
 
 type WalletService struct {
-	walletRepo *repository.WalletRepository
+	walletRepo repository.WalletRepository
 }
 
 // NewWalletService creates a new wallet service
-func NewWalletService(walletRepo *repository.WalletRepository) *WalletService {
+func NewWalletService(walletRepo repository.WalletRepository) *WalletService {
 	return &WalletService{
 		walletRepo: walletRepo,
 	}
 }
 
 // GetBalance retrieves the current balance for a user
-func (s *WalletService) GetBalance(userID int) (float64, error) {
+func (s *WalletService) GetBalance(ctx context.Context, userID int) (float64, error) {
+	ctx, span := tracing.Start(ctx, "WalletService.GetBalance")
+	defer span.End()
+
 	if userID <= 0 {
 		return 0, errors.New("invalid user ID")
 	}
 
-	balance, err := s.walletRepo.GetBalance(userID)
+	balance, err := s.walletRepo.GetBalance(ctx, userID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get wallet balance: %w", err)
 	}
@@ -34,7 +38,10 @@ func (s *WalletService) GetBalance(userID int) (float64, error) {
 }
 
 // ValidateSufficientBalance checks if user has enough balance for a transaction
-func (s *WalletService) ValidateSufficientBalance(userID int, amount float64) error {
+func (s *WalletService) ValidateSufficientBalance(ctx context.Context, userID int, amount float64) error {
+	ctx, span := tracing.Start(ctx, "WalletService.ValidateSufficientBalance")
+	defer span.End()
+
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
@@ -43,7 +50,7 @@ func (s *WalletService) ValidateSufficientBalance(userID int, amount float64) er
 		return errors.New("invalid amount")
 	}
 
-	balance, err := s.walletRepo.GetBalance(userID)
+	balance, err := s.walletRepo.GetBalance(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get wallet balance: %w", err)
 	}
@@ -56,7 +63,10 @@ func (s *WalletService) ValidateSufficientBalance(userID int, amount float64) er
 }
 
 // DeductBalance deducts amount from user's wallet (used in transactions)
-func (s *WalletService) DeductBalance(tx *sql.Tx, userID int, amount float64) error {
+func (s *WalletService) DeductBalance(ctx context.Context, tx repository.Tx, userID int, amount float64) error {
+	ctx, span := tracing.Start(ctx, "WalletService.DeductBalance")
+	defer span.End()
+
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
@@ -66,12 +76,12 @@ func (s *WalletService) DeductBalance(tx *sql.Tx, userID int, amount float64) er
 	}
 
 	// Validate balance before deducting
-	if err := s.ValidateSufficientBalance(userID, amount); err != nil {
+	if err := s.ValidateSufficientBalance(ctx, userID, amount); err != nil {
 		return err
 	}
 
 	// Deduct the amount (negative value)
-	err := s.walletRepo.UpdateBalance(tx, userID, -amount)
+	err := s.walletRepo.UpdateBalance(ctx, tx, userID, -amount)
 	if err != nil {
 		return fmt.Errorf("failed to deduct wallet balance: %w", err)
 	}
@@ -80,7 +90,10 @@ func (s *WalletService) DeductBalance(tx *sql.Tx, userID int, amount float64) er
 }
 
 // AddBalance adds amount to user's wallet (for top-ups, refunds)
-func (s *WalletService) AddBalance(tx *sql.Tx, userID int, amount float64) error {
+func (s *WalletService) AddBalance(ctx context.Context, tx repository.Tx, userID int, amount float64) error {
+	ctx, span := tracing.Start(ctx, "WalletService.AddBalance")
+	defer span.End()
+
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
@@ -90,7 +103,7 @@ func (s *WalletService) AddBalance(tx *sql.Tx, userID int, amount float64) error
 	}
 
 	// Add the amount (positive value)
-	err := s.walletRepo.UpdateBalance(tx, userID, amount)
+	err := s.walletRepo.UpdateBalance(ctx, tx, userID, amount)
 	if err != nil {
 		return fmt.Errorf("failed to add wallet balance: %w", err)
 	}
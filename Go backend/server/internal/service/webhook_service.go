@@ -0,0 +1,560 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/outbox"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+)
+
+const (
+	webhookDeliveryLogLimit = 100 // Deliveries returned by ListDeliveries
+	webhookWorkers          = 8   // Deliveries sent at once
+	webhookMaxErrorBody     = 512 // How much of a failed response is kept in the delivery log
+)
+
+// Headers of webhook requests
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookEventTypes maps the outbox events merchants are notified of to their webhook event types
+var webhookEventTypes = map[string]string{
+	model.EventVoucherPurchased: model.WebhookEventVoucherSold,
+	model.EventVoucherRefunded:  model.WebhookEventVoucherRefunded,
+	model.EventVoucherRedeemed:  model.WebhookEventVoucherRedeemed,
+}
+
+// WebhookSettings tune merchant webhook deliveries
+type WebhookSettings struct {
+	Timeout      time.Duration // Timeout of each request
+	MaxAttempts  int           // Attempts before a delivery fails for good
+	RetryBase    time.Duration // Delay before the second attempt; doubles with every attempt
+	RetryMax     time.Duration // Longest delay between attempts
+	DisableAfter int           // Consecutive failed attempts before an endpoint is disabled
+	BatchSize    int           // Due deliveries fetched at a time
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	userService *UserService
+	settings    WebhookSettings
+	client      *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhookRepo repository.WebhookRepository, userService *UserService, settings WebhookSettings) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		userService: userService,
+		settings:    settings,
+		client: &http.Client{
+			Timeout:   settings.Timeout,
+			Transport: webhookTransport(settings.Timeout),
+			// A redirect counts as a failure; the signed request is only sent to the registered URL
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// CreateEndpoint registers a webhook endpoint for the merchant user's merchant.
+// The returned endpoint carries the signing secret, which is not shown again.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, userID int, rawURL string, eventTypes []string) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateEndpoint")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for _, eventType := range eventTypes {
+		if !slices.Contains(model.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("invalid event type %q: must be one of %s", eventType, strings.Join(model.WebhookEventTypes, ", "))
+		}
+		if !slices.Contains(types, eventType) {
+			types = append(types, eventType)
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	endpoint := &model.WebhookEndpoint{
+		MerchantID: merchantID,
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: types,
+	}
+	if err := s.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// ListEndpoints retrieves the webhook endpoints of the merchant user's merchant
+func (s *WebhookService) ListEndpoints(ctx context.Context, userID int) ([]*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListEndpoints")
+	defer span.End()
+
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := s.webhookRepo.ListEndpointsByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// EnableEndpoint re-enables an endpoint of the merchant user's merchant. Pending deliveries
+// resume; events that occurred while it was disabled were not queued and can't be replayed.
+func (s *WebhookService) EnableEndpoint(ctx context.Context, userID, endpointID int) (*model.WebhookEndpoint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.EnableEndpoint")
+	defer span.End()
+
+	if _, err := s.getOwnEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.EnableEndpoint(ctx, endpointID); err != nil {
+		return nil, fmt.Errorf("failed to enable webhook endpoint: %w", err)
+	}
+
+	return s.getOwnEndpoint(ctx, userID, endpointID)
+}
+
+// DeleteEndpoint removes an endpoint of the merchant user's merchant with its delivery log
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, userID, endpointID int) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteEndpoint")
+	defer span.End()
+
+	if _, err := s.getOwnEndpoint(ctx, userID, endpointID); err != nil {
+		return err
+	}
+
+	deleted, err := s.webhookRepo.DeleteEndpoint(ctx, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if !deleted {
+		return errors.New("webhook endpoint not found")
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves the newest deliveries of an endpoint of the merchant user's merchant,
+// optionally only those with the given status
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, endpointID int, status string) ([]*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListDeliveries")
+	defer span.End()
+
+	if _, err := s.getOwnEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	var filter *model.WebhookDeliveryStatus
+	if status != "" {
+		deliveryStatus := model.WebhookDeliveryStatus(status)
+		filter = &deliveryStatus
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, endpointID, filter, webhookDeliveryLogLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery sends a delivery again with a fresh set of attempts. The payload is the same,
+// so receivers that already processed the event recognize it by its ID.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, userID, deliveryID int) (*model.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ReplayDelivery")
+	defer span.End()
+
+	delivery, err := s.webhookRepo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	// Deliveries of other merchants' endpoints are reported as not found
+	if _, err := s.getOwnEndpoint(ctx, userID, delivery.EndpointID); err != nil {
+		if err.Error() == "webhook endpoint not found" {
+			return nil, errors.New("webhook delivery not found")
+		}
+		return nil, err
+	}
+
+	// Resetting a delivery that is queued or being sent would undo its attempts or send it twice
+	if delivery.LockedUntil != nil && delivery.LockedUntil.After(time.Now()) {
+		return nil, errors.New("webhook delivery is being sent and can't be replayed")
+	}
+	if delivery.Status == model.WebhookDeliveryPending {
+		return nil, errors.New("webhook delivery is still pending and can't be replayed")
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// getOwnEndpoint retrieves an endpoint of the merchant user's merchant; other merchants'
+// endpoints are reported as not found
+func (s *WebhookService) getOwnEndpoint(ctx context.Context, userID, endpointID int) (*model.WebhookEndpoint, error) {
+	merchantID, err := s.userService.GetMerchantID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.webhookRepo.GetEndpointByID(ctx, endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	if endpoint == nil || endpoint.MerchantID != merchantID {
+		return nil, errors.New("webhook endpoint not found")
+	}
+
+	return endpoint, nil
+}
+
+// HandleEvent queues a delivery of a sold, refunded or redeemed voucher event to each active endpoint of
+// the voucher's merchant that subscribed to it. It runs as an outbox subscriber, which may see
+// an event more than once; endpoints get one delivery per event regardless.
+func (s *WebhookService) HandleEvent(ctx context.Context, msg outbox.Message) error {
+	eventType, ok := webhookEventTypes[msg.Type]
+	if !ok {
+		return nil
+	}
+
+	var event model.TransactionEventPayload
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		return fmt.Errorf("failed to decode transaction event: %w", err)
+	}
+	if event.MerchantID == nil || event.Transaction.VoucherID == nil {
+		return nil // Not a merchant's voucher
+	}
+
+	endpoints, err := s.webhookRepo.ListEndpointsByMerchant(ctx, *event.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	payload, err := json.Marshal(model.WebhookPayload{
+		ID:         msg.ID,
+		Type:       eventType,
+		MerchantID: *event.MerchantID,
+		OccurredAt: msg.OccurredAt,
+		Data: model.WebhookVoucherSale{
+			TransactionID:  event.Transaction.ID,
+			VoucherID:      *event.Transaction.VoucherID,
+			VoucherName:    event.VoucherName,
+			UserID:         event.Transaction.UserID,
+			Amount:         event.Transaction.Amount,
+			DiscountAmount: event.Transaction.DiscountAmount,
+			CreatedAt:      event.Transaction.CreatedAt,
+			RedeemedAt:     event.Transaction.RedeemedAt,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Status != model.WebhookEndpointActive {
+			continue
+		}
+		if len(endpoint.EventTypes) > 0 && !slices.Contains(endpoint.EventTypes, eventType) {
+			continue
+		}
+
+		delivery := &model.WebhookDelivery{
+			EndpointID: endpoint.ID,
+			EventID:    msg.ID,
+			EventType:  eventType,
+			Payload:    payload,
+		}
+		if _, err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// StartDispatcher sends due deliveries every interval until ctx is cancelled
+func (s *WebhookService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+					slog.Error("Webhook dispatcher failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// DispatchDue makes one attempt for each due delivery of an active endpoint. Deliveries are
+// claimed for a lease, so dispatchers of several servers never send the same one at once.
+func (s *WebhookService) DispatchDue(ctx context.Context) error {
+	now := time.Now()
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, now, now.Add(s.lease()), s.settings.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, webhookWorkers)
+	for _, delivery := range deliveries {
+		workers <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-workers }()
+			s.attempt(ctx, delivery)
+		})
+	}
+	wg.Wait()
+	return nil
+}
+
+// attempt sends a delivery once and records the result in the delivery log
+func (s *WebhookService) attempt(ctx context.Context, delivery *model.WebhookDelivery) {
+	endpoint, err := s.webhookRepo.GetEndpointByID(ctx, delivery.EndpointID)
+	if err != nil || endpoint == nil || endpoint.Status != model.WebhookEndpointActive {
+		// Deleted or disabled since it was claimed. Releasing the claim without counting an
+		// attempt leaves the delivery pending, so it resumes if the endpoint is enabled again.
+		if err != nil {
+			slog.Error("Failed to get webhook endpoint", "endpoint_id", delivery.EndpointID, "error", err)
+		}
+		if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			slog.Error("Failed to release webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := s.send(ctx, endpoint, delivery)
+	if ctx.Err() != nil {
+		return // Shutting down; the attempt doesn't count
+	}
+	metrics.ObserveWebhookDelivery(err == nil, time.Since(start))
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.LastError = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.settings.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts, s.settings.RetryBase, s.settings.RetryMax))
+	}
+	if err != nil {
+		lastError := err.Error()
+		delivery.LastError = &lastError
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		slog.Error("Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+
+	reason := fmt.Sprintf("%d consecutive failed attempts, the last: %v", s.settings.DisableAfter, err)
+	disabled, recordErr := s.webhookRepo.RecordEndpointResult(ctx, endpoint.ID, err == nil, s.settings.DisableAfter, reason)
+	if recordErr != nil {
+		slog.Error("Failed to record webhook endpoint result", "endpoint_id", endpoint.ID, "error", recordErr)
+	}
+	if disabled {
+		slog.Warn("Webhook endpoint disabled after repeated failures", "endpoint_id", endpoint.ID, "merchant_id", endpoint.MerchantID, "error", err)
+	}
+}
+
+// send POSTs the signed payload and returns the response status code, if there was a response
+func (s *WebhookService) send(ctx context.Context, endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, strconv.Itoa(delivery.EventID))
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorBody))
+		if snippet = bytes.TrimSpace(snippet); len(snippet) == 0 {
+			return &statusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+		}
+		return &statusCode, fmt.Errorf("endpoint answered %s: %s", resp.Status, snippet)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) // Lets the connection be reused
+	return &statusCode, nil
+}
+
+// SignWebhook returns the X-Webhook-Signature of a payload sent at timestamp (Unix seconds):
+// "v1=" and the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with the endpoint's secret.
+// Signing the timestamp lets receivers reject replayed requests.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// lease returns how long claimed deliveries are held: long enough for every batch worker's
+// requests to time out, with as much again to spare for recording the results
+func (s *WebhookService) lease() time.Duration {
+	rounds := (s.settings.BatchSize + webhookWorkers - 1) / webhookWorkers
+	return time.Duration(rounds*2) * s.settings.Timeout
+}
+
+// webhookBackoff returns the delay after the given number of failed attempts
+func webhookBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// validateWebhookURL checks that a webhook URL is absolute, uses https and doesn't name this
+// machine or a private network. Host names are checked again when dialed, against every
+// address they resolve to then.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return errors.New("invalid webhook URL: must be an absolute URL without credentials")
+	}
+	if u.Scheme != "https" {
+		return errors.New("invalid webhook URL: must use https")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("invalid webhook URL: must not point to a private, loopback or link-local address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return errors.New("invalid webhook URL: must not point to a private, loopback or link-local address")
+	}
+	return nil
+}
+
+// nonPublicPrefixes are the special-purpose ranges netip.Addr has no predicate for
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved, including broadcast
+}
+
+// publicAddr reports whether addr may receive webhooks: not loopback, private (RFC 1918 or
+// IPv6 unique local), link-local (which holds cloud metadata services), multicast or reserved
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	return !slices.ContainsFunc(nonPublicPrefixes, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
+
+// webhookTransport returns the transport of webhook requests. It connects directly rather than
+// through a proxy and refuses addresses that aren't public, whatever the URL's host resolved
+// to, so a merchant can't point deliveries at the internal network.
+func webhookTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
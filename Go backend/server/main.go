@@ -2,67 +2,213 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/NavaneethWKT/CapStone_GO_Lang/conf"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/metrics"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/protoc"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/ratelimit"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/config"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/events"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/handler"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/health"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/interceptor"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/model"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/outbox"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/memory"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/repository/postgres"
 	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/service"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 )
 
 const (
-	grpcPort = ":50051"
+	serviceName = "voucher-payment-service"
+
+	reservationDefaultHold   = 10 * time.Minute
+	reservationMaxHold       = 60 * time.Minute
+	reservationSweepInterval = 30 * time.Second
+
+	rateLimitCleanupInterval = time.Minute // Drop state of clients that went quiet
+
+	transactionEventBuffer = 64 // Events a watching client may fall behind by before its stream is ended
 )
 
 func main() {
-	log.Println("Starting Voucher Payment Service...")
+	// The certs subcommand needs no configuration
+	if len(os.Args) > 1 && os.Args[1] == "certs" {
+		runCerts(os.Args[2:])
+		return
+	}
+
+	// The migrate subcommand accepts the same configuration flags before its own arguments
+	args := os.Args[1:]
+	migrateCmd := len(args) > 0 && args[0] == "migrate"
+	if migrateCmd {
+		args = args[1:]
+	}
 
 	// Step 1: Load configuration
-	cfg, err := config.LoadConfig()
+	cfg, opts, err := config.LoadConfig(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		logging.Fatal("Failed to load config", "error", err)
+	}
+	if opts.PrintConfig {
+		if err := conf.Print(os.Stdout, cfg); err != nil {
+			logging.Fatal("Failed to print config", "error", err)
+		}
+		return
+	}
+
+	logCfg, _ := cfg.Log.Config() // Already checked by LoadConfig
+	logging.Setup(logCfg)
+
+	if migrateCmd {
+		runMigrate(cfg, opts.Args)
+		return
 	}
-	log.Println("Configuration loaded successfully")
 
-	// Step 2: Connect to database
-	db, err := config.ConnectDB(cfg)
+	slog.Info("Starting Voucher Payment Service...")
+	slog.Info("Configuration loaded successfully", "file", opts.File)
+
+	traceExporter, _ := cfg.Tracing.ParseExporter()
+	shutdownTracing, err := tracing.Setup(context.Background(), serviceName, traceExporter)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
-	log.Println("Database connected successfully")
-
-	// Step 3: Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	voucherRepo := repository.NewVoucherRepository(db)
-	walletRepo := repository.NewWalletRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
-	log.Println("Repositories initialized")
-
-	// Step 4: Initialize services
-	mockUPI := service.NewMockUPI(0.95) // 95% success rate
-	userService := service.NewUserService(userRepo)
-	voucherService := service.NewVoucherService(voucherRepo)
-	walletService := service.NewWalletService(walletRepo)
-	transactionService := service.NewTransactionService(transactionRepo, userService)
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+	slog.Info("Tracing configured", "exporter", traceExporter)
+
+	// Step 2: Connect to storage
+	// Step 3: Initialize repositories (in memory, or backed by the database)
+	var repos *repository.Repositories
+	var dbMonitor *health.DBMonitor // Nil when there is no database
+	if cfg.Storage == config.StorageMemory {
+		repos = memory.New()
+		slog.Warn("Using in-memory storage, data is lost on restart", "demo_admin", memory.DemoAdminEmail, "demo_customer", memory.DemoCustomerEmail)
+	} else {
+		db, err := config.ConnectDB(context.Background(), &cfg.DB)
+		if err != nil {
+			logging.Fatal("Failed to connect to database", "error", err)
+		}
+		defer db.Close()
+		slog.Info("Database connected successfully")
+
+		if err := checkSchema(context.Background(), db, cfg.AutoMigrate); err != nil {
+			logging.Fatal("Database schema check failed", "error", err)
+		}
+		slog.Info("Database schema is up to date")
+
+		if err := metrics.RegisterDB(db, cfg.DB.DBName); err != nil {
+			logging.Fatal("Failed to register database metrics", "error", err)
+		}
+
+		// Keep checking the database so readiness reflects its current health
+		monitorCtx, stopMonitor := context.WithCancel(context.Background())
+		defer stopMonitor()
+		dbMonitor = health.NewDBMonitor(db, cfg.DB.ConnectTimeout)
+		dbMonitor.Start(monitorCtx, cfg.DB.HealthCheckInterval)
+
+		repos = postgres.New(db)
+	}
+	slog.Info("Repositories initialized", "storage", cfg.Storage)
+
+	// Step 4: Initialize services; committed purchases are published on the event bus for watching clients
+	eventBus := events.NewBus(transactionEventBuffer)
+	mockUPI := service.NewMockUPI(cfg.Payments.MockUPISuccessRate)
+	userService := service.NewUserService(repos.Users)
+	voucherService := service.NewVoucherService(repos.Vouchers, repos.Transactions, userService)
+	walletService := service.NewWalletService(repos.Wallets)
+	transactionService := service.NewTransactionService(repos.Transactions, userService, eventBus)
+	promotionService := service.NewPromotionService(repos.Promotions, userService)
+	priceScheduleService := service.NewPriceScheduleService(repos.PriceSchedules, userService, voucherService)
+	reservationService := service.NewReservationService(
+		repos.Tx,
+		repos.Reservations,
+		repos.Vouchers,
+		userService,
+		reservationDefaultHold,
+		reservationMaxHold,
+	)
 	paymentService := service.NewPaymentService(
-		db,
+		repos.Tx,
 		userService,
 		voucherService,
 		walletService,
-		voucherRepo,
-		transactionRepo,
+		promotionService,
+		priceScheduleService,
+		reservationService,
+		repos.Vouchers,
+		repos.Transactions,
+		repos.Outbox,
 		mockUPI,
+		eventBus,
 	)
-	log.Println("Services initialized")
+	merchantService := service.NewMerchantService(repos.Merchants, repos.Vouchers, userService, voucherService)
+	settlementService := service.NewSettlementService(repos.Tx, repos.Settlements, merchantService, userService, cfg.Settlement.CommissionRate)
+	webhookService := service.NewWebhookService(repos.Webhooks, userService, cfg.Webhooks.Settings())
+
+	// Rate limits and login lockouts keep their state in memory
+	rateLimitStore := ratelimit.NewMemoryStore()
+	emailPolicy, ipPolicy := cfg.Login.Policies()
+	loginGuard := service.NewLoginGuard(rateLimitStore, emailPolicy, ipPolicy)
+	slog.Info("Services initialized")
+
+	// Release expired stock holds in the background
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	reservationService.StartSweeper(sweeperCtx, reservationSweepInterval)
+	rateLimitStore.StartJanitor(sweeperCtx, rateLimitCleanupInterval)
+
+	// Deliver the domain events recorded in the outbox to the configured sinks
+	subscribers := outbox.NewSubscribers()
+	subscribers.Subscribe(outbox.AllEvents, "log", func(_ context.Context, msg outbox.Message) error {
+		slog.Debug("Domain event", "event_id", msg.ID, "event_type", msg.Type, "aggregate", msg.AggregateType+"/"+msg.AggregateID)
+		return nil
+	})
+	// Queue merchant webhook deliveries for sold, refunded and redeemed vouchers
+	subscribers.Subscribe(model.EventVoucherPurchased, "merchant_webhooks", webhookService.HandleEvent)
+	subscribers.Subscribe(model.EventVoucherRefunded, "merchant_webhooks", webhookService.HandleEvent)
+	subscribers.Subscribe(model.EventVoucherRedeemed, "merchant_webhooks", webhookService.HandleEvent)
+	sinks := []outbox.Sink{subscribers}
+	if cfg.Outbox.WebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(cfg.Outbox.WebhookURL))
+	}
+	if cfg.Outbox.File != "" {
+		fileSink, err := outbox.NewFileSink(cfg.Outbox.File)
+		if err != nil {
+			logging.Fatal("Failed to set up outbox file sink", "error", err)
+		}
+		defer fileSink.Close()
+		sinks = append(sinks, fileSink)
+	}
+	relay := outbox.NewRelay(repos.Outbox, cfg.Outbox.Settings(), sinks...)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	relay.Start(relayCtx)
+	slog.Info("Outbox relay started", "webhook", cfg.Outbox.WebhookURL != "", "file", cfg.Outbox.File)
+
+	// Send queued merchant webhook deliveries, retrying failed ones
+	webhookCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	webhookService.StartDispatcher(webhookCtx, cfg.Webhooks.PollInterval)
 
 	// Step 5: Initialize handlers
 	voucherServiceHandler := handler.NewVoucherServiceHandler(
@@ -71,33 +217,100 @@ func main() {
 		paymentService,
 		walletService,
 		transactionService,
+		promotionService,
+		priceScheduleService,
+		reservationService,
+		merchantService,
+		settlementService,
+		webhookService,
+		loginGuard,
 	)
-	log.Println("Handlers initialized")
+	slog.Info("Handlers initialized")
 
 	// Step 6: Setup gRPC server with interceptors
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(unaryInterceptor), // Logging interceptor
-	)
+	var limiter *ratelimit.Limiter // Nil allows everything
+	if cfg.RateLimit.Enabled {
+		defaultLimit, methodLimits, _ := cfg.RateLimit.Limits() // Already checked by LoadConfig
+		limiter = ratelimit.NewLimiter(rateLimitStore, defaultLimit, methodLimits)
+	}
+	forwarders, _ := cfg.RateLimit.TrustedForwarders() // Already checked by LoadConfig
+	rateLimitInterceptor := ratelimit.UnaryServerInterceptor(limiter, forwarders)
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()), // Continue traces started by the gateway
+		// Accept the gateway's keepalive pings, including on idle connections
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPC.Keepalive.MinTime,
+			PermitWithoutStream: true,
+		}),
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(logCfg), // Logging interceptor
+			metrics.UnaryServerInterceptor(),       // Request count and latency metrics
+			interceptor.Recovery(),                 // Panics become codes.Internal instead of crashing the server
+			rateLimitInterceptor,                   // Per client and method budgets
+			interceptor.Validation(),               // Field rules declared in voucher.proto
+		),
+		// The same, for streams such as WatchTransactions
+		grpc.ChainStreamInterceptor(
+			logging.StreamServerInterceptor(logCfg),
+			metrics.StreamServerInterceptor(),
+			interceptor.StreamRecovery(),
+			ratelimit.StreamServerInterceptor(limiter, forwarders),
+			interceptor.StreamValidation(),
+		),
+	}
+
+	// TLS, and mutual TLS when a client CA is configured; certificates are reloaded when their files change
+	tlsConfig, err := cfg.GRPC.TLS.ServerConfig("h2")
+	if err != nil {
+		logging.Fatal("Failed to set up TLS", "error", err)
+	}
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	// Step 7: Register gRPC service
 	protoc.RegisterVoucherServiceServer(grpcServer, voucherServiceHandler)
 
+	// Report per-dependency health on the standard grpc.health.v1 service
+	healthReporter := health.NewReporter(cfg.Health.CheckTimeout, protoc.VoucherService_ServiceDesc.ServiceName)
+	if dbMonitor != nil {
+		healthReporter.Add(health.DependencyDB, func(context.Context) error { return dbMonitor.Err() })
+	}
+	healthReporter.Add(health.DependencyPaymentGateway, mockUPI.Check)
+	healthpb.RegisterHealthServer(grpcServer, healthReporter.Server())
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	healthReporter.Start(healthCtx, cfg.Health.CheckInterval)
+
 	// Enable gRPC reflection for testing
 	reflection.Register(grpcServer)
-	log.Println("gRPC server configured")
+	slog.Info("gRPC server configured")
 
 	// Step 8: Start gRPC server
-	lis, err := net.Listen("tcp", grpcPort)
+	lis, err := net.Listen("tcp", cfg.GRPC.Addr)
 	if err != nil {
-		log.Fatalf("Failed to listen on %s: %v", grpcPort, err)
+		logging.Fatal("Failed to listen", "addr", cfg.GRPC.Addr, "error", err)
 	}
 
-	log.Printf("gRPC server listening on %s", grpcPort)
+	slog.Info("gRPC server listening", "addr", cfg.GRPC.Addr, "tls", cfg.GRPC.TLS.Enabled, "mtls", cfg.GRPC.TLS.ClientCAFile != "")
+
+	// Expose Prometheus metrics and the readiness probe over HTTP
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", metrics.Handler())
+	metricsMux.Handle("/readyz", health.ReadyHandler(healthReporter.Ready))
+	metricsServer := &http.Server{Addr: cfg.Metrics.Addr, Handler: metricsMux}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Failed to serve metrics", "error", err)
+		}
+	}()
+	slog.Info("Metrics server listening", "addr", cfg.Metrics.Addr)
 
 	// Step 9: Graceful shutdown
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve: %v", err)
+			logging.Fatal("Failed to serve", "error", err)
 		}
 	}()
 
@@ -106,12 +319,23 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server...")
+	healthReporter.Shutdown()
+	eventBus.Close() // End watch streams, which would otherwise hold up the graceful stop
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		slog.Warn("Metrics server shutdown failed", "error", err)
+	}
+	defer func() {
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Tracing shutdown failed", "error", err)
+		}
+	}()
+
 	stopped := make(chan struct{})
 	go func() {
 		grpcServer.GracefulStop()
@@ -120,31 +344,13 @@ func main() {
 
 	select {
 	case <-stopped:
-		log.Println("Server stopped gracefully")
+		slog.Info("Server stopped gracefully")
 	case <-ctx.Done():
-		log.Println("Shutdown timeout, forcing stop")
+		slog.Warn("Shutdown timeout, forcing stop")
 		grpcServer.Stop()
 	}
-}
-
-// unaryInterceptor is a logging interceptor for gRPC
-func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	start := time.Now()
 
-	// Log incoming request
-	log.Printf("gRPC method: %s, request: %+v", info.FullMethod, req)
-
-	// Call the handler
-	resp, err := handler(ctx, req)
-
-	// Log response and duration
-	duration := time.Since(start)
-	if err != nil {
-		log.Printf("gRPC method: %s, error: %v, duration: %v", info.FullMethod, err, duration)
-	} else {
-		log.Printf("gRPC method: %s, success, duration: %v", info.FullMethod, duration)
-	}
-
-	return resp, err
+	// Stop the relay once no more events can be recorded; an interrupted delivery is retried after a restart
+	stopRelay()
+	relay.Wait()
 }
-
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/NavaneethWKT/CapStone_GO_Lang/logging"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/migrations"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/config"
+	"github.com/NavaneethWKT/CapStone_GO_Lang/server/internal/migrate"
+)
+
+const migrateUsage = "usage: server migrate [config flags] up | down [steps] | status"
+
+// runMigrate handles the "migrate" subcommand
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if cfg.Storage != config.StoragePostgres {
+		logging.Fatal("Migrations only apply to postgres storage", "storage", cfg.Storage)
+	}
+
+	ctx := context.Background()
+	db, err := config.ConnectDB(ctx, &cfg.DB)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	runner, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		logging.Fatal("Failed to load migrations", "error", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		for _, m := range applied {
+			slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		slog.Info("Database schema is up to date", "version", runner.Latest())
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				logging.Fatal("Invalid number of steps", "steps", args[1])
+			}
+		}
+		reverted, err := runner.Down(ctx, steps)
+		for _, m := range reverted {
+			slog.Info("Reverted migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+
+	case "status":
+		if err := printMigrationStatus(ctx, runner); err != nil {
+			logging.Fatal("Failed to read migration status", "error", err)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+// printMigrationStatus prints the schema version and a table of migrations
+func printMigrationStatus(ctx context.Context, runner *migrate.Runner) error {
+	version, dirty, err := runner.Version(ctx)
+	if err != nil {
+		return err
+	}
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Schema version: %d (expected %d)", version, runner.Latest())
+	if dirty {
+		fmt.Print(" DIRTY")
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+	return w.Flush()
+}
+
+// checkSchema makes sure the database schema matches this build before serving,
+// applying pending migrations first when autoMigrate is set
+func checkSchema(ctx context.Context, db *sql.DB, autoMigrate bool) error {
+	runner, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	err = runner.Check(ctx)
+	if !migrate.IsOutdated(err) {
+		return err
+	}
+	if !autoMigrate {
+		return fmt.Errorf(`%w; run "server migrate up" or start with --auto-migrate`, err)
+	}
+
+	applied, err := runner.Up(ctx)
+	for _, m := range applied {
+		slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	return runner.Check(ctx)
+}
@@ -0,0 +1,112 @@
+// Package tracing sets up OpenTelemetry tracing shared by the gRPC server and the REST gateway.
+// Trace context travels from the gateway to the server in W3C traceparent headers / gRPC metadata.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies spans created by this module
+const instrumentationName = "github.com/NavaneethWKT/CapStone_GO_Lang"
+
+// Exporters supported by Setup
+const (
+	ExporterNone   = "none"   // Tracing disabled (no-op spans)
+	ExporterStdout = "stdout" // Spans printed as JSON to stdout, no collector needed
+	ExporterOTLP   = "otlp"   // Spans sent over OTLP/gRPC, configured by the OTEL_EXPORTER_OTLP_* variables
+)
+
+// Settings are the tracing options as given in config files, environment variables and flags.
+// They live in the "tracing" section, so TRACING_EXPORTER sets the exporter.
+type Settings struct {
+	Exporter string `conf:"exporter" default:"none" usage:"span exporter: none, stdout or otlp"`
+}
+
+// ParseExporter checks the exporter name (none, stdout or otlp)
+func (s Settings) ParseExporter() (string, error) {
+	exporter := strings.ToLower(s.Exporter)
+	switch exporter {
+	case "":
+		return ExporterNone, nil
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+		return exporter, nil
+	default:
+		return "", fmt.Errorf("invalid tracing exporter %q: must be none, stdout or otlp", exporter)
+	}
+}
+
+// Setup installs the global tracer provider and W3C propagators for serviceName.
+// The returned function flushes and stops the exporter; call it on shutdown.
+func Setup(ctx context.Context, serviceName, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		spanExporter, err = otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporter, err)
+	}
+
+	resource, err := sdkresource.Merge(
+		sdkresource.Default(),
+		sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts an internal span (e.g. a service call) as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartQuery starts a client span for a database query made by a repository method
+func StartQuery(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNamePostgreSQL),
+	)
+}
+
+// RecordError marks a span as failed
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}